@@ -7,8 +7,6 @@ import (
 	"image/jpeg"
 	"io"
 	"log"
-	"os/exec"
-	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,14 +22,20 @@ type CaptureWorker struct {
 
 	// Frame output
 	frameBuffer *FrameBuffer // Buffer mode for decoupled capture/render
+	slot        int          // Manager slot, passed on to sinks
+	sinksMu     sync.RWMutex
+	sinks       []FrameSink // Recorders and other consumers of real frames
+	cropMu      sync.Mutex
+	crop        Crop // Applied after the transform; see SetCrop
 
-	// FFmpeg capture
-	ffmpegCmd *exec.Cmd
-	ffmpegMu  sync.Mutex
+	// Frame input: the real camera, plus a synthetic fallback used while
+	// the real camera is unavailable
+	source   FrameSource
+	fallback FrameSource
 
 	// Capture settings - use camera's max capabilities, never restart
 	targetFPS  atomic.Int32 // Effective FPS (controls frame skipping)
-	captureFPS int          // FFmpeg capture rate (from camera capabilities)
+	captureFPS int          // Source capture rate (from camera capabilities)
 	captureW   int          // Capture width (from camera capabilities)
 	captureH   int          // Capture height (from camera capabilities)
 
@@ -46,10 +50,21 @@ type CaptureWorker struct {
 }
 
 // NewCaptureWorkerWithBuffer creates a capture worker using FrameBuffer
+// and the default frame source for s.Backend. Per-camera overrides in
+// s.Cameras are applied.
 func NewCaptureWorkerWithBuffer(camera Camera, buffer *FrameBuffer, s Settings) *CaptureWorker {
-	capW := camera.Capabilities.MaxWidth
-	capH := camera.Capabilities.MaxHeight
-	capFPS := camera.Capabilities.MaxFPS
+	s = s.ForCamera(camera)
+	return NewCaptureWorkerWithSource(camera, NewFrameSource(camera, s), buffer, s)
+}
+
+// NewCaptureWorkerWithSource creates a capture worker reading from an arbitrary FrameSource.
+// Per-camera overrides in s.Cameras are applied.
+func NewCaptureWorkerWithSource(camera Camera, source FrameSource, buffer *FrameBuffer, s Settings) *CaptureWorker {
+	s = s.ForCamera(camera)
+	caps := source.Capabilities()
+	capW := caps.MaxWidth
+	capH := caps.MaxHeight
+	capFPS := caps.MaxFPS
 
 	// Ensure we have valid defaults from settings
 	if capW == 0 {
@@ -66,18 +81,20 @@ func NewCaptureWorkerWithBuffer(camera Camera, buffer *FrameBuffer, s Settings)
 		camera:      camera,
 		settings:    s,
 		frameBuffer: buffer,
+		source:      source,
+		fallback:    NewTestPatternSource(camera, s),
 		stopCh:      make(chan struct{}),
 		captureW:    capW,
 		captureH:    capH,
 		captureFPS:  capFPS,
+		crop:        s.Crop,
 	}
 	cw.targetFPS.Store(int32(capFPS))
-	log.Printf("[Capture] %s: Vehicle mode - %dx%d @ %d FPS (buffer, fixed)", camera.DeviceID, capW, capH, capFPS)
 	return cw
 }
 
 // SetFPS updates the target FPS for this capture worker
-// This uses frame skipping - FFmpeg stays at max FPS, we just decode fewer frames
+// This uses frame skipping - the source stays at max FPS, we just decode fewer frames
 // NO RESTART EVER - resolution stays constant
 func (cw *CaptureWorker) SetFPS(fps int) {
 	if fps < 5 {
@@ -89,10 +106,31 @@ func (cw *CaptureWorker) SetFPS(fps int) {
 	}
 	oldFPS := cw.targetFPS.Swap(int32(fps))
 	if oldFPS != int32(fps) {
-		log.Printf("[Capture] %s: Target FPS %d -> %d (frame skipping, no restart)", cw.camera.DeviceID, oldFPS, fps)
+		log.Printf("[Capture] %s: Target FPS %d -> %d (frame skipping, no restart)", cw.camera.Label(cw.slot), oldFPS, fps)
 	}
 }
 
+// AddSink registers a sink for the frames this worker publishes.
+func (cw *CaptureWorker) AddSink(sink FrameSink) {
+	cw.sinksMu.Lock()
+	cw.sinks = append(cw.sinks, sink)
+	cw.sinksMu.Unlock()
+}
+
+// SetCrop changes the part of the frame that is kept, from the next frame on.
+func (cw *CaptureWorker) SetCrop(c Crop) {
+	cw.cropMu.Lock()
+	cw.crop = c
+	cw.cropMu.Unlock()
+}
+
+// Crop returns the part of the frame that is kept.
+func (cw *CaptureWorker) Crop() Crop {
+	cw.cropMu.Lock()
+	defer cw.cropMu.Unlock()
+	return cw.crop
+}
+
 // GetFPS returns current FPS setting
 func (cw *CaptureWorker) GetFPS() int {
 	return int(cw.targetFPS.Load())
@@ -114,6 +152,8 @@ func (cw *CaptureWorker) Start() error {
 		return fmt.Errorf("capture worker already running")
 	}
 
+	log.Printf("[Capture] %s: Vehicle mode - %dx%d @ %d FPS (buffer, fixed)",
+		cw.camera.Label(cw.slot), cw.captureW, cw.captureH, cw.captureFPS)
 	cw.running.Store(true)
 	cw.wg.Add(1)
 	go func() {
@@ -139,13 +179,8 @@ func (cw *CaptureWorker) Stop() {
 		close(cw.stopCh)
 	}
 
-	// Kill FFmpeg immediately to unblock any reads
-	cw.ffmpegMu.Lock()
-	if cw.ffmpegCmd != nil && cw.ffmpegCmd.Process != nil {
-		cw.ffmpegCmd.Process.Kill()
-		cw.ffmpegCmd.Wait() // Reap zombie process
-	}
-	cw.ffmpegMu.Unlock()
+	// Close the source immediately to unblock any reads (kills FFmpeg)
+	cw.source.Close()
 
 	// Wait for capture goroutine to fully exit (with timeout)
 	done := make(chan struct{})
@@ -157,14 +192,14 @@ func (cw *CaptureWorker) Stop() {
 	case <-done:
 		// Goroutine exited cleanly
 	case <-time.After(2 * time.Second):
-		log.Printf("[Capture] %s: Warning - goroutine did not exit within 2s", cw.camera.DeviceID)
+		log.Printf("[Capture] %s: Warning - goroutine did not exit within 2s", cw.camera.Label(cw.slot))
 	}
 }
 
 // Restart stops the worker and starts it again with a fresh stopCh
 // Used for hot-plug recovery without recreating the entire manager
 func (cw *CaptureWorker) Restart() error {
-	log.Printf("[Capture] %s: Restarting worker...", cw.camera.DeviceID)
+	log.Printf("[Capture] %s: Restarting worker...", cw.camera.Label(cw.slot))
 
 	// Stop waits for goroutine to fully exit
 	cw.Stop()
@@ -197,17 +232,16 @@ func (cw *CaptureWorker) GetStats() (frameCount uint64, fps float64, errors uint
 	return
 }
 
-// captureLoop runs the main capture loop using FFmpeg
-// Implements automatic recovery: if camera disconnects or FFmpeg fails,
+// GetSkippedFrames returns the number of frames skipped to honor the target FPS
+func (cw *CaptureWorker) GetSkippedFrames() uint64 {
+	return cw.skippedFrames.Load()
+}
+
+// captureLoop runs the main capture loop against the frame source.
+// Implements automatic recovery: if the camera disconnects or the source fails,
 // falls back to test patterns which periodically try to reconnect
 func (cw *CaptureWorker) captureLoop() {
-	defer func() {
-		cw.ffmpegMu.Lock()
-		if cw.ffmpegCmd != nil && cw.ffmpegCmd.Process != nil {
-			cw.ffmpegCmd.Process.Kill()
-		}
-		cw.ffmpegMu.Unlock()
-	}()
+	defer cw.source.Close()
 
 	// Main capture loop with recovery
 	for cw.running.Load() {
@@ -217,8 +251,8 @@ func (cw *CaptureWorker) captureLoop() {
 		if !realCameraWorking && cw.running.Load() {
 			// Camera failed or disconnected - fall back to test pattern
 			// runTestPatternLoop will periodically try to reconnect
-			log.Printf("[Capture] Camera %s: Real camera failed, entering recovery mode",
-				cw.camera.DeviceID)
+			log.Printf("[Capture] %s: Real camera failed, entering recovery mode",
+				cw.camera.Label(cw.slot))
 			cw.runTestPatternLoop()
 			// If runTestPatternLoop returns, it means:
 			// 1. Stop was called (running=false), or
@@ -234,116 +268,29 @@ func (cw *CaptureWorker) captureLoop() {
 	}
 }
 
-// tryRealCameraCapture attempts to capture from real camera using FFmpeg
+// tryRealCameraCapture opens the frame source and streams from it until the
+// stream ends or the worker is stopped.
+// Returns false if the source could not be opened or its stream ended.
 func (cw *CaptureWorker) tryRealCameraCapture() bool {
-	videoSize := fmt.Sprintf("%dx%d", cw.captureW, cw.captureH)
-	fps := cw.captureFPS
-	format := cw.settings.Format
-
-	log.Printf("[Capture] Camera %s: Vehicle mode - %s @ %d FPS (%s, fixed)",
-		cw.camera.DeviceID, videoSize, fps, format)
-
-	// Build format list based on configured format
-	// The configured format is tried first, then fallbacks
-	var formats [][]string
-
-	// Common FFmpeg args for all formats
-	commonArgs := []string{"-thread_queue_size", "512", "-probesize", "32", "-analyzeduration", "0"}
-	outputArgs := []string{"-f", "image2pipe", "-vcodec", "mjpeg", "-q:v", "5", "-"}
-
-	// buildArgs safely constructs FFmpeg args without mutating commonArgs/outputArgs.
-	// Using append(append(commonArgs, ...), outputArgs...) would corrupt commonArgs
-	// on subsequent calls if the first append didn't grow the backing array.
-	buildArgs := func(inputArgs ...string) []string {
-		args := make([]string, 0, len(commonArgs)+len(inputArgs)+len(outputArgs))
-		args = append(args, commonArgs...)
-		args = append(args, inputArgs...)
-		args = append(args, outputArgs...)
-		return args
-	}
-
-	fpsStr := fmt.Sprintf("%d", fps)
-
-	// Primary format from config
-	if format == "mjpeg" {
-		formats = append(formats, buildArgs(
-			"-f", "v4l2", "-input_format", "mjpeg", "-video_size", videoSize,
-			"-framerate", fpsStr, "-i", cw.camera.DevicePath))
-		// YUYV fallback
-		formats = append(formats, buildArgs(
-			"-f", "v4l2", "-input_format", "yuyv422", "-video_size", videoSize,
-			"-framerate", fpsStr, "-i", cw.camera.DevicePath))
-	} else if format == "yuyv" {
-		// YUYV first if configured
-		formats = append(formats, buildArgs(
-			"-f", "v4l2", "-input_format", "yuyv422", "-video_size", videoSize,
-			"-framerate", fpsStr, "-i", cw.camera.DevicePath))
-		// MJPEG fallback
-		formats = append(formats, buildArgs(
-			"-f", "v4l2", "-input_format", "mjpeg", "-video_size", videoSize,
-			"-framerate", fpsStr, "-i", cw.camera.DevicePath))
-	}
-
-	// Auto format detection as last resort
-	formats = append(formats, buildArgs(
-		"-f", "v4l2", "-video_size", videoSize,
-		"-framerate", fpsStr, "-i", cw.camera.DevicePath))
-
-	for _, args := range formats {
-		if !cw.running.Load() {
-			return false // Shutting down, don't try more formats
-		}
-		if cw.tryFFmpegCapture(args) {
-			return true
-		}
-	}
-
-	return false
-}
-
-// tryFFmpegCapture tries to capture with specific FFmpeg arguments
-// NEVER restarts - FFmpeg runs at camera's max settings, frame skipping handles FPS
-func (cw *CaptureWorker) tryFFmpegCapture(args []string) bool {
-	log.Printf("[Capture] Camera %s: Trying FFmpeg with args: %v", cw.camera.DeviceID, args)
-
-	cw.ffmpegMu.Lock()
-	cw.ffmpegCmd = exec.Command("ffmpeg", args...)
-	cw.ffmpegCmd.Stderr = nil // Suppress FFmpeg stderr output
-
-	stdout, err := cw.ffmpegCmd.StdoutPipe()
-	if err != nil {
-		cw.ffmpegMu.Unlock()
-		log.Printf("[Capture] Camera %s: Failed to create stdout pipe: %v", cw.camera.DeviceID, err)
+	if !cw.running.Load() {
 		return false
 	}
-
-	if err := cw.ffmpegCmd.Start(); err != nil {
-		cw.ffmpegMu.Unlock()
-		log.Printf("[Capture] Camera %s: Failed to start FFmpeg: %v", cw.camera.DeviceID, err)
+	if err := cw.source.Open(); err != nil {
+		log.Printf("[Capture] %s: Failed to open frame source: %v", cw.camera.Label(cw.slot), err)
 		return false
 	}
-	cw.ffmpegMu.Unlock()
-
-	// CRITICAL: Always reap the process to prevent zombies
-	defer func() {
-		cw.ffmpegMu.Lock()
-		if cw.ffmpegCmd != nil && cw.ffmpegCmd.Process != nil {
-			cw.ffmpegCmd.Process.Kill()
-			cw.ffmpegCmd.Wait() // Reap zombie process
-		}
-		cw.ffmpegMu.Unlock()
-	}()
-
-	log.Printf("[Capture] Camera %s: FFmpeg started - %dx%d @ %d FPS (PID: %d)",
-		cw.camera.DeviceID, cw.captureW, cw.captureH, cw.captureFPS, cw.ffmpegCmd.Process.Pid)
+	// CRITICAL: Always close the source to release the device (and reap FFmpeg)
+	defer cw.source.Close()
 
-	// Pre-allocate read buffer for efficiency
-	readBuffer := make([]byte, 8192)    // Larger buffer for fewer syscalls
-	frameData := make([]byte, 0, 65536) // Pre-allocate typical JPEG size
+	return cw.streamFrames()
+}
 
+// streamFrames reads frames from the open source and publishes them.
+// NEVER restarts - the source runs at the camera's max settings, frame skipping handles FPS
+func (cw *CaptureWorker) streamFrames() bool {
 	lastProcessedTime := time.Now()
 
-	// Read frames from FFmpeg output - FFmpeg controls the rate
+	// Read frames from the source - the source controls the rate
 	// NO RESTART LOGIC - frame skipping handles FPS adaptation
 	for cw.running.Load() {
 		select {
@@ -357,17 +304,15 @@ func (cw *CaptureWorker) tryFFmpegCapture(args []string) bool {
 			}
 			minFrameInterval := time.Second / time.Duration(targetFPS)
 
-			// Read raw JPEG bytes (must read to stay in sync with stream)
-			jpegData, err := cw.readMJPEGFrameRaw(stdout, readBuffer, &frameData)
+			// Read the raw frame (must read to stay in sync with stream)
+			raw, err := cw.source.NextFrame()
 			if err != nil {
 				if err == io.EOF {
-					log.Printf("[Capture] Camera %s: FFmpeg stream ended", cw.camera.DeviceID)
+					log.Printf("[Capture] %s: Frame source stream ended", cw.camera.Label(cw.slot))
 					return false
 				}
 				// Timeout or other error - skip this frame, don't freeze
 				cw.errorCount.Add(1)
-				// Clear frameData to resync on next frame
-				frameData = frameData[:0]
 				continue
 			}
 
@@ -382,12 +327,21 @@ func (cw *CaptureWorker) tryFFmpegCapture(args []string) bool {
 			}
 			lastProcessedTime = now
 
-			// Decode JPEG to image
-			frame := cw.decodeJPEG(jpegData)
+			// Decode to image (no-op for sources that deliver images)
+			frame := cw.decodeFrame(raw)
 			if frame == nil {
 				cw.errorCount.Add(1)
 				continue
 			}
+			transformed := false
+			if t := cw.settings.Transform(); !raw.Transformed && !t.IsIdentity() {
+				frame = transformImage(frame, t)
+				transformed = true
+			}
+			if c := cw.Crop(); !c.IsFull() {
+				frame = c.Apply(frame) // Shares pixels, no copy
+				transformed = true
+			}
 
 			// Update stats
 			cw.frameCount.Add(1)
@@ -397,123 +351,68 @@ func (cw *CaptureWorker) tryFFmpegCapture(args []string) bool {
 			if count%150 == 1 { // Log every 150 frames (~10 sec at 15fps)
 				bounds := frame.Bounds()
 				skipped := cw.skippedFrames.Load()
-				log.Printf("[Capture] Camera %s: Frame #%d (%dx%d) @ %d FPS (skipped: %d)",
-					cw.camera.DeviceID, count, bounds.Dx(), bounds.Dy(), targetFPS, skipped)
+				log.Printf("[Capture] %s: Frame #%d (%dx%d) @ %d FPS (skipped: %d)",
+					cw.camera.Label(cw.slot), count, bounds.Dx(), bounds.Dy(), targetFPS, skipped)
 			}
 
 			// Send frame - prefer FrameBuffer if available
 			cw.sendFrame(frame)
+			cw.publishFrame(raw, frame, now, transformed)
 		}
 	}
 
 	return true
 }
 
-// readMJPEGFrameRaw reads raw JPEG bytes from stream without decoding
-// Returns the raw JPEG data and any error. Caller decides whether to decode.
-// Has built-in timeout to prevent blocking during camera issues (vibration, USB hiccups)
-func (cw *CaptureWorker) readMJPEGFrameRaw(reader io.Reader, buffer []byte, frameData *[]byte) ([]byte, error) {
-	// Reset frame data slice (keep capacity)
-	*frameData = (*frameData)[:0]
-
-	// Timeout for reading a complete frame (prevents freeze during vibration)
-	// Scale with FPS: at 30fps a frame is ~33ms, at 5fps ~200ms; add generous margin
-	fps := int(cw.targetFPS.Load())
-	if fps <= 0 {
-		fps = cw.settings.FPS
-	}
-	frameTimeout := time.Duration(float64(time.Second)/float64(fps)*3) + 50*time.Millisecond
-	if frameTimeout < 150*time.Millisecond {
-		frameTimeout = 150 * time.Millisecond
+// publishFrame passes a real camera frame on to the registered sinks
+func (cw *CaptureWorker) publishFrame(raw Frame, frame image.Image, ts time.Time, transformed bool) {
+	cw.sinksMu.RLock()
+	sinks := cw.sinks
+	cw.sinksMu.RUnlock()
+	if len(sinks) == 0 {
+		return
 	}
-	frameStart := time.Now()
-
-	// Find SOI marker (0xFFD8)
-	foundSOI := false
-	for !foundSOI {
-		// Check timeout
-		if time.Since(frameStart) > frameTimeout {
-			*frameData = (*frameData)[:0]
-			return nil, fmt.Errorf("timeout finding SOI marker")
-		}
-
-		n, err := reader.Read(buffer)
-		if err != nil {
-			return nil, err
-		}
-
-		*frameData = append(*frameData, buffer[:n]...)
 
-		// Look for SOI marker
-		for i := 0; i < len(*frameData)-1; i++ {
-			if (*frameData)[i] == 0xFF && (*frameData)[i+1] == 0xD8 {
-				*frameData = (*frameData)[i:]
-				foundSOI = true
-				break
-			}
-		}
-
-		// Prevent runaway buffer growth
-		if len(*frameData) > 100000 {
-			*frameData = (*frameData)[len(*frameData)-10000:]
-		}
+	captured := CapturedFrame{Camera: cw.camera, Slot: cw.slot, Time: ts, Image: frame}
+	if raw.Format == PixelFormatMJPEG && raw.Image == nil && !transformed {
+		captured.JPEG = withHuffmanTables(raw.Data)
 	}
+	for _, sink := range sinks {
+		sink.WriteFrame(captured)
+	}
+}
 
-	// Find EOI marker (0xFFD9)
-	// Track last-scanned position to avoid O(n^2) rescanning on each Read()
-	scanFrom := 1
-	for {
-		// Check timeout
-		if time.Since(frameStart) > frameTimeout {
-			*frameData = (*frameData)[:0]
-			return nil, fmt.Errorf("timeout finding EOI marker")
-		}
-
-		if len(*frameData) > 10 {
-			for i := scanFrom; i < len(*frameData); i++ {
-				if (*frameData)[i-1] == 0xFF && (*frameData)[i] == 0xD9 {
-					// Found complete frame - copy the JPEG data
-					jpegData := make([]byte, i+1)
-					copy(jpegData, (*frameData)[:i+1])
-
-					// Keep remaining data for next frame
-					if i+1 < len(*frameData) {
-						remaining := (*frameData)[i+1:]
-						*frameData = append((*frameData)[:0], remaining...)
-					} else {
-						*frameData = (*frameData)[:0]
-					}
-
-					return jpegData, nil
-				}
-			}
-			// Next time, start scanning from where we left off minus 1
-			// (minus 1 because the EOI marker spans two bytes)
-			scanFrom = len(*frameData) - 1
-			if scanFrom < 1 {
-				scanFrom = 1
-			}
-		}
-
-		// Read more data
-		n, err := reader.Read(buffer)
+// decodeFrame turns a source frame into an image.
+// Returns nil on decode failure - caller should skip this frame
+func (cw *CaptureWorker) decodeFrame(f Frame) image.Image {
+	if f.Image != nil {
+		return f.Image
+	}
+	switch f.Format {
+	case PixelFormatMJPEG:
+		return cw.decodeJPEG(f.Data)
+	case PixelFormatYUYV:
+		img, err := yuyvToYCbCr(f.Data, f.Width, f.Height)
 		if err != nil {
-			return nil, err
+			return nil
 		}
-
-		*frameData = append(*frameData, buffer[:n]...)
-
-		if len(*frameData) > 200000 {
-			*frameData = (*frameData)[:0]
-			return nil, io.EOF
+		return img
+	case PixelFormatNV12:
+		img, err := nv12ToYCbCr(f.Data, f.Width, f.Height)
+		if err != nil {
+			return nil
 		}
+		return img
+	default:
+		return nil
 	}
 }
 
 // decodeJPEG decodes raw JPEG bytes to image
 // Returns nil on decode failure - caller should skip this frame
 func (cw *CaptureWorker) decodeJPEG(jpegData []byte) image.Image {
-	img, err := jpeg.Decode(bytes.NewReader(jpegData))
+	// Camera MJPEG passed through untouched may lack Huffman tables
+	img, err := jpeg.Decode(bytes.NewReader(withHuffmanTables(jpegData)))
 	if err != nil {
 		return nil
 	}
@@ -523,7 +422,7 @@ func (cw *CaptureWorker) decodeJPEG(jpegData []byte) image.Image {
 // runTestPatternLoop generates test patterns when real camera is unavailable
 // Periodically attempts to reconnect to the real camera
 func (cw *CaptureWorker) runTestPatternLoop() {
-	log.Printf("[Capture] Camera %s: Using test pattern mode (real camera unavailable)", cw.camera.DeviceID)
+	log.Printf("[Capture] %s: Using test pattern mode (real camera unavailable)", cw.camera.Label(cw.slot))
 
 	// Try to reconnect to real camera every 10 seconds
 	retryTicker := time.NewTicker(10 * time.Second)
@@ -545,19 +444,24 @@ func (cw *CaptureWorker) runTestPatternLoop() {
 
 			// Log retry attempts (not too frequently)
 			if time.Since(lastRetryLog) > 30*time.Second {
-				log.Printf("[Capture] Camera %s: Retry #%d - attempting to reconnect...",
-					cw.camera.DeviceID, retryCount)
+				log.Printf("[Capture] %s: Retry #%d - attempting to reconnect...",
+					cw.camera.Label(cw.slot), retryCount)
 				lastRetryLog = time.Now()
 			}
 
 			if cw.tryRealCameraCapture() {
-				log.Printf("[Capture] Camera %s: Reconnected to real camera after %d retries!",
-					cw.camera.DeviceID, retryCount)
+				log.Printf("[Capture] %s: Reconnected to real camera after %d retries!",
+					cw.camera.Label(cw.slot), retryCount)
 				return // Exit test pattern loop - real camera is working
 			}
 
 		default:
-			frame := cw.generateTestFrame(int(cw.frameCount.Load()))
+			testFrame, err := cw.fallback.NextFrame()
+			if err != nil || testFrame.Image == nil {
+				time.Sleep(frameInterval)
+				continue
+			}
+			frame := testFrame.Image
 			cw.frameCount.Add(1)
 			cw.lastFrameTime.Store(time.Now().UnixNano())
 
@@ -575,78 +479,3 @@ func (cw *CaptureWorker) sendFrame(frame image.Image) {
 		cw.frameBuffer.Write(frame)
 	}
 }
-
-// generateTestFrame creates a test frame for development (fallback)
-func (cw *CaptureWorker) generateTestFrame(frameNum int) image.Image {
-	width, height := cw.settings.Width, cw.settings.Height
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-	stride := img.Stride
-
-	// Create realistic patterns that simulate camera input
-	cameraIDNum := 0
-	if len(cw.camera.DeviceID) > 5 { // "videoX" format
-		if num, err := strconv.Atoi(cw.camera.DeviceID[5:]); err == nil {
-			cameraIDNum = num
-		}
-	}
-
-	// Cache time.Now() once per frame instead of per-pixel
-	now := time.Now()
-	sec := now.Second()
-	timestamp := int(now.Unix())
-
-	for y := 0; y < height; y++ {
-		rowOff := y * stride
-		for x := 0; x < width; x++ {
-			var r, g, b uint8
-
-			switch cameraIDNum {
-			case 0: // Camera 0 - Blue sky scene
-				gradient := float64(y) / float64(height)
-				r = uint8(135 * (1 - gradient))
-				g = uint8(206 * (1 - gradient))
-				b = uint8(250 * (1 - gradient))
-
-				if x%80 < 20 && y%60 < 15 {
-					white := uint8(200 + int(15*sec%55))
-					r, g, b = white, white, white
-				}
-
-			case 1: // Camera 1 - Green landscape
-				r = uint8(50 + 20*sec%30)
-				g = uint8(120 + 30*sec%40)
-				b = uint8(50)
-
-				if x%100 < 10 && y%100 < 10 {
-					r, g, b = 255, 100, 100
-				}
-
-			case 2: // Camera 2 - Urban scene
-				gray := uint8(128 + 50*sec%80)
-				r, g, b = gray, gray, gray
-
-				if (x%40 < 5 || y%30 < 3) && x+y > 200 {
-					r, g, b = 180, 180, 200
-				}
-
-			default: // Default multi-color pattern
-				r = uint8((x + frameNum) % 256)
-				g = uint8((y + frameNum/2) % 256)
-				b = uint8((x + y + frameNum/3) % 256)
-			}
-
-			// Add time overlay to show "live" nature
-			if (timestamp%100) < 50 && x < 50 && y < 20 {
-				r, g, b = 255, 255, 255
-			}
-
-			off := rowOff + x*4
-			img.Pix[off+0] = r
-			img.Pix[off+1] = g
-			img.Pix[off+2] = b
-			img.Pix[off+3] = 255
-		}
-	}
-
-	return img
-}
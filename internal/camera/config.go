@@ -1,5 +1,10 @@
 package camera
 
+import (
+	"sort"
+	"strings"
+)
+
 // =============================================================================
 // Camera Settings
 // =============================================================================
@@ -14,6 +19,7 @@ const (
 	DefaultFPS        = 25
 	DefaultFormat     = "mjpeg"
 	DefaultMaxCameras = 3
+	DefaultBackend    = "ffmpeg"
 )
 
 // Settings holds camera capture configuration.
@@ -24,6 +30,108 @@ type Settings struct {
 	FPS        int    // Target frames per second
 	Format     string // Capture format: "mjpeg" or "yuyv"
 	MaxCameras int    // Maximum number of cameras to discover/use
+	Backend    string // Capture backend: "ffmpeg" or "v4l2" (native, no child process)
+
+	// SlotPins pins camera identities to slots (index = slot, "" = unpinned).
+	// Keys are matched with Camera.Matches, e.g. "1-1.3" or "046d:0825".
+	SlotPins []string
+
+	// Cameras holds per-camera overrides keyed like SlotPins. ForCamera
+	// resolves them into the effective settings for one camera.
+	Cameras map[string]CameraOverride
+
+	// Rotation is the clockwise rotation in degrees (0/90/180/270) applied to
+	// decoded frames, after Mirror (left/right) and Flip (top/bottom). Only
+	// meaningful per camera, i.e. after ForCamera.
+	Rotation int
+	Mirror   bool
+	Flip     bool
+
+	// Crop is the part of the (transformed) frame that is kept. Only
+	// meaningful per camera; CaptureWorker.SetCrop changes it at runtime.
+	Crop Crop
+}
+
+// Transform returns the frame transform of the settings.
+func (s Settings) Transform() Transform {
+	return Transform{Rotation: s.Rotation, Mirror: s.Mirror, Flip: s.Flip}
+}
+
+// CameraOverride holds per-camera settings from a [camera.<id-or-port>]
+// config section. Zero values inherit the global Settings.
+type CameraOverride struct {
+	Width    int
+	Height   int
+	FPS      int
+	Format   string
+	Name     string
+	Role     string
+	Rotation int
+	Mirror   bool   // Swap left and right (e.g. side cameras)
+	Flip     bool   // Swap top and bottom
+	Crop     Crop   // Shown part of the frame; zero = full frame
+	NoMotion bool   // Excluded from motion detection
+	Zones    string // Motion detection zones (motion.ParseZones format)
+	Filters  string // Display filter chain (filter.Parse format); "" = global
+
+	// Fisheye correction for display (see package dewarp); zero = none
+	DewarpK1    float64
+	DewarpK2    float64
+	DewarpScale float64
+	DewarpFile  string // Calibration file; the values above override it
+}
+
+// OverrideFor returns the override matching cam, if any. When several keys
+// match (e.g. port path and vendor:product), the first in sorted order wins.
+func (s Settings) OverrideFor(cam Camera) (CameraOverride, bool) {
+	key, ok := s.OverrideKey(cam)
+	if !ok {
+		return CameraOverride{}, false
+	}
+	return s.Cameras[key], true
+}
+
+// OverrideKey returns the key of the override OverrideFor picks for cam.
+func (s Settings) OverrideKey(cam Camera) (string, bool) {
+	if len(s.Cameras) == 0 {
+		return "", false
+	}
+	keys := make([]string, 0, len(s.Cameras))
+	for key := range s.Cameras {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if cam.Matches(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// ForCamera returns the settings for one camera with its override applied.
+func (s Settings) ForCamera(cam Camera) Settings {
+	o, ok := s.OverrideFor(cam)
+	if !ok {
+		return s
+	}
+	if o.Width > 0 {
+		s.Width = o.Width
+	}
+	if o.Height > 0 {
+		s.Height = o.Height
+	}
+	if o.FPS > 0 {
+		s.FPS = o.FPS
+	}
+	if f := strings.ToLower(o.Format); f == "mjpeg" || f == "yuyv" {
+		s.Format = f
+	}
+	s.Rotation = o.Rotation
+	s.Mirror = o.Mirror
+	s.Flip = o.Flip
+	s.Crop = o.Crop
+	return s
 }
 
 // DefaultSettings returns sensible defaults for vehicle camera monitoring.
@@ -34,5 +142,6 @@ func DefaultSettings() Settings {
 		FPS:        DefaultFPS,
 		Format:     DefaultFormat,
 		MaxCameras: DefaultMaxCameras,
+		Backend:    DefaultBackend,
 	}
 }
@@ -0,0 +1,132 @@
+package camera
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinCropSize is the smallest crop edge, as a fraction of the frame.
+const MinCropSize = 0.05
+
+// Crop is the part of a frame that is kept, in normalized coordinates
+// (0,0 top-left, 1,1 bottom-right). The zero value keeps the whole frame.
+type Crop struct {
+	X, Y, W, H float64
+}
+
+// FullFrame is the crop that keeps the whole frame.
+var FullFrame = Crop{W: 1, H: 1}
+
+// ParseCrop parses "x,y,w,h" (normalized); "" is the full frame.
+func ParseCrop(s string) (Crop, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Crop{}, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Crop{}, fmt.Errorf("want x,y,w,h, got %q", s)
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return Crop{}, fmt.Errorf("bad number %q", strings.TrimSpace(p))
+		}
+		v[i] = f
+	}
+	c := Crop{X: v[0], Y: v[1], W: v[2], H: v[3]}
+	if err := c.Validate(); err != nil {
+		return Crop{}, err
+	}
+	return c, nil
+}
+
+// Validate checks that c lies within the frame and is not too small. The
+// full frame (including the zero value) is valid.
+func (c Crop) Validate() error {
+	const eps = 1e-9
+	if c.IsFull() {
+		return nil
+	}
+	if c.X < 0 || c.Y < 0 || c.X+c.W > 1+eps || c.Y+c.H > 1+eps {
+		return fmt.Errorf("crop %v is outside the frame", c)
+	}
+	if c.W < MinCropSize-eps || c.H < MinCropSize-eps {
+		return fmt.Errorf("crop %v is smaller than %g of the frame", c, MinCropSize)
+	}
+	return nil
+}
+
+// IsFull reports whether c keeps the whole frame.
+func (c Crop) IsFull() bool {
+	n := c.normalized()
+	return n.X <= 0 && n.Y <= 0 && n.W >= 1 && n.H >= 1
+}
+
+// normalized returns c with the zero value replaced by FullFrame.
+func (c Crop) normalized() Crop {
+	if c.W <= 0 || c.H <= 0 {
+		return FullFrame
+	}
+	return c
+}
+
+// String formats c for ParseCrop; "" for the full frame.
+func (c Crop) String() string {
+	if c.IsFull() {
+		return ""
+	}
+	f := func(v float64) string { return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64) }
+	return f(c.X) + "," + f(c.Y) + "," + f(c.W) + "," + f(c.H)
+}
+
+// Within returns inner, given relative to c, as a crop of the whole frame.
+// Zooming into a cropped picture and keeping the view uses this.
+func (c Crop) Within(inner Crop) Crop {
+	c, inner = c.normalized(), inner.normalized()
+	return Crop{
+		X: c.X + inner.X*c.W,
+		Y: c.Y + inner.Y*c.H,
+		W: inner.W * c.W,
+		H: inner.H * c.H,
+	}
+}
+
+// Rect returns the pixel rectangle of c within bounds.
+func (c Crop) Rect(bounds image.Rectangle) image.Rectangle {
+	c = c.normalized()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	r := image.Rect(
+		bounds.Min.X+int(math.Round(c.X*w)),
+		bounds.Min.Y+int(math.Round(c.Y*h)),
+		bounds.Min.X+int(math.Round((c.X+c.W)*w)),
+		bounds.Min.Y+int(math.Round((c.Y+c.H)*h)),
+	).Intersect(bounds)
+	if r.Empty() {
+		return bounds
+	}
+	return r
+}
+
+// Apply returns the cropped part of img. Images with a SubImage method (all
+// the decoded frame types) share their pixels instead of being copied.
+func (c Crop) Apply(img image.Image) image.Image {
+	b := img.Bounds()
+	r := c.Rect(b)
+	if r == b {
+		return img
+	}
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,82 @@
+package camera
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseCrop(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Crop
+		wantErr bool
+	}{
+		{"", Crop{}, false},
+		{"0.1, 0.2, 0.5, 0.6", Crop{X: 0.1, Y: 0.2, W: 0.5, H: 0.6}, false},
+		{"0,0,1,1", Crop{W: 1, H: 1}, false},
+		{"0,0,1", Crop{}, true},
+		{"0,0,x,1", Crop{}, true},
+		{"0.6,0,0.5,1", Crop{}, true}, // Past the right edge
+		{"0,0,0.01,1", Crop{}, true},  // Too small
+	}
+	for _, tt := range tests {
+		got, err := ParseCrop(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseCrop(%q) = %+v, %v; want %+v, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	c := Crop{X: 0.125, Y: 0, W: 0.33333, H: 1}
+	if got := c.String(); got != "0.125,0,0.3333,1" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := FullFrame.String(); got != "" {
+		t.Errorf("FullFrame.String() = %q, want empty", got)
+	}
+}
+
+func TestCrop_Within(t *testing.T) {
+	outer := Crop{X: 0.2, Y: 0.1, W: 0.5, H: 0.8}
+	got := outer.Within(Crop{X: 0.5, Y: 0.5, W: 0.5, H: 0.5})
+	want := Crop{X: 0.45, Y: 0.5, W: 0.25, H: 0.4}
+	if got != want {
+		t.Errorf("Within = %+v, want %+v", got, want)
+	}
+	if got := (Crop{}).Within(outer); got != outer {
+		t.Errorf("full frame Within = %+v, want %+v", got, outer)
+	}
+}
+
+func TestCrop_ApplySharesPixels(t *testing.T) {
+	img := image.NewYCbCr(image.Rect(0, 0, 8, 4), image.YCbCrSubsampleRatio420)
+	c := Crop{X: 0.5, Y: 0.5, W: 0.5, H: 0.5}
+
+	got, ok := c.Apply(img).(*image.YCbCr)
+	if !ok {
+		t.Fatalf("Apply returned %T, want *image.YCbCr", c.Apply(img))
+	}
+	if got.Bounds() != image.Rect(4, 2, 8, 4) {
+		t.Errorf("bounds = %v, want (4,2)-(8,4)", got.Bounds())
+	}
+	img.Y[img.YOffset(5, 3)] = 200
+	if y := got.YCbCrAt(5, 3).Y; y != 200 {
+		t.Errorf("cropped image does not share pixels (Y = %d)", y)
+	}
+	if full := FullFrame.Apply(img); full != image.Image(img) {
+		t.Error("full frame should return the source image")
+	}
+
+	// Images without SubImage are copied
+	gray := &plainImage{image.NewGray(image.Rect(0, 0, 8, 4))}
+	if b := c.Apply(gray).Bounds(); b != image.Rect(0, 0, 4, 2) {
+		t.Errorf("copied crop bounds = %v, want 4x2", b)
+	}
+}
+
+// plainImage hides the SubImage method of the wrapped image.
+type plainImage struct{ img *image.Gray }
+
+func (p *plainImage) ColorModel() color.Model { return p.img.ColorModel() }
+func (p *plainImage) Bounds() image.Rectangle { return p.img.Bounds() }
+func (p *plainImage) At(x, y int) color.Color { return p.img.At(x, y) }
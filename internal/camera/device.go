@@ -26,10 +26,21 @@ type Camera struct {
 	DeviceID     string
 	DevicePath   string
 	Name         string
+	Role         string // Configured role, e.g. "Left" or "Rear" ("" if none)
 	Available    bool
+	Identity     CameraIdentity // Persistent USB identity (port path, vid/pid, serial)
 	Capabilities CameraCapabilities
 }
 
+// Label returns the role for logs and on-screen labels, or "Camera <slot>"
+// when no role is configured.
+func (c Camera) Label(slot int) string {
+	if c.Role != "" {
+		return c.Role
+	}
+	return fmt.Sprintf("Camera %d", slot)
+}
+
 // DiscoverCamerasWithSettings finds all available USB camera devices on Linux
 // using the provided settings for resolution/FPS defaults.
 func DiscoverCamerasWithSettings(s Settings) ([]Camera, error) {
@@ -95,8 +106,9 @@ func DiscoverCamerasWithSettings(s Settings) ([]Camera, error) {
 		}
 	}
 
-	// Limit to configured number of cameras
-	if len(devicePaths) > maxCameras {
+	// Limit to configured number of cameras. With pinned slots every camera is
+	// kept so a pinned one is not cut off; the Manager trims after arranging.
+	if len(devicePaths) > maxCameras && !hasSlotPins(s) {
 		devicePaths = devicePaths[:maxCameras]
 	}
 
@@ -110,8 +122,10 @@ func DiscoverCamerasWithSettings(s Settings) ([]Camera, error) {
 			DevicePath: dev.path,
 			Name:       cleanCameraName(dev.name),
 			Available:  true,
+			Identity:   LookupIdentity(dev.path),
 		}
-		cam.Capabilities = queryCameraCapabilities(dev.path, numCameras, s)
+		// Per-camera overrides pick the resolution/FPS this camera is asked for
+		cam.Capabilities = queryCameraCapabilities(dev.path, numCameras, s.ForCamera(cam))
 		cameras = append(cameras, cam)
 	}
 
@@ -130,13 +144,23 @@ func DiscoverCamerasWithSettings(s Settings) ([]Camera, error) {
 
 	log.Printf("[Discovery] Found %d cameras", len(cameras))
 	for _, cam := range cameras {
-		log.Printf("[Discovery]   %s: %dx%d @ %dfps (%s)",
+		log.Printf("[Discovery]   %s: %dx%d @ %dfps (%s) [%s]",
 			cam.DeviceID, cam.Capabilities.MaxWidth, cam.Capabilities.MaxHeight,
-			cam.Capabilities.MaxFPS, cam.Capabilities.Format)
+			cam.Capabilities.MaxFPS, cam.Capabilities.Format, cam.Identity)
 	}
 	return cameras, nil
 }
 
+// hasSlotPins reports whether any camera identity is pinned to a slot.
+func hasSlotPins(s Settings) bool {
+	for _, key := range s.SlotPins {
+		if strings.TrimSpace(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // DiscoverCameras finds all available USB camera devices using default settings.
 // Prefer DiscoverCamerasWithSettings for config-driven discovery.
 func DiscoverCameras() ([]Camera, error) {
@@ -347,7 +371,7 @@ func discoverCamerasSimple(s Settings) ([]Camera, error) {
 			devicePaths = append(devicePaths, devicePath)
 		}
 
-		if len(devicePaths) >= maxCameras {
+		if len(devicePaths) >= maxCameras && !hasSlotPins(s) {
 			break
 		}
 	}
@@ -361,8 +385,9 @@ func discoverCamerasSimple(s Settings) ([]Camera, error) {
 			DevicePath: devicePath,
 			Name:       fmt.Sprintf("Camera %d", i+1),
 			Available:  true,
+			Identity:   LookupIdentity(devicePath),
 		}
-		cam.Capabilities = queryCameraCapabilities(devicePath, numCameras, s)
+		cam.Capabilities = queryCameraCapabilities(devicePath, numCameras, s.ForCamera(cam))
 		cameras = append(cameras, cam)
 	}
 
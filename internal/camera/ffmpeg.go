@@ -0,0 +1,267 @@
+package camera
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"sync"
+)
+
+// =============================================================================
+// FFmpeg/V4L2 Frame Source
+// =============================================================================
+// Spawns FFmpeg against a V4L2 device and splits the MJPEG or rawvideo
+// stream it writes to stdout into frames. The configured input format is tried first, then fallbacks
+// (mjpeg copy -> mjpeg re-encode -> yuyv422 -> auto). When a stream ends,
+// the next format is started transparently; io.EOF is only returned once
+// every format has failed.
+//
+// A camera's mirror/flip/rotate transform is done by FFmpeg (-vf) in every
+// candidate that decodes anyway, which is much cheaper than transforming in
+// Go. MJPEG passthrough cannot filter, so with a transform it moves behind
+// the re-encode and leaves the transform to the capture worker.
+// =============================================================================
+
+// FFmpegSource captures frames from a V4L2 device through an FFmpeg child process.
+type FFmpegSource struct {
+	camera   Camera
+	settings Settings
+	caps     CameraCapabilities
+
+	mu         sync.Mutex
+	cmd        *exec.Cmd
+	stdout     io.ReadCloser
+	candidates []ffmpegCandidate
+	next       int    // Index of the next candidate to try
+	closed     bool   // Set by Close so fallbacks stop spawning processes
+	framer     framer // Matches the running candidate's output format
+	filtered   bool   // Running candidate applies the transform
+}
+
+// ffmpegCandidate is one way of running FFmpeg and the format it outputs.
+type ffmpegCandidate struct {
+	args     []string
+	output   PixelFormat
+	filtered bool // Output already has Settings.Transform applied
+}
+
+// NewFFmpegSource creates an FFmpeg source for a discovered camera.
+// It matches the SourceFactory signature.
+func NewFFmpegSource(cam Camera, s Settings) FrameSource {
+	caps := cam.Capabilities
+
+	// Ensure we have valid defaults from settings
+	if caps.MaxWidth == 0 {
+		caps.MaxWidth = s.Width
+	}
+	if caps.MaxHeight == 0 {
+		caps.MaxHeight = s.Height
+	}
+	if caps.MaxFPS == 0 {
+		caps.MaxFPS = s.FPS
+	}
+	if caps.Format == "" {
+		caps.Format = s.Format
+	}
+
+	return &FFmpegSource{
+		camera:   cam,
+		settings: s,
+		caps:     caps,
+	}
+}
+
+// Capabilities returns the resolution and FPS FFmpeg is asked to capture at.
+func (fs *FFmpegSource) Capabilities() CameraCapabilities {
+	return fs.caps
+}
+
+// Open builds the format fallback list and starts FFmpeg with the first
+// candidate that launches.
+func (fs *FFmpegSource) Open() error {
+	log.Printf("[Capture] Camera %s: Vehicle mode - %dx%d @ %d FPS (%s, fixed)",
+		fs.camera.DeviceID, fs.caps.MaxWidth, fs.caps.MaxHeight, fs.caps.MaxFPS, fs.settings.Format)
+
+	fs.mu.Lock()
+	fs.closed = false
+	fs.candidates = fs.buildCandidates()
+	fs.next = 0
+	fs.mu.Unlock()
+
+	if !fs.startNext() {
+		return fmt.Errorf("no FFmpeg input format could be started for %s", fs.camera.DevicePath)
+	}
+	return nil
+}
+
+// NextFrame reads the next frame from FFmpeg's output without decoding it.
+// If the stream ends, the next fallback format is started before giving up.
+func (fs *FFmpegSource) NextFrame() (Frame, error) {
+	for {
+		fs.mu.Lock()
+		stdout := fs.stdout
+		fr := fs.framer
+		filtered := fs.filtered
+		fs.mu.Unlock()
+		if stdout == nil {
+			return Frame{}, io.EOF
+		}
+
+		frame, err := fr.readFrame(stdout)
+		if err == nil {
+			frame.Transformed = filtered
+			return frame, nil
+		}
+		if err != io.EOF {
+			return Frame{}, err
+		}
+
+		log.Printf("[Capture] Camera %s: FFmpeg stream ended", fs.camera.DeviceID)
+		if !fs.startNext() {
+			return Frame{}, io.EOF
+		}
+	}
+}
+
+// Close kills FFmpeg (unblocking any pending read) and stops further fallbacks.
+func (fs *FFmpegSource) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.closed = true
+	fs.killLocked()
+	return nil
+}
+
+// killLocked kills and reaps the current FFmpeg process. Caller holds fs.mu.
+func (fs *FFmpegSource) killLocked() {
+	if fs.cmd != nil && fs.cmd.Process != nil {
+		fs.cmd.Process.Kill()
+		fs.cmd.Wait() // Reap zombie process
+	}
+	fs.cmd = nil
+	fs.stdout = nil
+}
+
+// startNext stops the current process and starts the next candidate format.
+// Returns false when the source was closed or every candidate has been tried.
+func (fs *FFmpegSource) startNext() bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.killLocked()
+
+	for fs.next < len(fs.candidates) {
+		if fs.closed {
+			return false // Shutting down, don't try more formats
+		}
+		candidate := fs.candidates[fs.next]
+		args := candidate.args
+		fs.next++
+
+		log.Printf("[Capture] Camera %s: Trying FFmpeg with args: %v", fs.camera.DeviceID, args)
+
+		cmd := exec.Command("ffmpeg", args...)
+		cmd.Stderr = nil // Suppress FFmpeg stderr output
+
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			log.Printf("[Capture] Camera %s: Failed to create stdout pipe: %v", fs.camera.DeviceID, err)
+			continue
+		}
+		if err := cmd.Start(); err != nil {
+			log.Printf("[Capture] Camera %s: Failed to start FFmpeg: %v", fs.camera.DeviceID, err)
+			continue
+		}
+
+		width, height := fs.caps.MaxWidth, fs.caps.MaxHeight
+		if candidate.filtered && fs.settings.Transform().swapsSize() {
+			width, height = height, width
+		}
+		fs.cmd = cmd
+		fs.stdout = stdout
+		fs.framer = newFramer(candidate.output, width, height, fs.caps.MaxFPS)
+		fs.filtered = candidate.filtered
+		log.Printf("[Capture] Camera %s: FFmpeg started - %dx%d @ %d FPS (PID: %d)",
+			fs.camera.DeviceID, fs.caps.MaxWidth, fs.caps.MaxHeight, fs.caps.MaxFPS, cmd.Process.Pid)
+		return true
+	}
+	return false
+}
+
+// buildCandidates returns FFmpeg invocations in the order they should be tried.
+// The configured format is tried first, then fallbacks. MJPEG input is first
+// passed through with -c:v copy (no decode/re-encode); if that stream fails,
+// the same input is retried with a re-encode. YUYV input is emitted as
+// rawvideo so Go converts it directly instead of decoding a JPEG. With a
+// transform, every decoding candidate filters and the passthrough comes last.
+func (fs *FFmpegSource) buildCandidates() []ffmpegCandidate {
+	videoSize := fmt.Sprintf("%dx%d", fs.caps.MaxWidth, fs.caps.MaxHeight)
+	fpsStr := fmt.Sprintf("%d", fs.caps.MaxFPS)
+	devicePath := fs.camera.DevicePath
+
+	transform := fs.settings.Transform()
+	filter := transform.ffmpegFilter()
+	outputSize := videoSize
+	if transform.swapsSize() {
+		outputSize = fmt.Sprintf("%dx%d", fs.caps.MaxHeight, fs.caps.MaxWidth)
+	}
+	var filterArgs []string
+	if filter != "" {
+		filterArgs = []string{"-vf", filter}
+	}
+	filtered := filter != ""
+
+	// Common FFmpeg args for all formats
+	commonArgs := []string{"-thread_queue_size", "512", "-probesize", "32", "-analyzeduration", "0"}
+	encodeArgs := append(filterArgs, "-f", "image2pipe", "-vcodec", "mjpeg", "-q:v", "5", "-")
+	copyArgs := []string{"-f", "mjpeg", "-c:v", "copy", "-"}
+	// Raw output is forced to the requested size so every frame has a known length
+	yuyvArgs := append(filterArgs, "-f", "rawvideo", "-pix_fmt", "yuyv422", "-s", outputSize, "-")
+	nv12Args := append(filterArgs, "-f", "rawvideo", "-pix_fmt", "nv12", "-s", outputSize, "-")
+
+	// buildArgs safely constructs FFmpeg args without mutating the shared slices.
+	// Using append(append(commonArgs, ...), outputArgs...) would corrupt commonArgs
+	// on subsequent calls if the first append didn't grow the backing array.
+	buildArgs := func(outputArgs []string, inputArgs ...string) []string {
+		args := make([]string, 0, len(commonArgs)+len(inputArgs)+len(outputArgs))
+		args = append(args, commonArgs...)
+		args = append(args, inputArgs...)
+		args = append(args, outputArgs...)
+		return args
+	}
+
+	mjpegInput := []string{"-f", "v4l2", "-input_format", "mjpeg", "-video_size", videoSize,
+		"-framerate", fpsStr, "-i", devicePath}
+	yuyvInput := []string{"-f", "v4l2", "-input_format", "yuyv422", "-video_size", videoSize,
+		"-framerate", fpsStr, "-i", devicePath}
+	passthrough := ffmpegCandidate{buildArgs(copyArgs, mjpegInput...), PixelFormatMJPEG, false}
+	reencode := ffmpegCandidate{buildArgs(encodeArgs, mjpegInput...), PixelFormatMJPEG, filtered}
+	mjpegFormats := []ffmpegCandidate{passthrough, reencode} // Re-encode is the fallback
+	if filtered {
+		mjpegFormats = []ffmpegCandidate{reencode, passthrough}
+	}
+	yuyvFormat := ffmpegCandidate{buildArgs(yuyvArgs, yuyvInput...), PixelFormatYUYV, filtered}
+
+	var formats []ffmpegCandidate
+
+	// Primary format from config
+	if fs.settings.Format == "mjpeg" {
+		formats = append(formats, mjpegFormats...)
+		// YUYV fallback
+		formats = append(formats, yuyvFormat)
+	} else if fs.settings.Format == "yuyv" {
+		// YUYV first if configured
+		formats = append(formats, yuyvFormat)
+		// MJPEG fallback
+		formats = append(formats, mjpegFormats...)
+	}
+
+	// Auto format detection as last resort; FFmpeg converts whatever the
+	// camera offers to NV12, which is cheaper than a JPEG encode + decode
+	formats = append(formats, ffmpegCandidate{buildArgs(nv12Args,
+		"-f", "v4l2", "-video_size", videoSize,
+		"-framerate", fpsStr, "-i", devicePath), PixelFormatNV12, filtered})
+
+	return formats
+}
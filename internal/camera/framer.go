@@ -0,0 +1,226 @@
+package camera
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// =============================================================================
+// Stream Framers
+// =============================================================================
+// A framer splits a byte stream (FFmpeg stdout) into frames. Which one is used
+// depends on the pixel format the stream carries:
+//   - MJPEG: variable-size JPEGs, found by scanning for SOI/EOI markers
+//   - YUYV/NV12 rawvideo: fixed-size frames, read by length with no scanning
+// =============================================================================
+
+// framer reads one frame at a time from a stream.
+type framer interface {
+	readFrame(reader io.Reader) (Frame, error)
+	reset()
+}
+
+// newFramer returns the framer for a stream of the given pixel format.
+func newFramer(format PixelFormat, width, height, fps int) framer {
+	switch format {
+	case PixelFormatYUYV, PixelFormatNV12:
+		return newRawFramer(format, width, height)
+	default:
+		return newMJPEGFramer(fps)
+	}
+}
+
+// =============================================================================
+// Raw video framer
+// =============================================================================
+
+// rawFramer reads fixed-size raw frames. The frame size is known up front
+// from the pixel format and resolution, so no marker scanning is needed.
+type rawFramer struct {
+	format    PixelFormat
+	width     int
+	height    int
+	frameSize int
+}
+
+// newRawFramer creates a framer for width x height frames in format.
+func newRawFramer(format PixelFormat, width, height int) *rawFramer {
+	return &rawFramer{
+		format:    format,
+		width:     width,
+		height:    height,
+		frameSize: rawFrameSize(format, width, height),
+	}
+}
+
+// rawFrameSize returns the byte size of one raw frame.
+func rawFrameSize(format PixelFormat, width, height int) int {
+	switch format {
+	case PixelFormatYUYV:
+		return width * height * 2
+	case PixelFormatNV12:
+		return width*height + 2*((width+1)/2)*((height+1)/2)
+	default:
+		return 0
+	}
+}
+
+// reset is a no-op; raw frames are read whole so nothing is carried over.
+func (f *rawFramer) reset() {}
+
+// readFrame reads exactly one frame. A truncated frame means the stream ended.
+func (f *rawFramer) readFrame(reader io.Reader) (Frame, error) {
+	if f.frameSize <= 0 {
+		return Frame{}, fmt.Errorf("invalid raw frame size for %s %dx%d", f.format, f.width, f.height)
+	}
+	data := make([]byte, f.frameSize)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return Frame{}, io.EOF
+	}
+	return Frame{Data: data, Format: f.format, Width: f.width, Height: f.height}, nil
+}
+
+// =============================================================================
+// MJPEG stream framer
+// =============================================================================
+
+// mjpegMaxFrameBytes caps a single frame. Passthrough frames keep the camera's
+// own quality, so this is well above what a -q:v 5 re-encode produces.
+const mjpegMaxFrameBytes = 2 << 20
+
+// mjpegFramer splits a concatenated MJPEG byte stream into JPEG images by
+// scanning for SOI/EOI markers. Buffers are reused across frames.
+type mjpegFramer struct {
+	fps       int    // Stream frame rate, used to size the read timeout
+	buffer    []byte // Read buffer
+	frameData []byte // Bytes read but not yet returned as a frame
+}
+
+// newMJPEGFramer creates a framer for a stream running at fps.
+func newMJPEGFramer(fps int) *mjpegFramer {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	return &mjpegFramer{
+		fps:       fps,
+		buffer:    make([]byte, 8192),     // Larger buffer for fewer syscalls
+		frameData: make([]byte, 0, 65536), // Pre-allocate typical JPEG size
+	}
+}
+
+// reset discards any partially read frame (used when the stream changes).
+func (f *mjpegFramer) reset() {
+	f.frameData = f.frameData[:0]
+}
+
+// readFrame reads raw JPEG bytes from stream without decoding
+// Returns the raw JPEG data and any error. Caller decides whether to decode.
+// Has built-in timeout to prevent blocking during camera issues (vibration, USB hiccups)
+func (f *mjpegFramer) readFrame(reader io.Reader) (Frame, error) {
+	jpegData, err := f.readMJPEGFrameRaw(reader)
+	if err != nil {
+		if err != io.EOF {
+			// Clear frameData to resync on next frame
+			f.frameData = f.frameData[:0]
+		}
+		return Frame{}, err
+	}
+	return Frame{Data: jpegData, Format: PixelFormatMJPEG}, nil
+}
+
+// readMJPEGFrameRaw scans the stream for one complete SOI..EOI frame.
+func (f *mjpegFramer) readMJPEGFrameRaw(reader io.Reader) ([]byte, error) {
+	// Timeout for reading a complete frame (prevents freeze during vibration)
+	timeout := frameTimeout(f.fps)
+	frameStart := time.Now()
+
+	// Find SOI marker (0xFFD8), starting with any bytes left over from the last frame
+	foundSOI := false
+	for !foundSOI {
+		for i := 0; i < len(f.frameData)-1; i++ {
+			if f.frameData[i] == 0xFF && f.frameData[i+1] == 0xD8 {
+				f.frameData = f.frameData[i:]
+				foundSOI = true
+				break
+			}
+		}
+		if foundSOI {
+			break
+		}
+
+		// Prevent runaway buffer growth
+		if len(f.frameData) > 100000 {
+			f.frameData = f.frameData[len(f.frameData)-10000:]
+		}
+
+		// Check timeout
+		if time.Since(frameStart) > timeout {
+			f.frameData = f.frameData[:0]
+			return nil, fmt.Errorf("timeout finding SOI marker")
+		}
+
+		n, err := reader.Read(f.buffer)
+		if err != nil {
+			return nil, err
+		}
+		f.frameData = append(f.frameData, f.buffer[:n]...)
+	}
+
+	// Find EOI marker (0xFFD9)
+	// Track last-scanned position to avoid O(n^2) rescanning on each Read()
+	scanFrom := 2 // Skip the SOI marker itself
+	for {
+		for i := scanFrom; i < len(f.frameData); i++ {
+			if f.frameData[i-1] == 0xFF && f.frameData[i] == 0xD9 {
+				// Found complete frame - copy the JPEG data
+				jpegData := make([]byte, i+1)
+				copy(jpegData, f.frameData[:i+1])
+
+				// Keep remaining data for next frame
+				remaining := f.frameData[i+1:]
+				f.frameData = append(f.frameData[:0], remaining...)
+
+				return jpegData, nil
+			}
+		}
+		// Next time, start scanning from where we left off minus 1
+		// (minus 1 because the EOI marker spans two bytes)
+		scanFrom = len(f.frameData) - 1
+		if scanFrom < 2 {
+			scanFrom = 2
+		}
+
+		// Check timeout
+		if time.Since(frameStart) > timeout {
+			f.frameData = f.frameData[:0]
+			return nil, fmt.Errorf("timeout finding EOI marker")
+		}
+
+		// Read more data
+		n, err := reader.Read(f.buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		f.frameData = append(f.frameData, f.buffer[:n]...)
+
+		if len(f.frameData) > mjpegMaxFrameBytes {
+			f.frameData = f.frameData[:0]
+			return nil, io.EOF
+		}
+	}
+}
+
+// frameTimeout returns how long to wait for one frame before giving up.
+// Scale with FPS: at 30fps a frame is ~33ms, at 5fps ~200ms; add generous margin
+func frameTimeout(fps int) time.Duration {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	timeout := time.Duration(float64(time.Second)/float64(fps)*3) + 50*time.Millisecond
+	if timeout < 150*time.Millisecond {
+		timeout = 150 * time.Millisecond
+	}
+	return timeout
+}
@@ -0,0 +1,206 @@
+package camera
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// Stable Camera Identity
+// =============================================================================
+// /dev/videoX numbers are handed out in enumeration order and change when a
+// camera is replugged or the system reboots. The USB port path (the sysfs
+// name of the USB device, e.g. "1-1.3") stays the same as long as the camera
+// is plugged into the same physical port; vendor/product ID and serial number
+// identify the camera itself wherever it is plugged in.
+//
+// Config refers to a camera with an identity key:
+//   1-1.3             USB port path
+//   046d:0825         vendor:product
+//   046d:0825:A1B2C3  vendor:product:serial
+//   serial:A1B2C3     serial number only
+//   video0            device ID (not stable, but handy for testing)
+// =============================================================================
+
+// sysfsRoot is where sysfs is mounted (overridden in tests).
+var sysfsRoot = "/sys"
+
+// CameraIdentity identifies a physical USB camera independently of its
+// /dev/videoX number. Fields are empty when sysfs does not provide them.
+type CameraIdentity struct {
+	PortPath  string // USB port path, e.g. "1-1.3"
+	VendorID  string // USB idVendor, e.g. "046d"
+	ProductID string // USB idProduct, e.g. "0825"
+	Serial    string // USB serial number (many cheap cameras have none)
+}
+
+// IsZero reports whether no identity information is available.
+func (id CameraIdentity) IsZero() bool {
+	return id == CameraIdentity{}
+}
+
+// String returns a compact description for logging, e.g. "1-1.3 046d:0825 SN=A1B2C3".
+func (id CameraIdentity) String() string {
+	if id.IsZero() {
+		return "unknown"
+	}
+	s := id.PortPath
+	if id.VendorID != "" {
+		s += fmt.Sprintf(" %s:%s", id.VendorID, id.ProductID)
+	}
+	if id.Serial != "" {
+		s += " SN=" + id.Serial
+	}
+	return strings.TrimSpace(s)
+}
+
+// Matches reports whether key (see the formats above) refers to this identity.
+func (id CameraIdentity) Matches(key string) bool {
+	key = strings.TrimSpace(key)
+	if key == "" || id.IsZero() {
+		return false
+	}
+	if serial := strings.TrimPrefix(key, "serial:"); serial != key {
+		return id.Serial != "" && id.Serial == serial
+	}
+
+	parts := strings.Split(key, ":")
+	switch len(parts) {
+	case 1:
+		return id.PortPath != "" && id.PortPath == key
+	case 2:
+		return id.VendorID != "" &&
+			strings.EqualFold(id.VendorID, parts[0]) && strings.EqualFold(id.ProductID, parts[1])
+	case 3:
+		return id.VendorID != "" && id.Serial != "" &&
+			strings.EqualFold(id.VendorID, parts[0]) && strings.EqualFold(id.ProductID, parts[1]) &&
+			id.Serial == parts[2]
+	default:
+		return false
+	}
+}
+
+// Matches reports whether key refers to this camera, either by identity
+// or by device ID ("video0").
+func (c Camera) Matches(key string) bool {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return false
+	}
+	return key == c.DeviceID || c.Identity.Matches(key)
+}
+
+// USBParent returns the sysfs USB device directory for a /dev/videoX device.
+// Two video nodes from the same physical USB camera share the same parent.
+// Returns "" if the parent cannot be determined.
+func USBParent(devPath string) string {
+	var videoNum int
+	_, err := fmt.Sscanf(devPath, "/dev/video%d", &videoNum)
+	if err != nil {
+		return ""
+	}
+	// Resolve the physical device symlink (the USB interface, e.g. 1-1.3:1.0)
+	// and go up one level to the USB device
+	symlinkPath := filepath.Join(sysfsRoot, "class", "video4linux", fmt.Sprintf("video%d", videoNum), "device")
+	resolved, err := filepath.EvalSymlinks(symlinkPath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(resolved)
+}
+
+// LookupIdentity reads the persistent identity of a /dev/videoX device from sysfs.
+// Returns a zero identity for non-USB devices or when sysfs is unavailable.
+func LookupIdentity(devPath string) CameraIdentity {
+	parent := USBParent(devPath)
+	if parent == "" {
+		return CameraIdentity{}
+	}
+	vendor := readSysfsAttr(parent, "idVendor")
+	if vendor == "" {
+		return CameraIdentity{} // Not a USB device (e.g. platform camera)
+	}
+	return CameraIdentity{
+		PortPath:  filepath.Base(parent),
+		VendorID:  vendor,
+		ProductID: readSysfsAttr(parent, "idProduct"),
+		Serial:    readSysfsAttr(parent, "serial"),
+	}
+}
+
+// readSysfsAttr returns a trimmed sysfs attribute, or "" if it is missing.
+func readSysfsAttr(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// ArrangeBySlot orders discovered cameras so that each pinned identity lands
+// in its slot. pins is indexed by slot; "" leaves the slot unpinned.
+// Unpinned cameras fill the unpinned slots in discovery order. A slot whose
+// pinned camera is missing (or an unpinned gap before a pinned slot) gets a
+// placeholder with Available=false, so later cameras keep their positions.
+func ArrangeBySlot(cameras []Camera, pins []string) []Camera {
+	if !hasSlotPins(Settings{SlotPins: pins}) {
+		return cameras
+	}
+
+	arranged := make([]Camera, len(pins))
+	filled := make([]bool, len(pins))
+	used := make([]bool, len(cameras))
+
+	// Pinned slots first, so a pinned camera is never taken by an unpinned slot
+	for slot, key := range pins {
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+		for i, cam := range cameras {
+			if !used[i] && cam.Matches(key) {
+				arranged[slot] = cam
+				filled[slot] = true
+				used[i] = true
+				break
+			}
+		}
+		if !filled[slot] {
+			arranged[slot] = Camera{Name: strings.TrimSpace(key)} // Waiting for pinned camera
+			filled[slot] = true
+		}
+	}
+
+	// Remaining cameras fill unpinned slots, then go after the last slot
+	next := 0
+	for i, cam := range cameras {
+		if used[i] {
+			continue
+		}
+		for next < len(filled) && filled[next] {
+			next++
+		}
+		if next < len(filled) {
+			arranged[next] = cam
+			filled[next] = true
+		} else {
+			arranged = append(arranged, cam)
+		}
+	}
+
+	// Drop trailing unpinned slots that nothing landed in
+	for len(arranged) > 0 && !arranged[len(arranged)-1].Available &&
+		strings.TrimSpace(pinAt(pins, len(arranged)-1)) == "" {
+		arranged = arranged[:len(arranged)-1]
+	}
+	return arranged
+}
+
+// pinAt returns the pin for slot, or "" beyond the configured pins.
+func pinAt(pins []string, slot int) string {
+	if slot < len(pins) {
+		return pins[slot]
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package camera
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeFakeSysfs builds a sysfs tree where video0 belongs to the USB device at
+// port 1-1.3 and video2 to a platform (non-USB) device.
+func makeFakeSysfs(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	usbDev := filepath.Join(root, "devices", "platform", "usb1", "1-1", "1-1.3")
+	usbIface := filepath.Join(usbDev, "1-1.3:1.0")
+	platformDev := filepath.Join(root, "devices", "platform", "isp", "video")
+	for _, dir := range []string{usbIface, platformDev, filepath.Join(root, "class", "video4linux", "video0"),
+		filepath.Join(root, "class", "video4linux", "video2")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, value := range map[string]string{"idVendor": "046d\n", "idProduct": "0825\n", "serial": "A1B2C3\n"} {
+		if err := os.WriteFile(filepath.Join(usbDev, name), []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(usbIface, filepath.Join(root, "class", "video4linux", "video0", "device")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(platformDev, filepath.Join(root, "class", "video4linux", "video2", "device")); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestLookupIdentity_FromSysfs(t *testing.T) {
+	old := sysfsRoot
+	sysfsRoot = makeFakeSysfs(t)
+	defer func() { sysfsRoot = old }()
+
+	id := LookupIdentity("/dev/video0")
+	want := CameraIdentity{PortPath: "1-1.3", VendorID: "046d", ProductID: "0825", Serial: "A1B2C3"}
+	if id != want {
+		t.Errorf("LookupIdentity(video0) = %+v, want %+v", id, want)
+	}
+	if got := LookupIdentity("/dev/video2"); !got.IsZero() {
+		t.Errorf("platform device should have no USB identity, got %+v", got)
+	}
+	if got := LookupIdentity("/dev/video9"); !got.IsZero() {
+		t.Errorf("missing device should have no identity, got %+v", got)
+	}
+}
+
+func TestCameraIdentity_Matches(t *testing.T) {
+	id := CameraIdentity{PortPath: "1-1.3", VendorID: "046d", ProductID: "0825", Serial: "A1B2C3"}
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"1-1.3", true},
+		{"1-1.2", false},
+		{"046d:0825", true},
+		{"046D:0825", true},
+		{"046d:0826", false},
+		{"046d:0825:A1B2C3", true},
+		{"046d:0825:OTHER", false},
+		{"serial:A1B2C3", true},
+		{"serial:", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := id.Matches(tt.key); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestArrangeBySlot(t *testing.T) {
+	left := Camera{DeviceID: "video0", Available: true, Identity: CameraIdentity{PortPath: "1-1.2"}}
+	rear := Camera{DeviceID: "video2", Available: true, Identity: CameraIdentity{PortPath: "1-1.3"}}
+	other := Camera{DeviceID: "video4", Available: true, Identity: CameraIdentity{PortPath: "1-1.4"}}
+
+	// No pins: discovery order is kept
+	if got := ArrangeBySlot([]Camera{left, rear}, nil); got[0].DeviceID != "video0" || got[1].DeviceID != "video2" {
+		t.Errorf("unpinned order changed: %v", got)
+	}
+
+	// Rear pinned to slot 0 moves ahead of the others
+	got := ArrangeBySlot([]Camera{left, rear, other}, []string{"1-1.3"})
+	if len(got) != 3 || got[0].DeviceID != "video2" || got[1].DeviceID != "video0" || got[2].DeviceID != "video4" {
+		t.Errorf("pinned slot 0: got %v", got)
+	}
+
+	// Pinned camera missing: slot kept as an unavailable placeholder
+	got = ArrangeBySlot([]Camera{left, other}, []string{"", "1-1.3"})
+	if len(got) != 3 || got[0].DeviceID != "video0" || got[1].Available || got[2].DeviceID != "video4" {
+		t.Errorf("missing pinned camera: got %v", got)
+	}
+
+	// Unpinned gap before a pinned slot with nothing to fill it
+	got = ArrangeBySlot([]Camera{rear}, []string{"", "", "1-1.3"})
+	if len(got) != 3 || got[0].Available || got[1].Available || got[2].DeviceID != "video2" {
+		t.Errorf("gap before pinned slot: got %v", got)
+	}
+}
+
+func TestManager_PinnedSlotWithoutCamera(t *testing.T) {
+	s := DefaultSettings()
+	s.SlotPins = []string{"1-1.3"}
+	m := NewManagerWithSettings(s, true)
+	m.SetDiscovery(func(Settings) ([]Camera, error) {
+		return []Camera{{DeviceID: "video0", Available: true, Identity: CameraIdentity{PortPath: "1-1.2"}}}, nil
+	})
+	m.SetSourceFactory(func(cam Camera, s Settings) FrameSource {
+		return newFakeSource(makeTestImage(8, 8, color.White))
+	})
+
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start with placeholder slot: %v", err)
+	}
+	defer m.Stop()
+
+	cams := m.GetCameras()
+	if len(cams) != 2 || cams[0].Available || cams[1].DeviceID != "video0" {
+		t.Fatalf("cameras = %+v, want placeholder then video0", cams)
+	}
+	if err := m.RestartCameraByIndex(0); err == nil {
+		t.Error("restarting a placeholder slot should fail")
+	}
+	waitForFrames(t, m.GetFrameBuffer("video0"), 2)
+}
+
+func TestManager_AppliesRoles(t *testing.T) {
+	s := DefaultSettings()
+	s.SlotPins = []string{"", "1-1.4"}
+	s.Cameras = map[string]CameraOverride{
+		"1-1.2": {Role: "Left", Name: "Left cam"},
+		"1-1.4": {Role: "Trailer"},
+	}
+	m := NewManagerWithSettings(s, true)
+	m.SetDiscovery(func(Settings) ([]Camera, error) {
+		return []Camera{
+			{DeviceID: "video0", Name: "USB Camera", Available: true, Identity: CameraIdentity{PortPath: "1-1.2"}},
+			{DeviceID: "video2", Name: "USB Camera", Available: true, Identity: CameraIdentity{PortPath: "1-1.3"}},
+		}, nil
+	})
+	m.SetSourceFactory(func(cam Camera, s Settings) FrameSource {
+		return newFakeSource(makeTestImage(8, 8, color.White))
+	})
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	defer m.Stop()
+
+	cams := m.GetCameras()
+	if len(cams) != 3 {
+		t.Fatalf("cameras = %+v, want 3 slots", cams)
+	}
+	if cams[0].Role != "Left" || cams[0].Name != "Left cam" || cams[0].Label(0) != "Left" {
+		t.Errorf("slot 0 = %+v, want role Left", cams[0])
+	}
+	if cams[1].Available || cams[1].Label(1) != "Trailer" {
+		t.Errorf("slot 1 = %+v, want Trailer placeholder", cams[1])
+	}
+	if cams[2].Role != "" || cams[2].Label(2) != "Camera 2" {
+		t.Errorf("slot 2 label = %q, want Camera 2", cams[2].Label(2))
+	}
+}
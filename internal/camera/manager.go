@@ -15,6 +15,13 @@ type Manager struct {
 	settings     Settings                // Camera capture settings from config
 	running      bool
 	mutex        sync.RWMutex
+
+	// Pluggable discovery and frame sources (default: v4l2-ctl + Settings.Backend)
+	discover      func(Settings) ([]Camera, error)
+	sourceFactory SourceFactory
+
+	// Sinks attached to every worker, including those created by a later Initialize
+	sinks []FrameSink
 }
 
 // NewManagerWithSettings creates a manager with explicit settings from config
@@ -35,10 +42,49 @@ func NewManagerWithSettings(s Settings, useBuffers bool) *Manager {
 	if s.MaxCameras <= 0 {
 		s.MaxCameras = DefaultMaxCameras
 	}
+	if s.Backend == "" {
+		s.Backend = DefaultBackend
+	}
 
 	return &Manager{
-		frameBuffers: make(map[string]*FrameBuffer),
-		settings:     s,
+		frameBuffers:  make(map[string]*FrameBuffer),
+		settings:      s,
+		discover:      DiscoverCamerasWithSettings,
+		sourceFactory: NewFrameSource,
+	}
+}
+
+// SetSourceFactory changes how frame sources are built for discovered cameras.
+// Takes effect on the next Initialize.
+func (m *Manager) SetSourceFactory(factory SourceFactory) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if factory == nil {
+		factory = NewFrameSource
+	}
+	m.sourceFactory = factory
+}
+
+// SetDiscovery replaces camera discovery, e.g. to register file, network or
+// synthetic cameras that v4l2-ctl cannot see. Takes effect on the next Initialize.
+func (m *Manager) SetDiscovery(discover func(Settings) ([]Camera, error)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if discover == nil {
+		discover = DiscoverCamerasWithSettings
+	}
+	m.discover = discover
+}
+
+// AddFrameSink attaches a sink to all current and future capture workers.
+func (m *Manager) AddFrameSink(sink FrameSink) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sinks = append(m.sinks, sink)
+	for _, worker := range m.workers {
+		if worker != nil {
+			worker.AddSink(sink)
+		}
 	}
 }
 
@@ -48,7 +94,7 @@ func (m *Manager) GetSettings() Settings {
 }
 
 // Initialize discovers and initializes cameras.
-// Must not be called concurrently — the caller (initializeCamerasAsync) ensures
+// Must not be called concurrently — the caller (core.Service.initializeCameras) ensures
 // single-threaded access during startup, and handleNewCameraDevice serializes
 // via reinitLock.
 func (m *Manager) Initialize() error {
@@ -61,24 +107,53 @@ func (m *Manager) Initialize() error {
 
 	log.Println("[Manager] Discovering cameras...")
 	// Discover cameras
-	cameras, err := DiscoverCamerasWithSettings(m.settings)
+	cameras, err := m.discover(m.settings)
 	if err != nil {
 		log.Printf("[Manager] Camera discovery failed: %v", err)
 		return err
 	}
 
 	log.Printf("[Manager] Found %d cameras", len(cameras))
+
+	// Put pinned cameras in their configured slots
+	cameras = ArrangeBySlot(cameras, m.settings.SlotPins)
+	if len(cameras) > m.settings.MaxCameras {
+		cameras = cameras[:m.settings.MaxCameras]
+	}
+
 	m.cameras = cameras
 	m.workers = make([]*CaptureWorker, len(cameras))
 	m.frameBuffers = make(map[string]*FrameBuffer)
 
 	// Create capture workers for each camera
 	for i, camera := range cameras {
-		log.Printf("[Manager] Creating worker for camera %s (%s)",
-			camera.DeviceID, camera.DevicePath)
+		if !camera.Available {
+			// Placeholder for a pinned camera that is not plugged in; the
+			// placeholder is named after the pin key
+			m.cameras[i].Role = m.settings.Cameras[camera.Name].Role
+			log.Printf("[Manager] Slot %d (%s) reserved for %q (not connected)",
+				i, m.cameras[i].Label(i), camera.Name)
+			continue
+		}
+		camSettings := m.settings.ForCamera(camera)
+		if o, ok := m.settings.OverrideFor(camera); ok {
+			if o.Name != "" {
+				camera.Name = o.Name
+			}
+			camera.Role = o.Role
+			m.cameras[i] = camera
+		}
+		log.Printf("[Manager] Creating worker for %s: camera %s (%s) [%s] in slot %d: %dx%d @ %d FPS %s",
+			camera.Label(i), camera.DeviceID, camera.DevicePath, camera.Identity, i,
+			camSettings.Width, camSettings.Height, camSettings.FPS, camSettings.Format)
 
 		buffer := NewFrameBuffer()
-		worker := NewCaptureWorkerWithBuffer(camera, buffer, m.settings)
+		source := m.sourceFactory(camera, camSettings)
+		worker := NewCaptureWorkerWithSource(camera, source, buffer, camSettings)
+		worker.slot = i
+		for _, sink := range m.sinks {
+			worker.AddSink(sink)
+		}
 		m.frameBuffers[camera.DeviceID] = buffer
 		m.workers[i] = worker
 	}
@@ -102,8 +177,12 @@ func (m *Manager) Start() error {
 	// Start cameras with staggered delays to reduce USB bandwidth contention
 	// USB 2.0 bandwidth is limited (~35MB/s real-world), starting all cameras
 	// simultaneously causes buffer overruns on some cameras
+	started := 0
 	for i, worker := range m.workers {
-		if i > 0 {
+		if worker == nil {
+			continue // Placeholder slot
+		}
+		if started > 0 {
 			// Release lock during sleep so UI can call GetFrameBuffer/GetCameras
 			m.mutex.Unlock()
 			log.Printf("[Manager] Waiting 500ms before starting camera %d to reduce USB contention", i+1)
@@ -121,6 +200,7 @@ func (m *Manager) Start() error {
 			m.mutex.Unlock()
 			return err
 		}
+		started++
 		log.Printf("[Manager] Started camera %d/%d", i+1, len(m.workers))
 	}
 
@@ -191,7 +271,7 @@ func (m *Manager) GetWorker(cameraID string) *CaptureWorker {
 	defer m.mutex.RUnlock()
 
 	for i, cam := range m.cameras {
-		if cam.DeviceID == cameraID && i < len(m.workers) {
+		if cam.Available && cam.DeviceID == cameraID && i < len(m.workers) {
 			return m.workers[i]
 		}
 	}
@@ -204,7 +284,7 @@ func (m *Manager) RestartCamera(cameraID string) error {
 	m.mutex.RLock()
 	var worker *CaptureWorker
 	for i, cam := range m.cameras {
-		if cam.DeviceID == cameraID && i < len(m.workers) {
+		if cam.Available && cam.DeviceID == cameraID && i < len(m.workers) {
 			worker = m.workers[i]
 			break
 		}
@@ -33,3 +33,32 @@ func TestNewManagerWithSettings_DefaultMaxCameras(t *testing.T) {
 		t.Errorf("MaxCameras = %d, want %d", s.MaxCameras, DefaultMaxCameras)
 	}
 }
+
+func TestSettings_ForCamera(t *testing.T) {
+	s := DefaultSettings()
+	s.Cameras = map[string]CameraOverride{
+		"1-1.3":     {Width: 1280, Height: 720, FPS: 10, Format: "YUYV", Name: "Rear", Rotation: 180, Mirror: true},
+		"046d:0825": {FPS: 5},
+	}
+
+	rear := Camera{DeviceID: "video2", Identity: CameraIdentity{PortPath: "1-1.3", VendorID: "046d", ProductID: "0825"}}
+	got := s.ForCamera(rear)
+	// "046d:0825" sorts before "1-1.3", so it wins for a camera matching both
+	if got.FPS != 5 || got.Width != DefaultWidth {
+		t.Errorf("ForCamera(both keys) = %+v, want first sorted override only", got)
+	}
+
+	rear.Identity.VendorID = "1234"
+	got = s.ForCamera(rear)
+	if got.Width != 1280 || got.Height != 720 || got.FPS != 10 || got.Format != "yuyv" || got.Rotation != 180 || !got.Mirror {
+		t.Errorf("ForCamera(1-1.3) = %+v", got)
+	}
+	if o, ok := s.OverrideFor(rear); !ok || o.Name != "Rear" {
+		t.Errorf("OverrideFor(1-1.3) = %+v, %v", o, ok)
+	}
+
+	other := Camera{DeviceID: "video0", Identity: CameraIdentity{PortPath: "1-1.2"}}
+	if got := s.ForCamera(other); got.Width != DefaultWidth || got.FPS != DefaultFPS || got.Rotation != 0 || got.Mirror {
+		t.Errorf("ForCamera(unmatched) = %+v, want globals", got)
+	}
+}
@@ -0,0 +1,28 @@
+package camera
+
+import (
+	"image"
+	"time"
+)
+
+// FrameSink receives every frame a CaptureWorker publishes from its real
+// camera (test patterns are not passed on). WriteFrame is called on the
+// capture goroutine and must return quickly: sinks that do I/O hand the frame
+// to their own goroutine and drop frames rather than block.
+type FrameSink interface {
+	WriteFrame(f CapturedFrame)
+}
+
+// CapturedFrame is a published frame plus what a sink needs to store it.
+// Image and JPEG are shared with the display path and must not be modified.
+type CapturedFrame struct {
+	Camera Camera      // Camera that produced the frame (with role)
+	Slot   int         // Manager slot index of the camera
+	Time   time.Time   // Capture time
+	Image  image.Image // Decoded frame as displayed (after rotation)
+
+	// JPEG is the camera's own MJPEG frame (with Huffman tables) when no
+	// transform was applied, so sinks can store it without re-encoding.
+	// nil for raw formats and transformed frames.
+	JPEG []byte
+}
@@ -0,0 +1,126 @@
+package camera
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Snapshot image formats
+const (
+	SnapshotJPEG = "jpeg"
+	SnapshotPNG  = "png"
+
+	DefaultSnapshotQuality = 90
+)
+
+// SnapshotOptions controls where and how Manager.Snapshot writes images.
+type SnapshotOptions struct {
+	Dir         string // Output directory (created if missing)
+	Format      string // SnapshotJPEG (default) or SnapshotPNG
+	JPEGQuality int    // 1-100, default DefaultSnapshotQuality
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SafeFileName turns a camera label into a string usable in file names.
+func SafeFileName(name string) string {
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_.")
+	if name == "" {
+		return "camera"
+	}
+	return name
+}
+
+// Snapshot writes the current frame of the camera in slot index, or of every
+// camera with a frame when index < 0, to opts.Dir. Files are named
+// <role-or-label>_<YYYYMMDD-HHMMSS.mmm>.jpg|png. Returns the written paths;
+// cameras without a frame yet are skipped.
+func (m *Manager) Snapshot(index int, opts SnapshotOptions) ([]string, error) {
+	if opts.Format == "" {
+		opts.Format = SnapshotJPEG
+	}
+	if opts.Format != SnapshotJPEG && opts.Format != SnapshotPNG {
+		return nil, fmt.Errorf("unsupported snapshot format %q", opts.Format)
+	}
+	if opts.JPEGQuality <= 0 || opts.JPEGQuality > 100 {
+		opts.JPEGQuality = DefaultSnapshotQuality
+	}
+
+	type shot struct {
+		label string
+		frame image.Image
+	}
+	var shots []shot
+
+	m.mutex.RLock()
+	if index >= len(m.cameras) {
+		m.mutex.RUnlock()
+		return nil, fmt.Errorf("camera index %d out of range", index)
+	}
+	for i, cam := range m.cameras {
+		if index >= 0 && i != index {
+			continue
+		}
+		buf := m.frameBuffers[cam.DeviceID]
+		if !cam.Available || buf == nil {
+			continue
+		}
+		if frame := buf.Read(); frame != nil {
+			shots = append(shots, shot{label: cam.Label(i), frame: frame})
+		}
+	}
+	m.mutex.RUnlock()
+
+	if len(shots) == 0 {
+		return nil, fmt.Errorf("no camera frame available for snapshot")
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create snapshot dir: %w", err)
+	}
+
+	stamp := time.Now().Format("20060102-150405.000")
+	ext := ".jpg"
+	if opts.Format == SnapshotPNG {
+		ext = ".png"
+	}
+
+	paths := make([]string, 0, len(shots))
+	for _, s := range shots {
+		path := filepath.Join(opts.Dir, fmt.Sprintf("%s_%s%s", SafeFileName(s.label), stamp, ext))
+		if err := writeSnapshot(path, s.frame, opts); err != nil {
+			return paths, err
+		}
+		log.Printf("[Manager] Snapshot of %s saved to %s", s.label, path)
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// writeSnapshot encodes img to path, removing partial files on error.
+func writeSnapshot(path string, img image.Image, opts SnapshotOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	if opts.Format == SnapshotPNG {
+		err = png.Encode(f, img)
+	} else {
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: opts.JPEGQuality})
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("encode snapshot %s: %w", path, err)
+	}
+	return nil
+}
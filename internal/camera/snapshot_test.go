@@ -0,0 +1,70 @@
+package camera
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestManager_Snapshot(t *testing.T) {
+	s := DefaultSettings()
+	s.Cameras = map[string]CameraOverride{"synthetic0": {Role: "Rear Cam"}}
+	m := NewManagerWithSettings(s, true)
+	m.SetDiscovery(func(Settings) ([]Camera, error) {
+		return []Camera{
+			{DeviceID: "synthetic0", Available: true},
+			{DeviceID: "synthetic1", Available: true},
+		}, nil
+	})
+	m.SetSourceFactory(func(cam Camera, s Settings) FrameSource {
+		return newFakeSource(makeTestImage(8, 8, color.White))
+	})
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+	waitForFrames(t, m.GetFrameBuffer("synthetic0"), 1)
+	waitForFrames(t, m.GetFrameBuffer("synthetic1"), 1)
+
+	dir := filepath.Join(t.TempDir(), "snaps")
+	paths, err := m.Snapshot(-1, SnapshotOptions{Dir: dir, Format: SnapshotPNG})
+	if err != nil {
+		t.Fatalf("Snapshot(all): %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("Snapshot(all) wrote %d files, want 2", len(paths))
+	}
+	for i, prefix := range []string{"Rear_Cam_", "Camera_1_"} {
+		name := filepath.Base(paths[i])
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".png") {
+			t.Errorf("snapshot %d named %q, want %s<time>.png", i, name, prefix)
+		}
+		f, err := os.Open(paths[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil || img.Bounds().Dx() != 8 {
+			t.Errorf("snapshot %d not a valid 8px PNG: %v", i, err)
+		}
+	}
+
+	paths, err = m.Snapshot(1, SnapshotOptions{Dir: dir})
+	if err != nil || len(paths) != 1 || !strings.HasSuffix(paths[0], ".jpg") {
+		t.Errorf("Snapshot(1) = %v, %v; want one .jpg", paths, err)
+	}
+
+	if _, err := m.Snapshot(5, SnapshotOptions{Dir: dir}); err == nil {
+		t.Error("Snapshot of missing slot should fail")
+	}
+	if _, err := m.Snapshot(0, SnapshotOptions{Dir: dir, Format: "bmp"}); err == nil {
+		t.Error("unsupported format should fail")
+	}
+}
@@ -0,0 +1,210 @@
+package camera
+
+import (
+	"image"
+	"strconv"
+	"time"
+)
+
+// =============================================================================
+// Frame Sources
+// =============================================================================
+// A FrameSource is anything that can produce camera frames: the FFmpeg/V4L2
+// pipeline, a synthetic test pattern, and later file or network inputs.
+// CaptureWorker owns restart, recovery and FPS skipping; sources only know
+// how to open a device, hand over the next frame and release the device.
+// =============================================================================
+
+// PixelFormat identifies how Frame.Data is encoded.
+type PixelFormat int
+
+const (
+	PixelFormatNone  PixelFormat = iota // No raw data, Frame.Image is set
+	PixelFormatMJPEG                    // Data is a complete JPEG image
+	PixelFormatYUYV                     // Data is packed YUYV 4:2:2, Width x Height
+	PixelFormatNV12                     // Data is NV12 (Y plane + interleaved CbCr 4:2:0)
+)
+
+// String returns a short name for logging.
+func (p PixelFormat) String() string {
+	switch p {
+	case PixelFormatMJPEG:
+		return "mjpeg"
+	case PixelFormatYUYV:
+		return "yuyv"
+	case PixelFormatNV12:
+		return "nv12"
+	default:
+		return "image"
+	}
+}
+
+// Frame is a single frame handed from a FrameSource to the CaptureWorker.
+// Sources that deliver encoded data set Data/Format and leave Image nil, so
+// the worker can drop frames it would skip anyway without decoding them.
+// Data is owned by the receiver; sources must not reuse it after returning.
+// Width and Height are required for raw formats, which carry no header.
+type Frame struct {
+	Data   []byte
+	Format PixelFormat
+	Width  int
+	Height int
+	Image  image.Image
+
+	// Transformed is set when the source already applied the camera's
+	// Settings.Transform (FFmpeg filter or sensor flip).
+	Transformed bool
+}
+
+// FrameSource produces frames for one camera.
+//
+// Open is called before streaming and again after every failure, so sources
+// must be reopenable. NextFrame blocks until a frame is available and returns
+// io.EOF once the stream has ended for good. Close must be safe to call from
+// another goroutine while NextFrame is blocked, and must unblock it.
+type FrameSource interface {
+	Open() error
+	NextFrame() (Frame, error)
+	Close() error
+	Capabilities() CameraCapabilities
+}
+
+// SourceFactory builds the FrameSource for a discovered camera.
+type SourceFactory func(cam Camera, s Settings) FrameSource
+
+// NewFrameSource is the default SourceFactory. It picks the capture
+// backend from Settings.Backend, falling back to FFmpeg.
+func NewFrameSource(cam Camera, s Settings) FrameSource {
+	switch s.Backend {
+	case "v4l2":
+		return NewV4L2Source(cam, s)
+	default:
+		return NewFFmpegSource(cam, s)
+	}
+}
+
+// =============================================================================
+// Test Pattern Source
+// =============================================================================
+
+// TestPatternSource generates synthetic frames. The worker falls back to it
+// while the real camera is unavailable, and it doubles as a source for
+// development without hardware.
+type TestPatternSource struct {
+	camera   Camera
+	width    int
+	height   int
+	fps      int
+	frameNum int
+}
+
+// NewTestPatternSource creates a synthetic source at the configured resolution.
+func NewTestPatternSource(cam Camera, s Settings) *TestPatternSource {
+	return &TestPatternSource{
+		camera: cam,
+		width:  s.Width,
+		height: s.Height,
+		fps:    s.FPS,
+	}
+}
+
+// Open is a no-op; test patterns are always available.
+func (ts *TestPatternSource) Open() error {
+	return nil
+}
+
+// NextFrame renders the next test frame. It does not pace itself;
+// the caller decides how often to ask.
+func (ts *TestPatternSource) NextFrame() (Frame, error) {
+	img := generateTestFrame(ts.camera.DeviceID, ts.width, ts.height, ts.frameNum)
+	ts.frameNum++
+	return Frame{Image: img}, nil
+}
+
+// Close is a no-op.
+func (ts *TestPatternSource) Close() error {
+	return nil
+}
+
+// Capabilities reports the configured resolution and FPS.
+func (ts *TestPatternSource) Capabilities() CameraCapabilities {
+	return CameraCapabilities{
+		MaxWidth:  ts.width,
+		MaxHeight: ts.height,
+		MaxFPS:    ts.fps,
+	}
+}
+
+// generateTestFrame creates a test frame for development (fallback)
+func generateTestFrame(deviceID string, width, height, frameNum int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	stride := img.Stride
+
+	// Create realistic patterns that simulate camera input
+	cameraIDNum := 0
+	if len(deviceID) > 5 { // "videoX" format
+		if num, err := strconv.Atoi(deviceID[5:]); err == nil {
+			cameraIDNum = num
+		}
+	}
+
+	// Cache time.Now() once per frame instead of per-pixel
+	now := time.Now()
+	sec := now.Second()
+	timestamp := int(now.Unix())
+
+	for y := 0; y < height; y++ {
+		rowOff := y * stride
+		for x := 0; x < width; x++ {
+			var r, g, b uint8
+
+			switch cameraIDNum {
+			case 0: // Camera 0 - Blue sky scene
+				gradient := float64(y) / float64(height)
+				r = uint8(135 * (1 - gradient))
+				g = uint8(206 * (1 - gradient))
+				b = uint8(250 * (1 - gradient))
+
+				if x%80 < 20 && y%60 < 15 {
+					white := uint8(200 + int(15*sec%55))
+					r, g, b = white, white, white
+				}
+
+			case 1: // Camera 1 - Green landscape
+				r = uint8(50 + 20*sec%30)
+				g = uint8(120 + 30*sec%40)
+				b = uint8(50)
+
+				if x%100 < 10 && y%100 < 10 {
+					r, g, b = 255, 100, 100
+				}
+
+			case 2: // Camera 2 - Urban scene
+				gray := uint8(128 + 50*sec%80)
+				r, g, b = gray, gray, gray
+
+				if (x%40 < 5 || y%30 < 3) && x+y > 200 {
+					r, g, b = 180, 180, 200
+				}
+
+			default: // Default multi-color pattern
+				r = uint8((x + frameNum) % 256)
+				g = uint8((y + frameNum/2) % 256)
+				b = uint8((x + y + frameNum/3) % 256)
+			}
+
+			// Add time overlay to show "live" nature
+			if (timestamp%100) < 50 && x < 50 && y < 20 {
+				r, g, b = 255, 255, 255
+			}
+
+			off := rowOff + x*4
+			img.Pix[off+0] = r
+			img.Pix[off+1] = g
+			img.Pix[off+2] = b
+			img.Pix[off+3] = 255
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,369 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSource delivers a fixed image (or JPEG, if set) at a steady rate until closed.
+type fakeSource struct {
+	mu       sync.Mutex
+	img      image.Image
+	jpeg     []byte
+	interval time.Duration
+	closeCh  chan struct{}
+	opens    int
+}
+
+func newFakeSource(img image.Image) *fakeSource {
+	return &fakeSource{img: img, interval: 5 * time.Millisecond}
+}
+
+func (f *fakeSource) Open() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opens++
+	f.closeCh = make(chan struct{})
+	return nil
+}
+
+func (f *fakeSource) NextFrame() (Frame, error) {
+	f.mu.Lock()
+	closeCh := f.closeCh
+	f.mu.Unlock()
+	if closeCh == nil {
+		return Frame{}, io.EOF
+	}
+	select {
+	case <-closeCh:
+		return Frame{}, io.EOF
+	case <-time.After(f.interval):
+		if f.jpeg != nil {
+			return Frame{Data: f.jpeg, Format: PixelFormatMJPEG}, nil
+		}
+		return Frame{Image: f.img}, nil
+	}
+}
+
+func (f *fakeSource) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closeCh != nil {
+		close(f.closeCh)
+		f.closeCh = nil
+	}
+	return nil
+}
+
+func (f *fakeSource) Capabilities() CameraCapabilities {
+	return CameraCapabilities{MaxWidth: 8, MaxHeight: 8, MaxFPS: 30}
+}
+
+func encodeTestJPEG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, makeTestImage(16, 16, c), nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func waitForFrames(t *testing.T, fb *FrameBuffer, want uint64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for fb.GetFrameCount() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d frames (got %d)", want, fb.GetFrameCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMJPEGFramer_SplitsConcatenatedStream(t *testing.T) {
+	first := encodeTestJPEG(t, color.White)
+	second := encodeTestJPEG(t, color.Black)
+
+	var stream bytes.Buffer
+	stream.Write([]byte{0x00, 0x12, 0x34}) // Leading garbage before first SOI
+	stream.Write(first)
+	stream.Write(second)
+
+	framer := newMJPEGFramer(25)
+	got1, err := framer.readFrame(&stream)
+	if err != nil {
+		t.Fatalf("first frame: %v", err)
+	}
+	if !bytes.Equal(got1.Data, first) {
+		t.Errorf("first frame: got %d bytes, want %d", len(got1.Data), len(first))
+	}
+
+	got2, err := framer.readFrame(&stream)
+	if err != nil {
+		t.Fatalf("second frame: %v", err)
+	}
+	if !bytes.Equal(got2.Data, second) {
+		t.Errorf("second frame: got %d bytes, want %d", len(got2.Data), len(second))
+	}
+
+	if _, err := framer.readFrame(&stream); err != io.EOF {
+		t.Errorf("after last frame: err = %v, want io.EOF", err)
+	}
+}
+
+func TestCaptureWorker_StreamsFromCustomSource(t *testing.T) {
+	cam := Camera{DeviceID: "fake0", Available: true}
+	src := newFakeSource(makeTestImage(8, 8, color.White))
+	fb := NewFrameBuffer()
+
+	cw := NewCaptureWorkerWithSource(cam, src, fb, DefaultSettings())
+	if w, h := cw.GetResolution(); w != 8 || h != 8 {
+		t.Errorf("resolution = %dx%d, want 8x8 from source capabilities", w, h)
+	}
+	if cw.GetMaxFPS() != 30 {
+		t.Errorf("max FPS = %d, want 30 from source capabilities", cw.GetMaxFPS())
+	}
+
+	if err := cw.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitForFrames(t, fb, 3)
+	cw.Stop()
+
+	if frame := fb.Read(); frame == nil || frame.Bounds().Dx() != 8 {
+		t.Errorf("unexpected frame in buffer: %v", frame)
+	}
+}
+
+func TestCaptureWorker_Crop(t *testing.T) {
+	cam := Camera{DeviceID: "fake0", Available: true}
+	fb := NewFrameBuffer()
+	s := DefaultSettings()
+	s.Cameras = map[string]CameraOverride{"fake0": {Crop: Crop{X: 0, Y: 0.5, W: 0.5, H: 0.5}}}
+
+	cw := NewCaptureWorkerWithSource(cam, newFakeSource(makeTestImage(8, 8, color.White)), fb, s)
+	if err := cw.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer cw.Stop()
+	waitForFrames(t, fb, 2)
+	if b := fb.Read().Bounds(); b.Dx() != 4 || b.Dy() != 4 {
+		t.Errorf("cropped frame = %v, want 4x4", b)
+	}
+
+	cw.SetCrop(Crop{})
+	n := fb.GetFrameCount()
+	waitForFrames(t, fb, n+2)
+	if b := fb.Read().Bounds(); b.Dx() != 8 {
+		t.Errorf("frame after clearing crop = %v, want 8x8", b)
+	}
+}
+
+func TestManager_InitializeWithCustomSource(t *testing.T) {
+	m := NewManagerWithSettings(DefaultSettings(), true)
+	m.SetDiscovery(func(Settings) ([]Camera, error) {
+		return []Camera{{DeviceID: "synthetic0", Available: true}}, nil
+	})
+	m.SetSourceFactory(func(cam Camera, s Settings) FrameSource {
+		return newFakeSource(makeTestImage(8, 8, color.Black))
+	})
+
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	fb := m.GetFrameBuffer("synthetic0")
+	if fb == nil {
+		t.Fatal("no frame buffer for synthetic camera")
+	}
+	waitForFrames(t, fb, 2)
+}
+
+// chanSink collects published frames.
+type chanSink chan CapturedFrame
+
+func (c chanSink) WriteFrame(f CapturedFrame) {
+	select {
+	case c <- f:
+	default:
+	}
+}
+
+func TestManager_FrameSinkGetsCameraJPEG(t *testing.T) {
+	s := DefaultSettings()
+	s.Cameras = map[string]CameraOverride{"synthetic0": {Role: "Rear"}}
+	m := NewManagerWithSettings(s, true)
+	m.SetDiscovery(func(Settings) ([]Camera, error) {
+		return []Camera{{DeviceID: "synthetic0", Available: true}}, nil
+	})
+	jpg := encodeTestJPEG(t, color.White)
+	m.SetSourceFactory(func(cam Camera, s Settings) FrameSource {
+		src := newFakeSource(nil)
+		src.jpeg = jpg
+		return src
+	})
+	sink := make(chanSink, 4)
+	m.AddFrameSink(sink) // Before Initialize: attached to the new workers
+
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer m.Stop()
+
+	select {
+	case f := <-sink:
+		if f.Camera.Role != "Rear" || f.Slot != 0 || f.Time.IsZero() {
+			t.Errorf("frame metadata = %+v/%d/%v", f.Camera, f.Slot, f.Time)
+		}
+		if !bytes.Equal(f.JPEG, jpg) || f.Image == nil {
+			t.Error("sink should get the camera JPEG untouched plus the decoded image")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no frame delivered to sink")
+	}
+}
+
+// stripDHT removes every DHT segment from a baseline JPEG, mimicking UVC MJPEG.
+func stripDHT(t *testing.T, data []byte) []byte {
+	t.Helper()
+	out := append([]byte(nil), data[:2]...)
+	i := 2
+	for i+4 <= len(data) {
+		marker := data[i+1]
+		length := int(data[i+2])<<8 | int(data[i+3])
+		if marker == 0xDA {
+			return append(out, data[i:]...)
+		}
+		if marker != 0xC4 {
+			out = append(out, data[i:i+2+length]...)
+		}
+		i += 2 + length
+	}
+	t.Fatal("no SOS marker in test JPEG")
+	return nil
+}
+
+func TestWithHuffmanTables_RestoresStrippedFrame(t *testing.T) {
+	full := encodeTestJPEG(t, color.RGBA{200, 50, 50, 255})
+	stripped := stripDHT(t, full)
+
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err == nil {
+		t.Fatal("expected image/jpeg to reject a frame without DHT")
+	}
+
+	fixed := withHuffmanTables(stripped)
+	if _, err := jpeg.Decode(bytes.NewReader(fixed)); err != nil {
+		t.Fatalf("decode after DHT injection: %v", err)
+	}
+
+	// Frames that already carry tables are returned as-is (no copy)
+	if got := withHuffmanTables(full); &got[0] != &full[0] {
+		t.Error("frame with DHT was copied")
+	}
+}
+
+func TestFFmpegSource_MJPEGPassthroughFirst(t *testing.T) {
+	s := DefaultSettings()
+	s.Format = "mjpeg"
+	fs := NewFFmpegSource(Camera{DeviceID: "video0", DevicePath: "/dev/video0"}, s).(*FFmpegSource)
+
+	candidates := fs.buildCandidates()
+	if len(candidates) != 4 {
+		t.Fatalf("got %d candidates, want 4 (copy, re-encode, yuyv, auto)", len(candidates))
+	}
+	joined := func(c ffmpegCandidate) string { return strings.Join(c.args, " ") }
+	if !strings.Contains(joined(candidates[0]), "-input_format mjpeg") ||
+		!strings.Contains(joined(candidates[0]), "-c:v copy") {
+		t.Errorf("first candidate should copy MJPEG: %v", candidates[0])
+	}
+	if !strings.Contains(joined(candidates[1]), "-vcodec mjpeg -q:v 5") {
+		t.Errorf("second candidate should re-encode MJPEG: %v", candidates[1])
+	}
+	if !strings.Contains(joined(candidates[2]), "-input_format yuyv422") ||
+		candidates[2].output != PixelFormatYUYV {
+		t.Errorf("third candidate should be raw YUYV: %v", candidates[2])
+	}
+	if candidates[3].output != PixelFormatNV12 {
+		t.Errorf("auto candidate should output NV12, got %v", candidates[3].output)
+	}
+}
+
+func TestFFmpegSource_TransformFilter(t *testing.T) {
+	s := DefaultSettings()
+	s.Format = "yuyv"
+	s.Rotation, s.Mirror = 90, true
+	fs := NewFFmpegSource(Camera{DeviceID: "video0", DevicePath: "/dev/video0"}, s).(*FFmpegSource)
+
+	candidates := fs.buildCandidates()
+	if len(candidates) != 4 {
+		t.Fatalf("got %d candidates, want 4 (yuyv, re-encode, copy, auto)", len(candidates))
+	}
+	joined := func(c ffmpegCandidate) string { return strings.Join(c.args, " ") }
+	// Quarter turn: raw output is forced to the rotated size
+	if got := joined(candidates[0]); !strings.Contains(got, "-vf hflip,transpose=clock -f rawvideo") ||
+		!strings.Contains(got, "-s 480x640") || !candidates[0].filtered {
+		t.Errorf("yuyv candidate should filter and swap size: %v", candidates[0])
+	}
+	if !strings.Contains(joined(candidates[1]), "-vf hflip,transpose=clock") || !candidates[1].filtered {
+		t.Errorf("re-encode should filter before passthrough: %v", candidates[1])
+	}
+	if strings.Contains(joined(candidates[2]), "-vf") || candidates[2].filtered ||
+		!strings.Contains(joined(candidates[2]), "-c:v copy") {
+		t.Errorf("passthrough cannot filter: %v", candidates[2])
+	}
+	if !candidates[3].filtered {
+		t.Errorf("auto candidate should filter: %v", candidates[3])
+	}
+
+	// A plain passthrough is untouched without a transform
+	fs = NewFFmpegSource(Camera{DeviceID: "video0", DevicePath: "/dev/video0"}, DefaultSettings()).(*FFmpegSource)
+	for _, c := range fs.buildCandidates() {
+		if strings.Contains(joined(c), "-vf") || c.filtered {
+			t.Errorf("unexpected filter without transform: %v", c)
+		}
+	}
+}
+
+func TestRawFramer_FixedSizeFrames(t *testing.T) {
+	// Two 4x2 YUYV frames back to back, then a truncated one
+	frameSize := rawFrameSize(PixelFormatYUYV, 4, 2)
+	stream := bytes.NewReader(make([]byte, frameSize*2+3))
+
+	fr := newFramer(PixelFormatYUYV, 4, 2, 25)
+	for i := 0; i < 2; i++ {
+		f, err := fr.readFrame(stream)
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if len(f.Data) != frameSize || f.Width != 4 || f.Height != 2 || f.Format != PixelFormatYUYV {
+			t.Fatalf("frame %d: %d bytes %dx%d %v", i, len(f.Data), f.Width, f.Height, f.Format)
+		}
+	}
+	if _, err := fr.readFrame(stream); err != io.EOF {
+		t.Errorf("truncated frame: err = %v, want io.EOF", err)
+	}
+}
+
+func TestNV12ToYCbCr(t *testing.T) {
+	// 2x2 NV12: four Y samples, then one Cb/Cr pair
+	img, err := nv12ToYCbCr([]byte{10, 20, 30, 40, 90, 160}, 2, 2)
+	if err != nil {
+		t.Fatalf("nv12ToYCbCr: %v", err)
+	}
+	if got := img.YCbCrAt(1, 1); got.Y != 40 || got.Cb != 90 || got.Cr != 160 {
+		t.Errorf("pixel (1,1) = %+v, want Y=40 Cb=90 Cr=160", got)
+	}
+}
@@ -0,0 +1,136 @@
+package camera
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+)
+
+// Transform describes how a camera's frames are turned for display: Mirror
+// swaps left and right, Flip swaps top and bottom, then the image is rotated
+// clockwise by Rotation degrees (0/90/180/270). Side cameras usually need
+// Mirror; cameras mounted upside down need Rotation 180.
+type Transform struct {
+	Rotation int
+	Mirror   bool
+	Flip     bool
+}
+
+// IsIdentity reports whether t leaves frames unchanged.
+func (t Transform) IsIdentity() bool {
+	return t.canonical() == Transform{}
+}
+
+// canonical returns the equivalent transform with at most one of Mirror and
+// Flip set, and a rotation below 180 when one is. Mirror+Flip is a half
+// turn, so the 8 possible results are the identity, the three rotations,
+// Mirror, Flip, and each of those two followed by a quarter turn.
+func (t Transform) canonical() Transform {
+	r := ((t.Rotation % 360) + 360) % 360
+	if r%90 != 0 {
+		r = 0 // Only quarter turns are supported
+	}
+	if t.Mirror && t.Flip {
+		t.Mirror, t.Flip = false, false
+		r = (r + 180) % 360
+	}
+	if r >= 180 && (t.Mirror || t.Flip) {
+		// Mirror then a half turn equals Flip, and vice versa
+		t.Mirror, t.Flip = !t.Mirror, !t.Flip
+		r -= 180
+	}
+	t.Rotation = r
+	return t
+}
+
+// swapsSize reports whether t turns a WxH frame into an HxW one.
+func (t Transform) swapsSize() bool {
+	r := t.canonical().Rotation
+	return r == 90 || r == 270
+}
+
+// sensorFlips returns the horizontal and vertical sensor flips that apply t,
+// or ok=false when t needs a quarter turn, which sensors cannot do.
+func (t Transform) sensorFlips() (hflip, vflip, ok bool) {
+	c := t.canonical()
+	switch c.Rotation {
+	case 0:
+		return c.Mirror, c.Flip, true
+	case 180:
+		return true, true, true
+	}
+	return false, false, false
+}
+
+// ffmpegFilter returns the FFmpeg -vf filter chain that applies t, or "" for
+// the identity.
+func (t Transform) ffmpegFilter() string {
+	c := t.canonical()
+	var filters []string
+	if c.Mirror {
+		filters = append(filters, "hflip")
+	}
+	if c.Flip {
+		filters = append(filters, "vflip")
+	}
+	switch c.Rotation {
+	case 90:
+		filters = append(filters, "transpose=clock")
+	case 180:
+		filters = append(filters, "hflip", "vflip")
+	case 270:
+		filters = append(filters, "transpose=cclock")
+	}
+	return strings.Join(filters, ",")
+}
+
+// transformImage applies t to img. The identity returns img unchanged;
+// anything else returns a new *image.RGBA.
+func transformImage(img image.Image, t Transform) image.Image {
+	t = t.canonical()
+	if t == (Transform{}) {
+		return img
+	}
+
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src) // Fast path for YCbCr
+	}
+	w, h := b.Dx(), b.Dy()
+
+	var dst *image.RGBA
+	if t.swapsSize() {
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	} else {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+
+	for y := 0; y < h; y++ {
+		srcRow := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		sy := y
+		if t.Flip {
+			sy = h - 1 - y
+		}
+		for x := 0; x < w; x++ {
+			sx := x
+			if t.Mirror {
+				sx = w - 1 - x
+			}
+			var dx, dy int
+			switch t.Rotation {
+			case 0:
+				dx, dy = sx, sy
+			case 90:
+				dx, dy = h-1-sy, sx
+			case 180:
+				dx, dy = w-1-sx, h-1-sy
+			case 270:
+				dx, dy = sy, w-1-sx
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], srcRow[x*4:x*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+package camera
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTransformImage(t *testing.T) {
+	// 3x2 image with a distinct color in two corners
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	src.Set(0, 0, red)
+	src.Set(2, 1, green)
+
+	tests := []struct {
+		t       Transform
+		w, h    int
+		redAt   image.Point
+		greenAt image.Point
+	}{
+		{Transform{Rotation: 90}, 2, 3, image.Pt(1, 0), image.Pt(0, 2)},
+		{Transform{Rotation: 180}, 3, 2, image.Pt(2, 1), image.Pt(0, 0)},
+		{Transform{Rotation: 270}, 2, 3, image.Pt(0, 2), image.Pt(1, 0)},
+		{Transform{Rotation: -90}, 2, 3, image.Pt(0, 2), image.Pt(1, 0)},
+		{Transform{Mirror: true}, 3, 2, image.Pt(2, 0), image.Pt(0, 1)},
+		{Transform{Flip: true}, 3, 2, image.Pt(0, 1), image.Pt(2, 0)},
+		{Transform{Mirror: true, Flip: true}, 3, 2, image.Pt(2, 1), image.Pt(0, 0)},
+		{Transform{Rotation: 90, Mirror: true}, 2, 3, image.Pt(1, 2), image.Pt(0, 0)},
+		{Transform{Rotation: 180, Mirror: true}, 3, 2, image.Pt(0, 1), image.Pt(2, 0)},
+	}
+	for _, tt := range tests {
+		got := transformImage(src, tt.t)
+		if b := got.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("%+v: size %v, want %dx%d", tt.t, b, tt.w, tt.h)
+			continue
+		}
+		if c := got.At(tt.redAt.X, tt.redAt.Y); c != red {
+			t.Errorf("%+v: red corner at %v is %v", tt.t, tt.redAt, c)
+		}
+		if c := got.At(tt.greenAt.X, tt.greenAt.Y); c != green {
+			t.Errorf("%+v: green corner at %v is %v", tt.t, tt.greenAt, c)
+		}
+	}
+
+	if got := transformImage(src, Transform{}); got != image.Image(src) {
+		t.Error("identity transform should return the source image")
+	}
+	if got := transformImage(src, Transform{Rotation: 360}); got != image.Image(src) {
+		t.Error("full turn should return the source image")
+	}
+}
+
+func TestTransform_FFmpegFilter(t *testing.T) {
+	tests := []struct {
+		t    Transform
+		want string
+	}{
+		{Transform{}, ""},
+		{Transform{Mirror: true}, "hflip"},
+		{Transform{Rotation: 90}, "transpose=clock"},
+		{Transform{Rotation: 270}, "transpose=cclock"},
+		{Transform{Mirror: true, Flip: true}, "hflip,vflip"},
+		{Transform{Rotation: 270, Flip: true}, "hflip,transpose=clock"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.ffmpegFilter(); got != tt.want {
+			t.Errorf("%+v.ffmpegFilter() = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
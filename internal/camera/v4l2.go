@@ -0,0 +1,482 @@
+package camera
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// =============================================================================
+// Native V4L2 Frame Source
+// =============================================================================
+// Captures straight from /dev/videoX with V4L2 ioctls and mmap'd buffers,
+// without an FFmpeg child process or a JPEG re-encode:
+//   1. VIDIOC_S_FMT   - negotiate resolution + MJPEG, YUYV or NV12
+//   2. VIDIOC_S_PARM  - request frame rate (best effort)
+//   3. VIDIOC_REQBUFS - allocate driver buffers, QUERYBUF + mmap each one
+//   4. VIDIOC_QBUF    - hand all buffers to the driver, then STREAMON
+//   5. VIDIOC_DQBUF   - per frame: take a filled buffer, copy it out, QBUF it back
+//
+// A mirror/flip/half-turn transform is handed to the sensor with the HFLIP
+// and VFLIP controls when the driver has them (VIDIOC_S_CTRL), so frames
+// arrive already turned. Otherwise the capture worker transforms them.
+//
+// The device is opened non-blocking and polled, so Close never races a
+// blocked ioctl. All device access goes through v4l2Device so the ioctl
+// sequence can be tested against a fake device.
+// =============================================================================
+
+// Pixel formats (fourcc)
+const (
+	v4l2PixFmtMJPEG = uint32('M') | uint32('J')<<8 | uint32('P')<<16 | uint32('G')<<24
+	v4l2PixFmtYUYV  = uint32('Y') | uint32('U')<<8 | uint32('Y')<<16 | uint32('V')<<24
+	v4l2PixFmtNV12  = uint32('N') | uint32('V')<<8 | uint32('1')<<16 | uint32('2')<<24
+)
+
+// v4l2PixFmts maps the formats V4L2Source can deliver to their fourcc.
+var v4l2PixFmts = map[PixelFormat]uint32{
+	PixelFormatMJPEG: v4l2PixFmtMJPEG,
+	PixelFormatYUYV:  v4l2PixFmtYUYV,
+	PixelFormatNV12:  v4l2PixFmtNV12,
+}
+
+// Enum values from videodev2.h
+const (
+	v4l2BufTypeVideoCapture = 1
+	v4l2MemoryMMAP          = 1
+	v4l2FieldNone           = 1
+	v4l2CapVideoCapture     = 0x00000001
+	v4l2CapStreaming        = 0x04000000
+	v4l2CIDHFlip            = 0x00980914 // V4L2_CID_BASE + 20
+	v4l2CIDVFlip            = 0x00980915 // V4L2_CID_BASE + 21
+
+	v4l2BufferCount = 4 // Driver buffers; enough to ride out a slow consumer
+)
+
+// v4l2Capability mirrors struct v4l2_capability.
+type v4l2Capability struct {
+	Driver       [16]uint8
+	Card         [32]uint8
+	BusInfo      [32]uint8
+	Version      uint32
+	Capabilities uint32
+	DeviceCaps   uint32
+	Reserved     [3]uint32
+}
+
+// v4l2PixFormat mirrors struct v4l2_pix_format.
+type v4l2PixFormat struct {
+	Width        uint32
+	Height       uint32
+	PixelFormat  uint32
+	Field        uint32
+	BytesPerLine uint32
+	SizeImage    uint32
+	Colorspace   uint32
+	Priv         uint32
+	Flags        uint32
+	YCbCrEnc     uint32
+	Quantization uint32
+	XferFunc     uint32
+}
+
+// v4l2Format mirrors struct v4l2_format. The kernel union is 200 bytes and
+// pointer-aligned (it contains struct v4l2_window), hence the padding after Type.
+type v4l2Format struct {
+	Type uint32
+	_    [unsafe.Sizeof(uintptr(0)) - 4]byte
+	Pix  v4l2PixFormat
+	_    [200 - unsafe.Sizeof(v4l2PixFormat{})]byte
+}
+
+// v4l2RequestBuffers mirrors struct v4l2_requestbuffers.
+type v4l2RequestBuffers struct {
+	Count        uint32
+	Type         uint32
+	Memory       uint32
+	Capabilities uint32
+	Flags        uint8
+	Reserved     [3]uint8
+}
+
+// v4l2Timeval mirrors struct timeval (two C longs).
+type v4l2Timeval struct {
+	Sec  int
+	Usec int
+}
+
+// v4l2Timecode mirrors struct v4l2_timecode.
+type v4l2Timecode struct {
+	Type     uint32
+	Flags    uint32
+	Frames   uint8
+	Seconds  uint8
+	Minutes  uint8
+	Hours    uint8
+	UserBits [4]uint8
+}
+
+// v4l2Buffer mirrors struct v4l2_buffer. The memory union is an unsigned long;
+// for MMAP buffers its low 32 bits hold the offset (little-endian).
+type v4l2Buffer struct {
+	Index     uint32
+	Type      uint32
+	BytesUsed uint32
+	Flags     uint32
+	Field     uint32
+	Timestamp v4l2Timeval
+	Timecode  v4l2Timecode
+	Sequence  uint32
+	Memory    uint32
+	Offset    uintptr
+	Length    uint32
+	Reserved2 uint32
+	RequestFD int32
+}
+
+// v4l2Fract mirrors struct v4l2_fract.
+type v4l2Fract struct {
+	Numerator   uint32
+	Denominator uint32
+}
+
+// v4l2CaptureParm mirrors struct v4l2_captureparm.
+type v4l2CaptureParm struct {
+	Capability   uint32
+	CaptureMode  uint32
+	TimePerFrame v4l2Fract
+	ExtendedMode uint32
+	ReadBuffers  uint32
+	Reserved     [4]uint32
+}
+
+// v4l2StreamParm mirrors struct v4l2_streamparm (200-byte union, no pointers).
+type v4l2StreamParm struct {
+	Type    uint32
+	Capture v4l2CaptureParm
+	_       [200 - unsafe.Sizeof(v4l2CaptureParm{})]byte
+}
+
+// v4l2Control mirrors struct v4l2_control.
+type v4l2Control struct {
+	ID    uint32
+	Value int32
+}
+
+// ioctl request encoding (_IOC from asm-generic/ioctl.h)
+const (
+	iocWrite = 1
+	iocRead  = 2
+)
+
+func v4l2IOC(dir, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | uintptr('V')<<8 | nr
+}
+
+var (
+	vidiocQueryCap  = v4l2IOC(iocRead, 0, unsafe.Sizeof(v4l2Capability{}))
+	vidiocSFmt      = v4l2IOC(iocRead|iocWrite, 5, unsafe.Sizeof(v4l2Format{}))
+	vidiocReqBufs   = v4l2IOC(iocRead|iocWrite, 8, unsafe.Sizeof(v4l2RequestBuffers{}))
+	vidiocQueryBuf  = v4l2IOC(iocRead|iocWrite, 9, unsafe.Sizeof(v4l2Buffer{}))
+	vidiocQBuf      = v4l2IOC(iocRead|iocWrite, 15, unsafe.Sizeof(v4l2Buffer{}))
+	vidiocDQBuf     = v4l2IOC(iocRead|iocWrite, 17, unsafe.Sizeof(v4l2Buffer{}))
+	vidiocStreamOn  = v4l2IOC(iocWrite, 18, unsafe.Sizeof(int32(0)))
+	vidiocStreamOff = v4l2IOC(iocWrite, 19, unsafe.Sizeof(int32(0)))
+	vidiocSParm     = v4l2IOC(iocRead|iocWrite, 22, unsafe.Sizeof(v4l2StreamParm{}))
+	vidiocSCtrl     = v4l2IOC(iocRead|iocWrite, 28, unsafe.Sizeof(v4l2Control{}))
+)
+
+// v4l2Device is the raw device interface used by V4L2Source.
+// The real implementation wraps a file descriptor; tests use a fake.
+type v4l2Device interface {
+	ioctl(req uintptr, arg unsafe.Pointer) error
+	mmap(offset int64, length int) ([]byte, error)
+	munmap(b []byte) error
+	waitReadable(timeout time.Duration) (bool, error)
+	close() error
+}
+
+// V4L2Source captures frames directly from a V4L2 device.
+type V4L2Source struct {
+	camera   Camera
+	settings Settings
+	caps     CameraCapabilities
+	openDev  func(path string) (v4l2Device, error)
+
+	mu        sync.Mutex
+	dev       v4l2Device
+	buffers   [][]byte
+	streaming bool
+	format    PixelFormat
+	width     int
+	height    int
+	flipped   bool // Sensor flips apply the transform
+}
+
+// NewV4L2Source creates a native V4L2 source for a discovered camera.
+// It matches the SourceFactory signature.
+func NewV4L2Source(cam Camera, s Settings) FrameSource {
+	caps := cam.Capabilities
+	if caps.MaxWidth == 0 {
+		caps.MaxWidth = s.Width
+	}
+	if caps.MaxHeight == 0 {
+		caps.MaxHeight = s.Height
+	}
+	if caps.MaxFPS == 0 {
+		caps.MaxFPS = s.FPS
+	}
+	if caps.Format == "" {
+		caps.Format = s.Format
+	}
+	return &V4L2Source{
+		camera:   cam,
+		settings: s,
+		caps:     caps,
+		openDev:  openV4L2Device,
+	}
+}
+
+// Capabilities returns the resolution and FPS requested from the driver.
+func (vs *V4L2Source) Capabilities() CameraCapabilities {
+	return vs.caps
+}
+
+// Open configures the device and starts streaming.
+// The configured format is tried first, then the other of MJPEG/YUYV, then NV12.
+func (vs *V4L2Source) Open() error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	vs.releaseLocked()
+
+	dev, err := vs.openDev(vs.camera.DevicePath)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", vs.camera.DevicePath, err)
+	}
+	vs.dev = dev
+
+	if err := vs.setupLocked(); err != nil {
+		vs.releaseLocked()
+		return err
+	}
+
+	log.Printf("[V4L2] Camera %s: Streaming %dx%d %s @ %d FPS (%d mmap buffers, no FFmpeg)",
+		vs.camera.DeviceID, vs.width, vs.height, vs.format, vs.caps.MaxFPS, len(vs.buffers))
+	return nil
+}
+
+// setFlipsLocked sets the sensor's HFLIP and VFLIP controls. Caller holds vs.mu.
+func (vs *V4L2Source) setFlipsLocked(hflip, vflip bool) error {
+	for _, c := range []struct {
+		id uint32
+		on bool
+	}{{v4l2CIDHFlip, hflip}, {v4l2CIDVFlip, vflip}} {
+		ctrl := v4l2Control{ID: c.id}
+		if c.on {
+			ctrl.Value = 1
+		}
+		if err := vs.dev.ioctl(vidiocSCtrl, unsafe.Pointer(&ctrl)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTransformLocked hands the transform to the sensor when it can do it
+// alone. Caller holds vs.mu.
+func (vs *V4L2Source) applyTransformLocked() {
+	t := vs.settings.Transform()
+	if t.IsIdentity() {
+		return
+	}
+	hflip, vflip, ok := t.sensorFlips()
+	if !ok {
+		return // Quarter turns are done by the capture worker
+	}
+	if err := vs.setFlipsLocked(hflip, vflip); err != nil {
+		log.Printf("[V4L2] Camera %s: Sensor flip not supported (%v), transforming frames in software",
+			vs.camera.DeviceID, err)
+		vs.setFlipsLocked(false, false)
+		return
+	}
+	vs.flipped = true
+	log.Printf("[V4L2] Camera %s: Sensor flip set (hflip=%v vflip=%v)", vs.camera.DeviceID, hflip, vflip)
+}
+
+// setupLocked runs the S_FMT/S_PARM/REQBUFS/QBUF/STREAMON sequence. Caller holds vs.mu.
+func (vs *V4L2Source) setupLocked() error {
+	var caps v4l2Capability
+	if err := vs.dev.ioctl(vidiocQueryCap, unsafe.Pointer(&caps)); err != nil {
+		return fmt.Errorf("VIDIOC_QUERYCAP: %w", err)
+	}
+	devCaps := caps.Capabilities
+	if caps.DeviceCaps != 0 {
+		devCaps = caps.DeviceCaps
+	}
+	if devCaps&v4l2CapVideoCapture == 0 || devCaps&v4l2CapStreaming == 0 {
+		return fmt.Errorf("%s does not support streaming video capture", vs.camera.DevicePath)
+	}
+
+	// Negotiate pixel format: configured format first, then the other, then NV12
+	order := []PixelFormat{PixelFormatMJPEG, PixelFormatYUYV, PixelFormatNV12}
+	if vs.settings.Format == "yuyv" {
+		order = []PixelFormat{PixelFormatYUYV, PixelFormatMJPEG, PixelFormatNV12}
+	}
+	negotiated := false
+	for _, format := range order {
+		pixFmt := v4l2PixFmts[format]
+		f := v4l2Format{Type: v4l2BufTypeVideoCapture}
+		f.Pix.Width = uint32(vs.caps.MaxWidth)
+		f.Pix.Height = uint32(vs.caps.MaxHeight)
+		f.Pix.PixelFormat = pixFmt
+		f.Pix.Field = v4l2FieldNone
+		if err := vs.dev.ioctl(vidiocSFmt, unsafe.Pointer(&f)); err != nil {
+			continue
+		}
+		// Drivers adjust unsupported requests instead of failing
+		if f.Pix.PixelFormat != pixFmt {
+			continue
+		}
+		vs.width = int(f.Pix.Width)
+		vs.height = int(f.Pix.Height)
+		vs.format = format
+		negotiated = true
+		break
+	}
+	if !negotiated {
+		return fmt.Errorf("%s supports none of MJPEG, YUYV or NV12 capture", vs.camera.DevicePath)
+	}
+
+	// Frame rate is best effort; cameras that ignore it are handled by frame skipping
+	parm := v4l2StreamParm{Type: v4l2BufTypeVideoCapture}
+	parm.Capture.TimePerFrame = v4l2Fract{Numerator: 1, Denominator: uint32(vs.caps.MaxFPS)}
+	if err := vs.dev.ioctl(vidiocSParm, unsafe.Pointer(&parm)); err != nil {
+		log.Printf("[V4L2] Camera %s: VIDIOC_S_PARM failed (%v), using driver frame rate",
+			vs.camera.DeviceID, err)
+	}
+
+	req := v4l2RequestBuffers{
+		Count:  v4l2BufferCount,
+		Type:   v4l2BufTypeVideoCapture,
+		Memory: v4l2MemoryMMAP,
+	}
+	if err := vs.dev.ioctl(vidiocReqBufs, unsafe.Pointer(&req)); err != nil {
+		return fmt.Errorf("VIDIOC_REQBUFS: %w", err)
+	}
+	if req.Count < 2 {
+		return fmt.Errorf("driver granted only %d buffers", req.Count)
+	}
+
+	for i := uint32(0); i < req.Count; i++ {
+		buf := v4l2Buffer{Index: i, Type: v4l2BufTypeVideoCapture, Memory: v4l2MemoryMMAP}
+		if err := vs.dev.ioctl(vidiocQueryBuf, unsafe.Pointer(&buf)); err != nil {
+			return fmt.Errorf("VIDIOC_QUERYBUF %d: %w", i, err)
+		}
+		mem, err := vs.dev.mmap(int64(uint32(buf.Offset)), int(buf.Length))
+		if err != nil {
+			return fmt.Errorf("mmap buffer %d: %w", i, err)
+		}
+		vs.buffers = append(vs.buffers, mem)
+		if err := vs.dev.ioctl(vidiocQBuf, unsafe.Pointer(&buf)); err != nil {
+			return fmt.Errorf("VIDIOC_QBUF %d: %w", i, err)
+		}
+	}
+
+	vs.applyTransformLocked()
+
+	bufType := int32(v4l2BufTypeVideoCapture)
+	if err := vs.dev.ioctl(vidiocStreamOn, unsafe.Pointer(&bufType)); err != nil {
+		return fmt.Errorf("VIDIOC_STREAMON: %w", err)
+	}
+	vs.streaming = true
+	return nil
+}
+
+// NextFrame waits for the driver to fill a buffer and returns a copy of it.
+// The mmap'd buffer is requeued immediately so the driver never runs dry.
+func (vs *V4L2Source) NextFrame() (Frame, error) {
+	vs.mu.Lock()
+	dev := vs.dev
+	vs.mu.Unlock()
+	if dev == nil {
+		return Frame{}, io.EOF
+	}
+
+	ready, err := dev.waitReadable(frameTimeout(vs.caps.MaxFPS))
+	if err != nil {
+		return Frame{}, io.EOF // Device gone (unplugged or closed)
+	}
+	if !ready {
+		return Frame{}, fmt.Errorf("timeout waiting for frame")
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if vs.dev == nil {
+		return Frame{}, io.EOF // Closed while waiting
+	}
+
+	buf := v4l2Buffer{Type: v4l2BufTypeVideoCapture, Memory: v4l2MemoryMMAP}
+	if err := vs.dev.ioctl(vidiocDQBuf, unsafe.Pointer(&buf)); err != nil {
+		if err == syscall.EAGAIN {
+			return Frame{}, fmt.Errorf("no frame ready")
+		}
+		return Frame{}, io.EOF
+	}
+	if int(buf.Index) >= len(vs.buffers) {
+		return Frame{}, fmt.Errorf("driver returned unknown buffer %d", buf.Index)
+	}
+
+	mem := vs.buffers[buf.Index]
+	used := int(buf.BytesUsed)
+	if used <= 0 || used > len(mem) {
+		used = len(mem)
+	}
+	data := make([]byte, used)
+	copy(data, mem[:used])
+
+	if err := vs.dev.ioctl(vidiocQBuf, unsafe.Pointer(&buf)); err != nil {
+		return Frame{}, io.EOF
+	}
+
+	return Frame{Data: data, Format: vs.format, Width: vs.width, Height: vs.height, Transformed: vs.flipped}, nil
+}
+
+// Close stops streaming, unmaps buffers and closes the device.
+func (vs *V4L2Source) Close() error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	vs.releaseLocked()
+	return nil
+}
+
+// releaseLocked tears down whatever Open set up. Caller holds vs.mu.
+func (vs *V4L2Source) releaseLocked() {
+	if vs.dev == nil {
+		return
+	}
+	if vs.streaming {
+		bufType := int32(v4l2BufTypeVideoCapture)
+		vs.dev.ioctl(vidiocStreamOff, unsafe.Pointer(&bufType))
+		vs.streaming = false
+	}
+	for _, mem := range vs.buffers {
+		vs.dev.munmap(mem)
+	}
+	vs.buffers = nil
+	// Free driver buffers so the next Open can renegotiate the format
+	req := v4l2RequestBuffers{Type: v4l2BufTypeVideoCapture, Memory: v4l2MemoryMMAP}
+	vs.dev.ioctl(vidiocReqBufs, unsafe.Pointer(&req))
+	// Controls outlive the file descriptor; leave the sensor as we found it
+	if vs.flipped {
+		vs.setFlipsLocked(false, false)
+		vs.flipped = false
+	}
+	vs.dev.close()
+	vs.dev = nil
+}
@@ -0,0 +1,85 @@
+package camera
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// v4l2FileDevice is a V4L2 device node opened non-blocking.
+type v4l2FileDevice struct {
+	fd int
+}
+
+// openV4L2Device opens a /dev/videoX node for streaming capture.
+func openV4L2Device(path string) (v4l2Device, error) {
+	fd, err := syscall.Open(path, syscall.O_RDWR|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &v4l2FileDevice{fd: fd}, nil
+}
+
+func (d *v4l2FileDevice) ioctl(req uintptr, arg unsafe.Pointer) error {
+	for {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(d.fd), req, uintptr(arg))
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
+
+func (d *v4l2FileDevice) mmap(offset int64, length int) ([]byte, error) {
+	return syscall.Mmap(d.fd, offset, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+func (d *v4l2FileDevice) munmap(b []byte) error {
+	return syscall.Munmap(b)
+}
+
+// pollFd mirrors struct pollfd.
+type pollFd struct {
+	fd      int32
+	events  int16
+	revents int16
+}
+
+const (
+	pollIn   = 0x1
+	pollErr  = 0x8
+	pollHup  = 0x10
+	pollNval = 0x20
+)
+
+// waitReadable blocks until a filled buffer can be dequeued or timeout expires.
+// ppoll is used because arm64 has no plain poll syscall.
+func (d *v4l2FileDevice) waitReadable(timeout time.Duration) (bool, error) {
+	pfd := pollFd{fd: int32(d.fd), events: pollIn}
+	ts := syscall.NsecToTimespec(timeout.Nanoseconds())
+	for {
+		n, _, errno := syscall.Syscall6(syscall.SYS_PPOLL,
+			uintptr(unsafe.Pointer(&pfd)), 1, uintptr(unsafe.Pointer(&ts)), 0, 0, 0)
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return false, errno
+		}
+		if n == 0 {
+			return false, nil
+		}
+		if pfd.revents&(pollErr|pollHup|pollNval) != 0 {
+			return false, fmt.Errorf("device error (revents 0x%x)", pfd.revents)
+		}
+		return pfd.revents&pollIn != 0, nil
+	}
+}
+
+func (d *v4l2FileDevice) close() error {
+	return syscall.Close(d.fd)
+}
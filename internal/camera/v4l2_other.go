@@ -0,0 +1,10 @@
+//go:build !linux
+
+package camera
+
+import "errors"
+
+// openV4L2Device is unavailable off Linux; use the FFmpeg backend instead.
+func openV4L2Device(path string) (v4l2Device, error) {
+	return nil, errors.New("native V4L2 capture is only supported on Linux")
+}
@@ -0,0 +1,266 @@
+package camera
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fakeV4L2Device emulates a capture driver: it accepts one pixel format,
+// hands out mmap'd buffers and fills each dequeued buffer with frame.
+type fakeV4L2Device struct {
+	mu        sync.Mutex
+	pixFmt    uint32 // Only format the "driver" supports
+	frame     []byte // Payload written into every dequeued buffer
+	bufSize   int
+	memory    [][]byte
+	queued    []uint32
+	streaming bool
+	closed    bool
+	unmapped  int
+	calls     []uintptr
+	controls  map[uint32]int32 // Supported controls; nil = none (S_CTRL fails)
+}
+
+func newFakeV4L2Device(pixFmt uint32, frame []byte) *fakeV4L2Device {
+	return &fakeV4L2Device{pixFmt: pixFmt, frame: frame, bufSize: len(frame) + 1024}
+}
+
+func (d *fakeV4L2Device) ioctl(req uintptr, arg unsafe.Pointer) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return syscall.EBADF
+	}
+	d.calls = append(d.calls, req)
+
+	switch req {
+	case vidiocQueryCap:
+		c := (*v4l2Capability)(arg)
+		c.Capabilities = v4l2CapVideoCapture | v4l2CapStreaming
+	case vidiocSFmt:
+		f := (*v4l2Format)(arg)
+		f.Pix.PixelFormat = d.pixFmt // Drivers adjust rather than fail
+	case vidiocSParm:
+	case vidiocReqBufs:
+		r := (*v4l2RequestBuffers)(arg)
+		d.memory = nil
+		for i := uint32(0); i < r.Count; i++ {
+			d.memory = append(d.memory, make([]byte, d.bufSize))
+		}
+	case vidiocQueryBuf:
+		b := (*v4l2Buffer)(arg)
+		b.Offset = uintptr(int(b.Index) * d.bufSize)
+		b.Length = uint32(d.bufSize)
+	case vidiocQBuf:
+		d.queued = append(d.queued, (*v4l2Buffer)(arg).Index)
+	case vidiocDQBuf:
+		if !d.streaming || len(d.queued) == 0 {
+			return syscall.EAGAIN
+		}
+		b := (*v4l2Buffer)(arg)
+		b.Index = d.queued[0]
+		d.queued = d.queued[1:]
+		b.BytesUsed = uint32(copy(d.memory[b.Index], d.frame))
+	case vidiocStreamOn:
+		d.streaming = true
+	case vidiocStreamOff:
+		d.streaming = false
+	case vidiocSCtrl:
+		c := (*v4l2Control)(arg)
+		if _, ok := d.controls[c.ID]; !ok {
+			return syscall.EINVAL
+		}
+		d.controls[c.ID] = c.Value
+	default:
+		return syscall.EINVAL
+	}
+	return nil
+}
+
+func (d *fakeV4L2Device) mmap(offset int64, length int) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.memory[int(offset)/d.bufSize][:length], nil
+}
+
+func (d *fakeV4L2Device) munmap(b []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.unmapped++
+	return nil
+}
+
+func (d *fakeV4L2Device) waitReadable(timeout time.Duration) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return false, syscall.EBADF
+	}
+	return d.streaming && len(d.queued) > 0, nil
+}
+
+func (d *fakeV4L2Device) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+func newTestV4L2Source(dev *fakeV4L2Device, format string) *V4L2Source {
+	s := DefaultSettings()
+	s.Width, s.Height, s.Format = 4, 2, format
+	src := NewV4L2Source(Camera{DeviceID: "video9", DevicePath: "/dev/video9"}, s).(*V4L2Source)
+	src.openDev = func(string) (v4l2Device, error) { return dev, nil }
+	return src
+}
+
+func TestV4L2IoctlNumbers(t *testing.T) {
+	// Values from <linux/videodev2.h> on 64-bit Linux
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("reference values are for 64-bit platforms")
+	}
+	tests := []struct {
+		name string
+		got  uintptr
+		want uintptr
+	}{
+		{"VIDIOC_QUERYCAP", vidiocQueryCap, 0x80685600},
+		{"VIDIOC_S_FMT", vidiocSFmt, 0xc0d05605},
+		{"VIDIOC_REQBUFS", vidiocReqBufs, 0xc0145608},
+		{"VIDIOC_QUERYBUF", vidiocQueryBuf, 0xc0585609},
+		{"VIDIOC_QBUF", vidiocQBuf, 0xc058560f},
+		{"VIDIOC_DQBUF", vidiocDQBuf, 0xc0585611},
+		{"VIDIOC_STREAMON", vidiocStreamOn, 0x40045612},
+		{"VIDIOC_STREAMOFF", vidiocStreamOff, 0x40045613},
+		{"VIDIOC_S_PARM", vidiocSParm, 0xc0cc5616},
+		{"VIDIOC_S_CTRL", vidiocSCtrl, 0xc008561c},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %#x, want %#x", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestV4L2Source_StreamsMJPEG(t *testing.T) {
+	jpegData := encodeTestJPEG(t, color.White)
+	dev := newFakeV4L2Device(v4l2PixFmtMJPEG, jpegData)
+	src := newTestV4L2Source(dev, "mjpeg")
+
+	if err := src.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(dev.queued) != v4l2BufferCount || !dev.streaming {
+		t.Fatalf("after Open: %d buffers queued, streaming=%v", len(dev.queued), dev.streaming)
+	}
+
+	// More frames than buffers proves buffers are requeued
+	for i := 0; i < v4l2BufferCount*2; i++ {
+		f, err := src.NextFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if f.Format != PixelFormatMJPEG || !bytes.Equal(f.Data, jpegData) {
+			t.Fatalf("frame %d: format %v, %d bytes", i, f.Format, len(f.Data))
+		}
+	}
+
+	src.Close()
+	if dev.streaming || !dev.closed || dev.unmapped != v4l2BufferCount {
+		t.Errorf("after Close: streaming=%v closed=%v unmapped=%d", dev.streaming, dev.closed, dev.unmapped)
+	}
+	if _, err := src.NextFrame(); err != io.EOF {
+		t.Errorf("NextFrame after Close: err = %v, want io.EOF", err)
+	}
+}
+
+func TestV4L2Source_FallsBackToYUYV(t *testing.T) {
+	// 4x2 YUYV: Y ramps 0..7, Cb=100, Cr=200
+	var yuyv []byte
+	for i := 0; i < 8; i += 2 {
+		yuyv = append(yuyv, byte(i), 100, byte(i+1), 200)
+	}
+	dev := newFakeV4L2Device(v4l2PixFmtYUYV, yuyv)
+	src := newTestV4L2Source(dev, "mjpeg")
+
+	if err := src.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer src.Close()
+
+	f, err := src.NextFrame()
+	if err != nil {
+		t.Fatalf("NextFrame: %v", err)
+	}
+	if f.Format != PixelFormatYUYV || f.Width != 4 || f.Height != 2 {
+		t.Fatalf("got format %v %dx%d, want yuyv 4x2", f.Format, f.Width, f.Height)
+	}
+
+	img, err := yuyvToYCbCr(f.Data, f.Width, f.Height)
+	if err != nil {
+		t.Fatalf("yuyvToYCbCr: %v", err)
+	}
+	if img.SubsampleRatio != image.YCbCrSubsampleRatio422 {
+		t.Errorf("subsample ratio = %v, want 4:2:2", img.SubsampleRatio)
+	}
+	if got := img.YCbCrAt(3, 1); got.Y != 7 || got.Cb != 100 || got.Cr != 200 {
+		t.Errorf("pixel (3,1) = %+v, want Y=7 Cb=100 Cr=200", got)
+	}
+}
+
+func TestV4L2Source_SensorFlip(t *testing.T) {
+	jpegData := encodeTestJPEG(t, color.White)
+
+	// Rotation 180 + mirror = flip: the sensor can do it
+	dev := newFakeV4L2Device(v4l2PixFmtMJPEG, jpegData)
+	dev.controls = map[uint32]int32{v4l2CIDHFlip: 0, v4l2CIDVFlip: 0}
+	src := newTestV4L2Source(dev, "mjpeg")
+	src.settings.Rotation, src.settings.Mirror = 180, true
+	if err := src.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if dev.controls[v4l2CIDHFlip] != 0 || dev.controls[v4l2CIDVFlip] != 1 {
+		t.Errorf("controls = %v, want vflip only", dev.controls)
+	}
+	if f, err := src.NextFrame(); err != nil || !f.Transformed {
+		t.Errorf("NextFrame = transformed %v, err %v; want sensor-transformed frame", f.Transformed, err)
+	}
+	src.Close()
+	if dev.controls[v4l2CIDVFlip] != 0 {
+		t.Errorf("controls after Close = %v, want reset", dev.controls)
+	}
+
+	// Quarter turns and drivers without flip controls leave it to software
+	for _, tc := range []struct {
+		name     string
+		controls map[uint32]int32
+		rotation int
+	}{
+		{"quarter turn", map[uint32]int32{v4l2CIDHFlip: 0, v4l2CIDVFlip: 0}, 90},
+		{"no controls", nil, 180},
+	} {
+		dev := newFakeV4L2Device(v4l2PixFmtMJPEG, jpegData)
+		dev.controls = tc.controls
+		src := newTestV4L2Source(dev, "mjpeg")
+		src.settings.Rotation = tc.rotation
+		if err := src.Open(); err != nil {
+			t.Fatalf("%s: Open: %v", tc.name, err)
+		}
+		if f, err := src.NextFrame(); err != nil || f.Transformed {
+			t.Errorf("%s: NextFrame = transformed %v, err %v; want untransformed", tc.name, f.Transformed, err)
+		}
+		for id, v := range dev.controls {
+			if v != 0 {
+				t.Errorf("%s: control %#x = %d, want 0", tc.name, id, v)
+			}
+		}
+		src.Close()
+	}
+}
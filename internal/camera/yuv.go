@@ -0,0 +1,60 @@
+package camera
+
+import (
+	"fmt"
+	"image"
+)
+
+// yuyvToYCbCr converts packed YUYV 4:2:2 (Y0 U Y1 V per pixel pair) into a
+// planar image.YCbCr. This is a straight byte shuffle; no color math needed.
+func yuyvToYCbCr(data []byte, width, height int) (*image.YCbCr, error) {
+	if width <= 0 || height <= 0 || width%2 != 0 {
+		return nil, fmt.Errorf("invalid YUYV frame size %dx%d", width, height)
+	}
+	if len(data) < width*height*2 {
+		return nil, fmt.Errorf("short YUYV frame: %d bytes, want %d", len(data), width*height*2)
+	}
+
+	img := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio422)
+	for y := 0; y < height; y++ {
+		src := data[y*width*2 : (y+1)*width*2]
+		yRow := img.Y[y*img.YStride : y*img.YStride+width]
+		cbRow := img.Cb[y*img.CStride : y*img.CStride+width/2]
+		crRow := img.Cr[y*img.CStride : y*img.CStride+width/2]
+		for x := 0; x < width/2; x++ {
+			yRow[2*x] = src[4*x]
+			cbRow[x] = src[4*x+1]
+			yRow[2*x+1] = src[4*x+2]
+			crRow[x] = src[4*x+3]
+		}
+	}
+	return img, nil
+}
+
+// nv12ToYCbCr converts NV12 (full Y plane followed by interleaved Cb/Cr at
+// half resolution) into a planar 4:2:0 image.YCbCr.
+func nv12ToYCbCr(data []byte, width, height int) (*image.YCbCr, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid NV12 frame size %dx%d", width, height)
+	}
+	cw, ch := (width+1)/2, (height+1)/2
+	if len(data) < width*height+2*cw*ch {
+		return nil, fmt.Errorf("short NV12 frame: %d bytes, want %d", len(data), width*height+2*cw*ch)
+	}
+
+	img := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio420)
+	for y := 0; y < height; y++ {
+		copy(img.Y[y*img.YStride:y*img.YStride+width], data[y*width:(y+1)*width])
+	}
+	uv := data[width*height:]
+	for y := 0; y < ch; y++ {
+		src := uv[y*cw*2 : (y+1)*cw*2]
+		cbRow := img.Cb[y*img.CStride : y*img.CStride+cw]
+		crRow := img.Cr[y*img.CStride : y*img.CStride+cw]
+		for x := 0; x < cw; x++ {
+			cbRow[x] = src[2*x]
+			crRow[x] = src[2*x+1]
+		}
+	}
+	return img, nil
+}
@@ -7,6 +7,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -17,6 +18,10 @@ import (
 
 // Config holds all runtime configuration values.
 type Config struct {
+	// Path is the INI file the config was loaded from ("" for defaults);
+	// SetValue writes changes made at runtime back to it.
+	Path string
+
 	// Logging
 	// LogLevel controls coarse output filtering (DEBUG/INFO/WARNING/ERROR/CRITICAL).
 	// Untagged messages are treated as INFO by the logger filter.
@@ -46,13 +51,69 @@ type Config struct {
 	FailedCameraCooldownS float64
 	CameraSlotCount       int
 	KillDeviceHolders     bool
+	CameraSlotPins        []string                // [slots] identity key per slot ("" = unpinned)
+	Cameras               map[string]CameraConfig // [camera.<id-or-port>] per-camera overrides
 
 	// Profile
-	CaptureWidth  int
-	CaptureHeight int
-	CaptureFPS    int
-	CaptureFormat string // "mjpeg" or "yuyv"; passed to FFmpeg as -input_format
-	UIFPS         int
+	CaptureWidth   int
+	CaptureHeight  int
+	CaptureFPS     int
+	CaptureFormat  string // "mjpeg" or "yuyv"; passed to FFmpeg as -input_format
+	CaptureBackend string // "ffmpeg" (child process) or "v4l2" (native ioctl/mmap)
+	UIFPS          int
+	DisplayFilters string // Display filter chain ("" = filter.DefaultSpec)
+
+	// Recording (loop DVR)
+	RecordingEnabled     bool
+	RecordingDir         string
+	RecordingSegmentSec  int
+	RecordingMaxTotalMB  int // 0 = no size limit
+	RecordingMinFreeMB   int // 0 = no free-space limit
+	RecordingJPEGQuality int // For frames that must be re-encoded (raw formats, rotation)
+
+	// Event clips (pre/post-event footage from an in-memory ring)
+	ClipsEnabled bool
+	ClipsDir     string
+	ClipPreSec   int
+	ClipPostSec  int
+	ClipRingMB   int // Per-camera memory limit of the pre-event ring
+
+	// Motion detection (frame differencing, red tile border)
+	MotionEnabled     bool
+	MotionSensitivity int     // 1 (large changes only) .. 100 (small changes)
+	MotionMinAreaPct  float64 // Percent of the frame that must change
+	MotionCheckFPS    int     // Frames checked per second and camera
+	MotionHoldSec     float64 // Motion stays flagged this long after the last change
+	MotionClips       bool    // Save an event clip when motion starts
+	MotionShowZones   bool    // Outline the detection zones on camera tiles
+
+	// Parking guide lines drawn over one camera (normalized coordinates)
+	GuidesEnabled      bool
+	GuidesCamera       string    // Role, identity, device ID or slot number
+	GuidesCenterX      float64   // Horizontal centre of the guides
+	GuidesNearY        float64   // Near end (close to the vehicle)
+	GuidesFarY         float64   // Far end, towards the horizon
+	GuidesNearWidth    float64   // Distance between the rails at the near end
+	GuidesFarWidth     float64   // Distance between the rails at the far end
+	GuidesBands        []float64 // Red/yellow/green boundaries as fractions of the guide length
+	GuidesBend         float64   // Sideways shift of the far end at full steering lock
+	GuidesSteeringPath string    // File holding the steering value (-1..1); "" = API only
+
+	// Inputs (GPIO lines, files, API) that drive display actions
+	Triggers []TriggerConfig // [trigger.<name>] sections, sorted by name
+
+	// Snapshots (still frames from the Snapshot button / Manager.Snapshot)
+	SnapshotDir         string
+	SnapshotFormat      string // "jpeg" or "png"
+	SnapshotJPEGQuality int
+
+	// Embedded HTTP server (MJPEG streams for phones/laptops)
+	HTTPEnabled     bool
+	HTTPListen      string
+	HTTPJPEGQuality int
+	HTTPMaxClients  int
+	HTTPMaxFPS      int    // 0 = follow the adaptive capture FPS
+	HTTPAPIToken    string // Bearer token the control API requires ("" = none)
 
 	// Health
 	HealthLogIntervalSec float64
@@ -64,6 +125,53 @@ type Config struct {
 	UIFPSLogging bool
 }
 
+// CameraConfig holds the settings of one [camera.<id-or-port>] section.
+// Zero values (and Slot -1) mean "inherit the global setting".
+type CameraConfig struct {
+	Width    int
+	Height   int
+	FPS      int
+	Format   string // "mjpeg" or "yuyv"
+	Name     string // Display name shown instead of the V4L2 card name
+	Role     string // Role label, e.g. "Left", "Rear" or "Trailer"
+	Rotation int    // Clockwise degrees: 0, 90, 180 or 270
+	Mirror   bool   // Swap left and right, e.g. for side cameras
+	Flip     bool   // Swap top and bottom
+	Slot     int    // Slot to pin this camera to, or -1
+	NoMotion bool   // motion = off: exclude this camera from motion detection
+	Zones    string // Motion detection zones, "x,y x,y x,y; ..." (normalized)
+	Crop     string // Shown part of the frame, "x,y,w,h" (normalized)
+	Filters  string // Display filter chain replacing [profile] filters
+
+	// Fisheye correction: DewarpFile is a calibration file (k1, k2, cx, cy,
+	// scale); the dewarp_* keys override its values. All zero = none.
+	DewarpK1    float64
+	DewarpK2    float64
+	DewarpScale float64
+	DewarpFile  string
+}
+
+// TriggerConfig holds the settings of one [trigger.<name>] section.
+type TriggerConfig struct {
+	Name       string
+	Source     string // "gpio", "file" or "api"
+	Chip       string // GPIO chip, e.g. "gpiochip0" or "/dev/gpiochip0"
+	Line       int    // GPIO line offset on Chip, or -1
+	ActiveLow  bool   // GPIO line is active when low
+	Bias       string // GPIO bias: "", "pull-up", "pull-down" or "disable"
+	Path       string // File or FIFO holding the value ("1"/"0", "on"/"off", ...)
+	Action     string // "fullscreen" or "clip"
+	Camera     string // Target camera: role, identity, device ID or slot number
+	DebounceMS int    // The input must hold a new value this long to count
+	HoldMS     int    // Stay active this long after the input clears (e.g. blinking turn signals)
+}
+
+// Trigger defaults for keys a [trigger.<name>] section leaves out
+const (
+	DefaultTriggerAction     = "fullscreen"
+	DefaultTriggerDebounceMS = 50
+)
+
 // =============================================================================
 // Defaults
 // =============================================================================
@@ -101,11 +209,59 @@ func DefaultConfig() *Config {
 		KillDeviceHolders:     true,
 
 		// Profile
-		CaptureWidth:  640,
-		CaptureHeight: 480,
-		CaptureFPS:    25,
-		CaptureFormat: "mjpeg",
-		UIFPS:         20,
+		CaptureWidth:   640,
+		CaptureHeight:  480,
+		CaptureFPS:     25,
+		CaptureFormat:  "mjpeg",
+		CaptureBackend: "ffmpeg",
+		UIFPS:          20,
+
+		// Recording
+		RecordingEnabled:     false,
+		RecordingDir:         "./recordings",
+		RecordingSegmentSec:  60,
+		RecordingMaxTotalMB:  4096,
+		RecordingMinFreeMB:   512,
+		RecordingJPEGQuality: 85,
+
+		// Event clips
+		ClipsEnabled: false,
+		ClipsDir:     "./clips",
+		ClipPreSec:   10,
+		ClipPostSec:  10,
+		ClipRingMB:   32,
+
+		// Motion detection
+		MotionEnabled:     false,
+		MotionSensitivity: 50,
+		MotionMinAreaPct:  1.0,
+		MotionCheckFPS:    5,
+		MotionHoldSec:     2.0,
+		MotionClips:       false,
+		MotionShowZones:   false,
+
+		// Parking guides
+		GuidesEnabled:   false,
+		GuidesCamera:    "Rear",
+		GuidesCenterX:   0.5,
+		GuidesNearY:     0.95,
+		GuidesFarY:      0.55,
+		GuidesNearWidth: 0.7,
+		GuidesFarWidth:  0.3,
+		GuidesBands:     []float64{0.25, 0.55},
+		GuidesBend:      0.15,
+
+		// Snapshots
+		SnapshotDir:         "./snapshots",
+		SnapshotFormat:      "jpeg",
+		SnapshotJPEGQuality: 90,
+
+		// HTTP server
+		HTTPEnabled:     false,
+		HTTPListen:      "127.0.0.1:8080",
+		HTTPJPEGQuality: 80,
+		HTTPMaxClients:  8,
+		HTTPMaxFPS:      0,
 
 		// Health
 		HealthLogIntervalSec: 30.0,
@@ -180,6 +336,71 @@ func (d iniData) hasSection(section string) bool {
 	return ok
 }
 
+// SetValue sets key in [section] of the INI file at path, keeping comments
+// and the order of everything else. An empty value removes the key. A
+// missing key is added at the end of its section, and a missing section (or
+// file) is created. The file is replaced atomically.
+func SetValue(path, section, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	entry := key + " = " + value
+	out := make([]string, 0, len(lines)+3)
+	inSection, found := false, false
+	insertAt := -1 // Index in out after the last entry of the section
+	for _, rawLine := range lines {
+		line := strings.TrimSpace(rawLine)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.TrimSpace(line[1:len(line)-1]) == section
+			out = append(out, rawLine)
+			if inSection {
+				insertAt = len(out)
+			}
+			continue
+		}
+		if inSection && line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") {
+			if idx := strings.IndexByte(line, '='); idx > 0 && strings.TrimSpace(line[:idx]) == key {
+				found = true
+				if value == "" {
+					continue
+				}
+				out = append(out, entry)
+				insertAt = len(out)
+				continue
+			}
+			insertAt = len(out) + 1
+		}
+		out = append(out, rawLine)
+	}
+
+	switch {
+	case found || value == "":
+	case insertAt >= 0:
+		out = append(out[:insertAt], append([]string{entry}, out[insertAt:]...)...)
+	default:
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		out = append(out, "["+section+"]", entry)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(out, "\n")+"\n"), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
 // =============================================================================
 // Type parsing helpers (match Python's _as_bool, _as_int, _as_float)
 // =============================================================================
@@ -238,6 +459,24 @@ func asFloat(value string, fallback float64, minVal, maxVal *float64) float64 {
 	return parsed
 }
 
+// asFractions parses a comma-separated ascending list of numbers strictly
+// between 0 and 1. "" is an empty list; anything else invalid fails.
+func asFractions(value string) ([]float64, bool) {
+	list := []float64{}
+	for _, f := range strings.Split(value, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || v <= 0 || v >= 1 || (len(list) > 0 && v <= list[len(list)-1]) {
+			return nil, false
+		}
+		list = append(list, v)
+	}
+	return list, true
+}
+
 // Helper functions to create pointers for min/max bounds
 func intPtr(v int) *int           { return &v }
 func floatPtr(v float64) *float64 { return &v }
@@ -263,6 +502,7 @@ func Load(path string) (*Config, error) {
 	}
 
 	cfg := DefaultConfig()
+	cfg.Path = path
 
 	// If file doesn't exist, return defaults (not an error)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -365,6 +605,27 @@ func applyINI(cfg *Config, ini iniData) {
 		}
 	}
 
+	// [slots] - pin a camera identity to a slot: "<slot> = <identity>", slots from 0
+	if ini.hasSection("slots") {
+		for key, value := range ini["slots"] {
+			slot := asInt(key, -1, nil, nil)
+			value = strings.TrimSpace(value)
+			if slot < 0 || slot >= 8 || value == "" {
+				continue
+			}
+			for len(cfg.CameraSlotPins) <= slot {
+				cfg.CameraSlotPins = append(cfg.CameraSlotPins, "")
+			}
+			cfg.CameraSlotPins[slot] = value
+		}
+	}
+
+	// [camera.<id-or-port>] - per-camera overrides, keyed by identity
+	applyCameraSections(cfg, ini)
+
+	// [trigger.<name>] - inputs that drive display actions
+	applyTriggerSections(cfg, ini)
+
 	// [profile]
 	if ini.hasSection("profile") {
 		if v, ok := ini.get("profile", "capture_width"); ok {
@@ -382,9 +643,160 @@ func applyINI(cfg *Config, ini iniData) {
 				cfg.CaptureFormat = v
 			}
 		}
+		if v, ok := ini.get("profile", "capture_backend"); ok {
+			v = strings.ToLower(strings.TrimSpace(v))
+			if v == "ffmpeg" || v == "v4l2" {
+				cfg.CaptureBackend = v
+			}
+		}
 		if v, ok := ini.get("profile", "ui_fps"); ok {
 			cfg.UIFPS = asInt(v, cfg.UIFPS, intPtr(1), intPtr(60))
 		}
+		if v, ok := ini.get("profile", "filters"); ok {
+			cfg.DisplayFilters = strings.TrimSpace(v)
+		}
+	}
+
+	// [recording]
+	if ini.hasSection("recording") {
+		if v, ok := ini.get("recording", "enabled"); ok {
+			cfg.RecordingEnabled = asBool(v, cfg.RecordingEnabled)
+		}
+		if v, ok := ini.get("recording", "dir"); ok && strings.TrimSpace(v) != "" {
+			cfg.RecordingDir = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get("recording", "segment_sec"); ok {
+			cfg.RecordingSegmentSec = asInt(v, cfg.RecordingSegmentSec, intPtr(10), intPtr(3600))
+		}
+		if v, ok := ini.get("recording", "max_total_mb"); ok {
+			cfg.RecordingMaxTotalMB = asInt(v, cfg.RecordingMaxTotalMB, intPtr(0), nil)
+		}
+		if v, ok := ini.get("recording", "min_free_mb"); ok {
+			cfg.RecordingMinFreeMB = asInt(v, cfg.RecordingMinFreeMB, intPtr(0), nil)
+		}
+		if v, ok := ini.get("recording", "jpeg_quality"); ok {
+			cfg.RecordingJPEGQuality = asInt(v, cfg.RecordingJPEGQuality, intPtr(30), intPtr(100))
+		}
+	}
+
+	// [clips]
+	if ini.hasSection("clips") {
+		if v, ok := ini.get("clips", "enabled"); ok {
+			cfg.ClipsEnabled = asBool(v, cfg.ClipsEnabled)
+		}
+		if v, ok := ini.get("clips", "dir"); ok && strings.TrimSpace(v) != "" {
+			cfg.ClipsDir = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get("clips", "pre_sec"); ok {
+			cfg.ClipPreSec = asInt(v, cfg.ClipPreSec, intPtr(1), intPtr(120))
+		}
+		if v, ok := ini.get("clips", "post_sec"); ok {
+			cfg.ClipPostSec = asInt(v, cfg.ClipPostSec, intPtr(1), intPtr(300))
+		}
+		if v, ok := ini.get("clips", "ring_mb"); ok {
+			cfg.ClipRingMB = asInt(v, cfg.ClipRingMB, intPtr(1), intPtr(512))
+		}
+	}
+
+	// [motion]
+	if ini.hasSection("motion") {
+		if v, ok := ini.get("motion", "enabled"); ok {
+			cfg.MotionEnabled = asBool(v, cfg.MotionEnabled)
+		}
+		if v, ok := ini.get("motion", "sensitivity"); ok {
+			cfg.MotionSensitivity = asInt(v, cfg.MotionSensitivity, intPtr(1), intPtr(100))
+		}
+		if v, ok := ini.get("motion", "min_area_pct"); ok {
+			cfg.MotionMinAreaPct = asFloat(v, cfg.MotionMinAreaPct, floatPtr(0.1), floatPtr(100.0))
+		}
+		if v, ok := ini.get("motion", "check_fps"); ok {
+			cfg.MotionCheckFPS = asInt(v, cfg.MotionCheckFPS, intPtr(1), intPtr(30))
+		}
+		if v, ok := ini.get("motion", "hold_sec"); ok {
+			cfg.MotionHoldSec = asFloat(v, cfg.MotionHoldSec, floatPtr(0.0), floatPtr(60.0))
+		}
+		if v, ok := ini.get("motion", "clips"); ok {
+			cfg.MotionClips = asBool(v, cfg.MotionClips)
+		}
+		if v, ok := ini.get("motion", "show_zones"); ok {
+			cfg.MotionShowZones = asBool(v, cfg.MotionShowZones)
+		}
+	}
+
+	// [guides]
+	if ini.hasSection("guides") {
+		if v, ok := ini.get("guides", "enabled"); ok {
+			cfg.GuidesEnabled = asBool(v, cfg.GuidesEnabled)
+		}
+		if v, ok := ini.get("guides", "camera"); ok && strings.TrimSpace(v) != "" {
+			cfg.GuidesCamera = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get("guides", "center_x"); ok {
+			cfg.GuidesCenterX = asFloat(v, cfg.GuidesCenterX, floatPtr(0.0), floatPtr(1.0))
+		}
+		if v, ok := ini.get("guides", "near_y"); ok {
+			cfg.GuidesNearY = asFloat(v, cfg.GuidesNearY, floatPtr(0.0), floatPtr(1.0))
+		}
+		if v, ok := ini.get("guides", "far_y"); ok {
+			cfg.GuidesFarY = asFloat(v, cfg.GuidesFarY, floatPtr(0.0), floatPtr(1.0))
+		}
+		if v, ok := ini.get("guides", "near_width"); ok {
+			cfg.GuidesNearWidth = asFloat(v, cfg.GuidesNearWidth, floatPtr(0.0), floatPtr(1.0))
+		}
+		if v, ok := ini.get("guides", "far_width"); ok {
+			cfg.GuidesFarWidth = asFloat(v, cfg.GuidesFarWidth, floatPtr(0.0), floatPtr(1.0))
+		}
+		if v, ok := ini.get("guides", "bend"); ok {
+			cfg.GuidesBend = asFloat(v, cfg.GuidesBend, floatPtr(0.0), floatPtr(1.0))
+		}
+		if v, ok := ini.get("guides", "bands"); ok {
+			if bands, ok := asFractions(v); ok {
+				cfg.GuidesBands = bands
+			}
+		}
+		if v, ok := ini.get("guides", "steering_path"); ok {
+			cfg.GuidesSteeringPath = strings.TrimSpace(v)
+		}
+	}
+
+	// [snapshot]
+	if ini.hasSection("snapshot") {
+		if v, ok := ini.get("snapshot", "dir"); ok && strings.TrimSpace(v) != "" {
+			cfg.SnapshotDir = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get("snapshot", "format"); ok {
+			switch f := strings.ToLower(strings.TrimSpace(v)); f {
+			case "jpeg", "png":
+				cfg.SnapshotFormat = f
+			case "jpg":
+				cfg.SnapshotFormat = "jpeg"
+			}
+		}
+		if v, ok := ini.get("snapshot", "jpeg_quality"); ok {
+			cfg.SnapshotJPEGQuality = asInt(v, cfg.SnapshotJPEGQuality, intPtr(30), intPtr(100))
+		}
+	}
+
+	// [http]
+	if ini.hasSection("http") {
+		if v, ok := ini.get("http", "enabled"); ok {
+			cfg.HTTPEnabled = asBool(v, cfg.HTTPEnabled)
+		}
+		if v, ok := ini.get("http", "listen"); ok && strings.TrimSpace(v) != "" {
+			cfg.HTTPListen = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get("http", "jpeg_quality"); ok {
+			cfg.HTTPJPEGQuality = asInt(v, cfg.HTTPJPEGQuality, intPtr(30), intPtr(100))
+		}
+		if v, ok := ini.get("http", "max_clients"); ok {
+			cfg.HTTPMaxClients = asInt(v, cfg.HTTPMaxClients, intPtr(1), intPtr(64))
+		}
+		if v, ok := ini.get("http", "max_fps"); ok {
+			cfg.HTTPMaxFPS = asInt(v, cfg.HTTPMaxFPS, intPtr(0), intPtr(60))
+		}
+		if v, ok := ini.get("http", "api_token"); ok {
+			cfg.HTTPAPIToken = strings.TrimSpace(v)
+		}
 	}
 
 	// [health]
@@ -395,6 +807,160 @@ func applyINI(cfg *Config, ini iniData) {
 	}
 }
 
+// cameraSectionPrefix starts the name of a per-camera section, e.g. [camera.1-1.3].
+const cameraSectionPrefix = "camera."
+
+// applyCameraSections parses the per-camera sections. Values use the same
+// limits as [profile]; a "slot" key pins the camera like [slots] does and
+// wins over it. Sections are applied in sorted order so conflicts resolve
+// the same way on every start.
+func applyCameraSections(cfg *Config, ini iniData) {
+	var sections []string
+	for section := range ini {
+		if strings.HasPrefix(section, cameraSectionPrefix) &&
+			strings.TrimSpace(strings.TrimPrefix(section, cameraSectionPrefix)) != "" {
+			sections = append(sections, section)
+		}
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		key := strings.TrimSpace(strings.TrimPrefix(section, cameraSectionPrefix))
+		cc := CameraConfig{Slot: -1}
+		if v, ok := ini.get(section, "width"); ok {
+			cc.Width = asInt(v, 0, intPtr(160), intPtr(1920))
+		}
+		if v, ok := ini.get(section, "height"); ok {
+			cc.Height = asInt(v, 0, intPtr(120), intPtr(1080))
+		}
+		if v, ok := ini.get(section, "fps"); ok {
+			cc.FPS = asInt(v, 0, intPtr(1), intPtr(60))
+		}
+		if v, ok := ini.get(section, "format"); ok {
+			v = strings.ToLower(strings.TrimSpace(v))
+			if v == "mjpeg" || v == "yuyv" {
+				cc.Format = v
+			}
+		}
+		if v, ok := ini.get(section, "name"); ok {
+			cc.Name = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "role"); ok {
+			cc.Role = strings.TrimSpace(v)
+		}
+		rotation, ok := ini.get(section, "rotation")
+		if !ok {
+			rotation, ok = ini.get(section, "rotate")
+		}
+		if ok {
+			switch r := asInt(rotation, 0, nil, nil); r {
+			case 0, 90, 180, 270:
+				cc.Rotation = r
+			}
+		}
+		if v, ok := ini.get(section, "mirror"); ok {
+			cc.Mirror = asBool(v, false)
+		}
+		if v, ok := ini.get(section, "flip"); ok {
+			cc.Flip = asBool(v, false)
+		}
+		if v, ok := ini.get(section, "motion"); ok {
+			cc.NoMotion = !asBool(v, true)
+		}
+		if v, ok := ini.get(section, "zones"); ok {
+			cc.Zones = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "crop"); ok {
+			cc.Crop = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "filters"); ok {
+			cc.Filters = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "dewarp_k1"); ok {
+			cc.DewarpK1 = asFloat(v, 0, floatPtr(-1), floatPtr(1))
+		}
+		if v, ok := ini.get(section, "dewarp_k2"); ok {
+			cc.DewarpK2 = asFloat(v, 0, floatPtr(-1), floatPtr(1))
+		}
+		if v, ok := ini.get(section, "dewarp_scale"); ok {
+			cc.DewarpScale = asFloat(v, 0, floatPtr(0.25), floatPtr(4))
+		}
+		if v, ok := ini.get(section, "dewarp_file"); ok {
+			cc.DewarpFile = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "slot"); ok {
+			if slot := asInt(v, -1, nil, nil); slot >= 0 && slot < 8 {
+				cc.Slot = slot
+				for len(cfg.CameraSlotPins) <= cc.Slot {
+					cfg.CameraSlotPins = append(cfg.CameraSlotPins, "")
+				}
+				cfg.CameraSlotPins[cc.Slot] = key
+			}
+		}
+		if cfg.Cameras == nil {
+			cfg.Cameras = make(map[string]CameraConfig)
+		}
+		cfg.Cameras[key] = cc
+	}
+}
+
+// triggerSectionPrefix starts the name of a trigger section, e.g. [trigger.reverse].
+const triggerSectionPrefix = "trigger."
+
+// applyTriggerSections parses the trigger sections in sorted order. Sources
+// and actions are checked when the triggers are opened, so a bad section is
+// reported instead of silently dropped.
+func applyTriggerSections(cfg *Config, ini iniData) {
+	var sections []string
+	for section := range ini {
+		if strings.HasPrefix(section, triggerSectionPrefix) &&
+			strings.TrimSpace(strings.TrimPrefix(section, triggerSectionPrefix)) != "" {
+			sections = append(sections, section)
+		}
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		tc := TriggerConfig{
+			Name:       strings.TrimSpace(strings.TrimPrefix(section, triggerSectionPrefix)),
+			Line:       -1,
+			Action:     DefaultTriggerAction,
+			DebounceMS: DefaultTriggerDebounceMS,
+		}
+		if v, ok := ini.get(section, "source"); ok {
+			tc.Source = strings.ToLower(strings.TrimSpace(v))
+		}
+		if v, ok := ini.get(section, "chip"); ok {
+			tc.Chip = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "line"); ok {
+			tc.Line = asInt(v, -1, nil, nil)
+		}
+		if v, ok := ini.get(section, "active_low"); ok {
+			tc.ActiveLow = asBool(v, false)
+		}
+		if v, ok := ini.get(section, "bias"); ok {
+			tc.Bias = strings.ToLower(strings.TrimSpace(v))
+		}
+		if v, ok := ini.get(section, "path"); ok {
+			tc.Path = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "action"); ok && strings.TrimSpace(v) != "" {
+			tc.Action = strings.ToLower(strings.TrimSpace(v))
+		}
+		if v, ok := ini.get(section, "camera"); ok {
+			tc.Camera = strings.TrimSpace(v)
+		}
+		if v, ok := ini.get(section, "debounce_ms"); ok {
+			tc.DebounceMS = asInt(v, tc.DebounceMS, intPtr(0), intPtr(5000))
+		}
+		if v, ok := ini.get(section, "hold_ms"); ok {
+			tc.HoldMS = asInt(v, 0, intPtr(0), intPtr(60000))
+		}
+		cfg.Triggers = append(cfg.Triggers, tc)
+	}
+}
+
 // =============================================================================
 // Profile scaling (choose_profile equivalent)
 // =============================================================================
@@ -212,6 +212,28 @@ baz = qux
 	}
 }
 
+func TestParseINI_CommentsOnlyOnTheirOwnLine(t *testing.T) {
+	tmp := writeTempFile(t, `[camera.1-1.3]
+# Rear bay
+name = Bay #2
+  ; zone list follows
+zones = 0,0 1,0 1,1 ; 0,0 0,1 1,1
+`)
+	ini, err := parseINI(tmp)
+	if err != nil {
+		t.Fatalf("parseINI() error: %v", err)
+	}
+	if val, _ := ini.get("camera.1-1.3", "name"); val != "Bay #2" {
+		t.Errorf("name = %q, want %q", val, "Bay #2")
+	}
+	if val, _ := ini.get("camera.1-1.3", "zones"); val != "0,0 1,0 1,1 ; 0,0 0,1 1,1" {
+		t.Errorf("zones = %q, want ; kept", val)
+	}
+	if n := len(ini["camera.1-1.3"]); n != 2 {
+		t.Errorf("section has %d keys, want 2", n)
+	}
+}
+
 func TestParseINI_EmptyFile(t *testing.T) {
 	tmp := writeTempFile(t, "")
 	ini, err := parseINI(tmp)
@@ -230,6 +252,73 @@ func TestParseINI_MissingFile(t *testing.T) {
 	}
 }
 
+func TestSetValue(t *testing.T) {
+	tmp := writeTempFile(t, `# Dashboard
+[camera.1-1.3]
+role = Rear
+crop = 0,0,1,1
+
+[camera.1-1.4]
+role = Left
+# trailing comment
+
+[motion]
+enabled = true
+`)
+	steps := []struct{ section, key, value string }{
+		{"camera.1-1.3", "crop", "0.1,0,0.8,0.9"}, // Replace
+		{"camera.1-1.4", "crop", "0,0.2,1,0.8"},   // Add to section
+		{"camera.video2", "crop", "0,0,0.5,0.5"},  // New section
+		{"motion", "enabled", ""},                 // Remove
+		{"motion", "missing", ""},                 // Remove nothing
+	}
+	for _, st := range steps {
+		if err := SetValue(tmp, st.section, st.key, st.value); err != nil {
+			t.Fatalf("SetValue(%s, %s): %v", st.section, st.key, err)
+		}
+	}
+
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Dashboard
+[camera.1-1.3]
+role = Rear
+crop = 0.1,0,0.8,0.9
+
+[camera.1-1.4]
+role = Left
+crop = 0,0.2,1,0.8
+# trailing comment
+
+[motion]
+
+[camera.video2]
+crop = 0,0,0.5,0.5
+`
+	if string(data) != want {
+		t.Errorf("file after SetValue:\n%s\nwant:\n%s", data, want)
+	}
+
+	cfg, err := Load(tmp)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Path != tmp || cfg.Cameras["1-1.3"].Crop != "0.1,0,0.8,0.9" || cfg.Cameras["video2"].Crop != "0,0,0.5,0.5" {
+		t.Errorf("Load after SetValue: path %q, cameras %+v", cfg.Path, cfg.Cameras)
+	}
+
+	// A missing file is created
+	fresh := filepath.Join(t.TempDir(), "new.ini")
+	if err := SetValue(fresh, "camera.video0", "crop", "0,0,0.5,1"); err != nil {
+		t.Fatalf("SetValue(new file): %v", err)
+	}
+	if data, _ := os.ReadFile(fresh); string(data) != "[camera.video0]\ncrop = 0,0,0.5,1\n" {
+		t.Errorf("new file = %q", data)
+	}
+}
+
 // =============================================================================
 // Load tests
 // =============================================================================
@@ -277,7 +366,45 @@ kill_device_holders = false
 capture_width = 1280
 capture_height = 720
 capture_fps = 30
+capture_backend = V4L2
 ui_fps = 25
+filters = dewarp, gamma=1.2, nightmode
+
+[recording]
+enabled = yes
+dir = /var/lib/dashcam
+segment_sec = 5
+max_total_mb = 1024
+min_free_mb = 0
+jpeg_quality = 70
+
+[clips]
+enabled = true
+dir = /var/lib/dashcam/clips
+pre_sec = 5
+post_sec = 1000
+ring_mb = 16
+
+[motion]
+enabled = on
+sensitivity = 150
+min_area_pct = 2.5
+check_fps = 0
+hold_sec = 3
+clips = yes
+show_zones = true
+
+[snapshot]
+dir = /var/lib/dashcam/snapshots
+format = PNG
+jpeg_quality = 10
+
+[http]
+enabled = yes
+listen = 127.0.0.1:9000
+max_clients = 0
+max_fps = 12
+api_token = s3cret
 
 [health]
 log_interval_sec = 60
@@ -326,6 +453,12 @@ log_interval_sec = 60
 	if cfg.UIFPS != 25 {
 		t.Errorf("UIFPS = %d, want 25", cfg.UIFPS)
 	}
+	if cfg.DisplayFilters != "dewarp, gamma=1.2, nightmode" {
+		t.Errorf("DisplayFilters = %q", cfg.DisplayFilters)
+	}
+	if cfg.CaptureBackend != "v4l2" {
+		t.Errorf("CaptureBackend = %q, want %q", cfg.CaptureBackend, "v4l2")
+	}
 	if cfg.CameraSlotCount != 4 {
 		t.Errorf("CameraSlotCount = %d, want 4", cfg.CameraSlotCount)
 	}
@@ -341,6 +474,41 @@ log_interval_sec = 60
 	if cfg.MaxRestartsPerWindow != 5 {
 		t.Errorf("MaxRestartsPerWindow = %d, want 5", cfg.MaxRestartsPerWindow)
 	}
+	if !cfg.RecordingEnabled || cfg.RecordingDir != "/var/lib/dashcam" {
+		t.Errorf("Recording = %v %q, want enabled in /var/lib/dashcam", cfg.RecordingEnabled, cfg.RecordingDir)
+	}
+	if cfg.RecordingSegmentSec != 10 {
+		t.Errorf("RecordingSegmentSec = %d, want clamped 10", cfg.RecordingSegmentSec)
+	}
+	if cfg.RecordingMaxTotalMB != 1024 || cfg.RecordingMinFreeMB != 0 || cfg.RecordingJPEGQuality != 70 {
+		t.Errorf("Recording limits = %d MB / %d MB free / q%d, want 1024 / 0 / 70",
+			cfg.RecordingMaxTotalMB, cfg.RecordingMinFreeMB, cfg.RecordingJPEGQuality)
+	}
+	if !cfg.ClipsEnabled || cfg.ClipsDir != "/var/lib/dashcam/clips" || cfg.ClipRingMB != 16 {
+		t.Errorf("Clips = %v %q %d MB, want enabled in /var/lib/dashcam/clips with 16 MB", cfg.ClipsEnabled, cfg.ClipsDir, cfg.ClipRingMB)
+	}
+	if cfg.ClipPreSec != 5 || cfg.ClipPostSec != 300 {
+		t.Errorf("Clip pre/post = %d/%d, want 5/300 (clamped)", cfg.ClipPreSec, cfg.ClipPostSec)
+	}
+	if !cfg.MotionEnabled || cfg.MotionSensitivity != 100 || cfg.MotionMinAreaPct != 2.5 ||
+		cfg.MotionCheckFPS != 1 || cfg.MotionHoldSec != 3 || !cfg.MotionClips || !cfg.MotionShowZones {
+		t.Errorf("Motion = %v sens=%d area=%.1f fps=%d hold=%.0f clips=%v, want enabled 100/2.5/1 (clamped)/3 with clips",
+			cfg.MotionEnabled, cfg.MotionSensitivity, cfg.MotionMinAreaPct, cfg.MotionCheckFPS, cfg.MotionHoldSec, cfg.MotionClips)
+	}
+	if cfg.SnapshotDir != "/var/lib/dashcam/snapshots" || cfg.SnapshotFormat != "png" || cfg.SnapshotJPEGQuality != 30 {
+		t.Errorf("Snapshot = %q %q q%d, want /var/lib/dashcam/snapshots png q30",
+			cfg.SnapshotDir, cfg.SnapshotFormat, cfg.SnapshotJPEGQuality)
+	}
+	if !cfg.HTTPEnabled || cfg.HTTPListen != "127.0.0.1:9000" || cfg.HTTPMaxClients != 1 || cfg.HTTPMaxFPS != 12 {
+		t.Errorf("HTTP = %v %q clients=%d fps=%d, want enabled on 127.0.0.1:9000 with 1 client at 12 FPS",
+			cfg.HTTPEnabled, cfg.HTTPListen, cfg.HTTPMaxClients, cfg.HTTPMaxFPS)
+	}
+	if cfg.HTTPJPEGQuality != 80 {
+		t.Errorf("HTTPJPEGQuality = %d, want default 80", cfg.HTTPJPEGQuality)
+	}
+	if cfg.HTTPAPIToken != "s3cret" {
+		t.Errorf("HTTPAPIToken = %q, want s3cret", cfg.HTTPAPIToken)
+	}
 }
 
 func TestLoad_PartialINI(t *testing.T) {
@@ -369,6 +537,175 @@ capture_fps = 15
 	}
 }
 
+func TestLoad_SlotPins(t *testing.T) {
+	content := `
+[slots]
+0 = 1-1.3
+2 = 046d:0825
+bogus = 1-1.4
+9 = 1-1.5
+`
+	tmp := writeTempFile(t, content)
+
+	cfg, err := Load(tmp)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	want := []string{"1-1.3", "", "046d:0825"}
+	if len(cfg.CameraSlotPins) != len(want) {
+		t.Fatalf("CameraSlotPins = %q, want %q", cfg.CameraSlotPins, want)
+	}
+	for i := range want {
+		if cfg.CameraSlotPins[i] != want[i] {
+			t.Errorf("CameraSlotPins[%d] = %q, want %q", i, cfg.CameraSlotPins[i], want[i])
+		}
+	}
+}
+
+func TestLoad_CameraSections(t *testing.T) {
+	content := `
+[slots]
+1 = 046d:0825
+
+[camera.1-1.3]
+width = 1280
+height = 720
+fps = 15
+format = YUYV
+name = Rear Cam
+role = Rear
+rotation = 180
+mirror = yes
+slot = 1
+motion = off
+zones = 0.5,0 1,0 1,1 ; 0,0 0.1,0 0,0.1
+
+[camera.046d:0825]
+width = 99999
+rotation = 45
+format = h264
+slot = -1
+
+[camera.1-1.4]
+rotate = 270
+flip = on
+dewarp_file = side.cal
+filters = dewarp, mirror
+dewarp_k1 = -0.35
+dewarp_scale = 10
+`
+	tmp := writeTempFile(t, content)
+
+	cfg, err := Load(tmp)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	rear, ok := cfg.Cameras["1-1.3"]
+	if !ok {
+		t.Fatalf("Cameras = %+v, want entry for 1-1.3", cfg.Cameras)
+	}
+	want := CameraConfig{Width: 1280, Height: 720, FPS: 15, Format: "yuyv", Name: "Rear Cam", Role: "Rear", Rotation: 180, Mirror: true, Slot: 1, NoMotion: true, Zones: "0.5,0 1,0 1,1 ; 0,0 0.1,0 0,0.1"}
+	if rear != want {
+		t.Errorf("Cameras[1-1.3] = %+v, want %+v", rear, want)
+	}
+
+	other := cfg.Cameras["046d:0825"]
+	if other.Width != 1920 || other.Rotation != 0 || other.Format != "" || other.Slot != -1 || other.NoMotion {
+		t.Errorf("Cameras[046d:0825] = %+v, want clamped width, no rotation/format/slot", other)
+	}
+	if side := cfg.Cameras["1-1.4"]; side.Rotation != 270 || !side.Flip || side.Mirror {
+		t.Errorf("Cameras[1-1.4] = %+v, want rotate alias 270 and flip", side)
+	}
+	if side := cfg.Cameras["1-1.4"]; side.DewarpFile != "side.cal" || side.DewarpK1 != -0.35 || side.DewarpK2 != 0 || side.DewarpScale != 4 || side.Filters != "dewarp, mirror" {
+		t.Errorf("Cameras[1-1.4] = %+v, want dewarp file, k1, clamped scale and filters", side)
+	}
+
+	// slot key in a camera section overrides [slots]
+	if len(cfg.CameraSlotPins) != 2 || cfg.CameraSlotPins[1] != "1-1.3" {
+		t.Errorf("CameraSlotPins = %q, want slot 1 pinned to 1-1.3", cfg.CameraSlotPins)
+	}
+}
+
+func TestLoad_Guides(t *testing.T) {
+	content := `
+[guides]
+enabled = true
+camera = 2
+near_y = 1.5
+far_width = 0.1
+bands = 0.2, 0.4, 0.8
+steering_path = /run/steering
+`
+	tmp := writeTempFile(t, content)
+
+	cfg, err := Load(tmp)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !cfg.GuidesEnabled || cfg.GuidesCamera != "2" || cfg.GuidesNearY != 1 || cfg.GuidesFarWidth != 0.1 ||
+		cfg.GuidesCenterX != 0.5 || cfg.GuidesSteeringPath != "/run/steering" {
+		t.Errorf("guides = %v %q near_y=%v far_width=%v center=%v path=%q", cfg.GuidesEnabled, cfg.GuidesCamera,
+			cfg.GuidesNearY, cfg.GuidesFarWidth, cfg.GuidesCenterX, cfg.GuidesSteeringPath)
+	}
+	if len(cfg.GuidesBands) != 3 || cfg.GuidesBands[2] != 0.8 {
+		t.Errorf("GuidesBands = %v, want [0.2 0.4 0.8]", cfg.GuidesBands)
+	}
+
+	for _, bad := range []string{"0.5, 0.3", "0.2, 1", "a"} {
+		cfg, err := Load(writeTempFile(t, "[guides]\nbands = "+bad+"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cfg.GuidesBands) != 2 || cfg.GuidesBands[0] != 0.25 {
+			t.Errorf("bands = %q: GuidesBands = %v, want the default", bad, cfg.GuidesBands)
+		}
+	}
+}
+
+func TestLoad_TriggerSections(t *testing.T) {
+	content := `
+[trigger.reverse]
+source = GPIO
+chip = gpiochip0
+line = 17
+active_low = yes
+bias = pull-up
+camera = Rear
+
+[trigger.left]
+source = file
+path = /run/left
+camera = 1
+debounce_ms = 99999
+hold_ms = 1200
+
+[trigger.door]
+source = api
+action = Clip
+`
+	tmp := writeTempFile(t, content)
+
+	cfg, err := Load(tmp)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	want := []TriggerConfig{
+		{Name: "door", Source: "api", Line: -1, Action: "clip", DebounceMS: DefaultTriggerDebounceMS},
+		{Name: "left", Source: "file", Line: -1, Path: "/run/left", Action: "fullscreen", Camera: "1", DebounceMS: 5000, HoldMS: 1200},
+		{Name: "reverse", Source: "gpio", Chip: "gpiochip0", Line: 17, ActiveLow: true, Bias: "pull-up", Action: "fullscreen", Camera: "Rear", DebounceMS: DefaultTriggerDebounceMS},
+	}
+	if len(cfg.Triggers) != len(want) {
+		t.Fatalf("Triggers = %+v, want %d sorted by name", cfg.Triggers, len(want))
+	}
+	for i := range want {
+		if cfg.Triggers[i] != want[i] {
+			t.Errorf("Triggers[%d] = %+v, want %+v", i, cfg.Triggers[i], want[i])
+		}
+	}
+}
+
 func TestLoad_EnvVarOverride(t *testing.T) {
 	content := `
 [logging]
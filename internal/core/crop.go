@@ -0,0 +1,88 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/config"
+	"fmt"
+	"log"
+)
+
+// =============================================================================
+// Crop
+// =============================================================================
+// A camera's "crop" key keeps part of its frame (after mirror/flip/rotate),
+// e.g. to cut away sky and bumper, for the display, motion detection and
+// recordings alike. The UI zooms and pans within that picture in
+// fullscreen and can keep the view as the new crop: SetCrop applies it to
+// the running capture worker at once and optionally writes it back to the
+// camera's [camera.<id>] section of the config file.
+// =============================================================================
+
+// Crop returns the crop of the camera in slot; the zero value is the full frame.
+func (s *Service) Crop(slot int) camera.Crop {
+	cam, ok := s.Camera(slot)
+	if !ok {
+		return camera.Crop{}
+	}
+	if m := s.Manager(); m != nil {
+		if w := m.GetWorker(cam.DeviceID); w != nil {
+			return w.Crop()
+		}
+	}
+	return s.cameraSettings().ForCamera(cam).Crop
+}
+
+// SetCrop sets the crop of the camera in slot (zero value = full frame).
+// It lasts while the service runs, across restarts of the camera; with
+// persist it is also saved to the config file.
+func (s *Service) SetCrop(slot int, c camera.Crop, persist bool) error {
+	if slot < 0 || slot >= s.slots {
+		return fmt.Errorf("camera slot %d out of range (0-%d)", slot, s.slots-1)
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	cam, ok := s.Camera(slot)
+	if !ok || cam.DeviceID == "" {
+		return fmt.Errorf("no camera in slot %d", slot)
+	}
+	if c.IsFull() {
+		c = camera.Crop{}
+	}
+
+	// Reuse the camera's [camera.<id>] section, else start one by port path
+	key, ok := s.cameraSettings().OverrideKey(cam)
+	if !ok {
+		key = cam.Identity.PortPath
+		if key == "" {
+			key = cam.DeviceID
+		}
+	}
+	s.cropMu.Lock()
+	s.crops[key] = c
+	s.cropMu.Unlock()
+
+	if m := s.Manager(); m != nil {
+		if w := m.GetWorker(cam.DeviceID); w != nil {
+			w.SetCrop(c)
+		}
+	}
+	label := cam.Label(slot)
+	if c.IsFull() {
+		log.Printf("[Core] %s: crop cleared", label)
+	} else {
+		log.Printf("[Core] %s: crop set to %s", label, c)
+	}
+
+	if !persist {
+		return nil
+	}
+	if s.cfg.Path == "" {
+		return fmt.Errorf("no config file to save the crop to")
+	}
+	if err := config.SetValue(s.cfg.Path, "camera."+key, "crop", c.String()); err != nil {
+		return fmt.Errorf("save crop: %w", err)
+	}
+	log.Printf("[Core] %s: crop saved to [camera.%s] in %s", label, key, s.cfg.Path)
+	return nil
+}
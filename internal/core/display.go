@@ -0,0 +1,65 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/dewarp"
+	"log"
+	"path/filepath"
+)
+
+// =============================================================================
+// Display Filters
+// =============================================================================
+// The UI runs each slot's frames through a filter.Chain built from the
+// camera's "filters" key, else [profile] filters. Its dewarp filter reads
+// the lens from the camera's dewarp_file / dewarp_k1 / dewarp_k2 /
+// dewarp_scale keys. Both are looked up again whenever cameras change slots.
+// =============================================================================
+
+// Filters returns the display filter spec of the camera in slot ("" = the
+// default chain).
+func (s *Service) Filters(slot int) string {
+	if cam, ok := s.Camera(slot); ok && cam.DeviceID != "" {
+		if o, ok := s.cameraSettings().OverrideFor(cam); ok && o.Filters != "" {
+			return o.Filters
+		}
+	}
+	return s.cfg.DisplayFilters
+}
+
+// Dewarp returns the lens correction of the camera in slot; the zero value
+// (no camera, or no dewarp keys) corrects nothing. A relative dewarp_file
+// is looked up next to the config file.
+func (s *Service) Dewarp(slot int) dewarp.Params {
+	cam, ok := s.Camera(slot)
+	if !ok || cam.DeviceID == "" {
+		return dewarp.Params{}
+	}
+	o, ok := s.cameraSettings().OverrideFor(cam)
+	if !ok {
+		return dewarp.Params{}
+	}
+
+	var p dewarp.Params
+	if o.DewarpFile != "" {
+		path := o.DewarpFile
+		if !filepath.IsAbs(path) && s.cfg.Path != "" {
+			path = filepath.Join(filepath.Dir(s.cfg.Path), path)
+		}
+		loaded, err := dewarp.LoadFile(path)
+		if err != nil {
+			log.Printf("[Core] %s: ignoring dewarp file: %v", cam.Label(slot), err)
+		} else {
+			p = loaded
+		}
+	}
+	if o.DewarpK1 != 0 {
+		p.K1 = o.DewarpK1
+	}
+	if o.DewarpK2 != 0 {
+		p.K2 = o.DewarpK2
+	}
+	if o.DewarpScale != 0 {
+		p.Scale = o.DewarpScale
+	}
+	return p
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/guides"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// Parking Guides
+// =============================================================================
+// With [guides] enabled, the UI draws guides.Lines over the [guides] camera
+// on its tile and in fullscreen. A steering value from -1 (full left) to 1
+// (full right) bends them; it is set through POST /api/steering, or read
+// from steering_path (e.g. a file a CAN bus reader keeps up to date).
+// =============================================================================
+
+// steeringPollInterval is how often steering_path is read.
+const steeringPollInterval = 100 * time.Millisecond
+
+// steeringEpsilon is the smallest steering change that redraws the guides.
+const steeringEpsilon = 0.005
+
+// SetGuidesHandler registers fn to be called when the guide lines change
+// shape (steering). Call before Start.
+func (s *Service) SetGuidesHandler(fn func()) {
+	s.onGuides = fn
+}
+
+// GuideLines returns the slot of the [guides] camera and its guide lines
+// for the current steering, or -1 when guides are off or the camera is
+// missing.
+func (s *Service) GuideLines() (int, []guides.Segment) {
+	if !s.cfg.GuidesEnabled {
+		return -1, nil
+	}
+	slot := s.findCamera(s.cfg.GuidesCamera)
+	if slot < 0 {
+		return -1, nil
+	}
+	return slot, guides.Lines(guides.Config{
+		CenterX:   s.cfg.GuidesCenterX,
+		NearY:     s.cfg.GuidesNearY,
+		FarY:      s.cfg.GuidesFarY,
+		NearWidth: s.cfg.GuidesNearWidth,
+		FarWidth:  s.cfg.GuidesFarWidth,
+		Bands:     s.cfg.GuidesBands,
+		Bend:      s.cfg.GuidesBend,
+	}, s.Steering())
+}
+
+// Steering returns the current steering value (-1..1).
+func (s *Service) Steering() float64 {
+	s.guidesMu.Lock()
+	defer s.guidesMu.Unlock()
+	return s.steering
+}
+
+// SetSteering sets the steering value, from -1 (full left) to 1 (full right).
+func (s *Service) SetSteering(value float64) error {
+	if math.IsNaN(value) || value < -1 || value > 1 {
+		return fmt.Errorf("steering %v out of range (-1 to 1)", value)
+	}
+	s.setSteering(value)
+	return nil
+}
+
+func (s *Service) setSteering(value float64) {
+	s.guidesMu.Lock()
+	changed := math.Abs(value-s.steering) >= steeringEpsilon
+	if changed {
+		s.steering = value
+	}
+	s.guidesMu.Unlock()
+	if changed && s.onGuides != nil {
+		s.onGuides()
+	}
+}
+
+// runSteering reads steering_path until Stop.
+func (s *Service) runSteering() {
+	path := s.cfg.GuidesSteeringPath
+	if !s.cfg.GuidesEnabled || path == "" {
+		return
+	}
+	log.Printf("[Guides] Reading steering from %s", path)
+
+	ticker := time.NewTicker(steeringPollInterval)
+	defer ticker.Stop()
+	lastErr := ""
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			value, err := readSteering(path)
+			if err != nil {
+				if msg := err.Error(); msg != lastErr {
+					log.Printf("[Guides] Steering: %v", err)
+					lastErr = msg
+				}
+				continue
+			}
+			lastErr = ""
+			s.setSteering(value)
+		}
+	}
+}
+
+// readSteering reads a steering value from path, clamped to -1..1.
+func readSteering(path string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil || math.IsNaN(value) {
+		return 0, fmt.Errorf("%s: not a number: %q", path, strings.TrimSpace(string(data)))
+	}
+	return math.Max(-1, math.Min(1, value)), nil
+}
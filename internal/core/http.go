@@ -0,0 +1,172 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/recording"
+	"camera-dashboard-go/internal/server"
+	"log"
+	"strconv"
+)
+
+// startHTTPServer starts the embedded HTTP server when [http] is enabled.
+func (s *Service) startHTTPServer() {
+	if !s.cfg.HTTPEnabled {
+		return
+	}
+	srv := server.New(server.Config{
+		Addr:        s.cfg.HTTPListen,
+		JPEGQuality: s.cfg.HTTPJPEGQuality,
+		MaxClients:  s.cfg.HTTPMaxClients,
+		MaxFPS:      s.cfg.HTTPMaxFPS,
+		APIToken:    s.cfg.HTTPAPIToken,
+	}, httpSource{s})
+	srv.EnableAPI(s.controller)
+	srv.EnableMetrics(metricsCollector{s})
+	srv.EnableEvents(s.events)
+	if err := srv.Start(); err != nil {
+		log.Printf("[Core] HTTP server disabled: %v", err)
+		return
+	}
+	s.httpServer = srv
+}
+
+// stopHTTPServer closes the HTTP server and all open streams.
+func (s *Service) stopHTTPServer() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// httpSource exposes the current camera manager to the HTTP server. The
+// manager is replaced on hotplug, so it is looked up on every call.
+type httpSource struct {
+	s *Service
+}
+
+func (h httpSource) Cameras() []camera.Camera {
+	if m := h.s.Manager(); m != nil {
+		return m.GetCameras()
+	}
+	return nil
+}
+
+func (h httpSource) FrameBuffer(deviceID string) *camera.FrameBuffer {
+	if m := h.s.Manager(); m != nil {
+		return m.GetFrameBuffer(deviceID)
+	}
+	return nil
+}
+
+func (h httpSource) FPS() int { return h.s.CaptureFPS() }
+
+// Controller implements the REST API for a service without a display. The
+// UI embeds it and adds the settings-tile actions (server.DisplayController).
+type Controller struct {
+	s *Service
+}
+
+// NewController returns the API controller for s.
+func NewController(s *Service) Controller {
+	return Controller{s}
+}
+
+// State reports the cameras and the FPS controller; the display fields keep
+// their defaults (no grid, no fullscreen, night mode off, 100% brightness).
+func (c Controller) State() server.State {
+	s := c.s
+	st := server.State{
+		Grid:       []int{},
+		Fullscreen: -1,
+		Brightness: 100,
+	}
+
+	m := s.Manager()
+	for i, cam := range s.Cameras() {
+		cs := server.CameraState{
+			Slot:       i,
+			Label:      DisplayName(cam, i),
+			Role:       cam.Role,
+			Name:       cam.Name,
+			DeviceID:   cam.DeviceID,
+			DevicePath: cam.DevicePath,
+			Connected:  s.Connected(i),
+
+			MotionDetection: s.MotionDetection(i),
+			Motion:          s.Motion(i),
+		}
+		if !cam.Identity.IsZero() {
+			cs.Identity = cam.Identity.String()
+		}
+		if m != nil {
+			if fb := m.GetFrameBuffer(cam.DeviceID); fb != nil {
+				cs.CaptureFPS = fb.GetActualFPS()
+			}
+		}
+		st.Cameras = append(st.Cameras, cs)
+	}
+
+	st.Steering = s.Steering()
+	st.Triggers = []server.TriggerState{}
+	for _, t := range s.Triggers() {
+		st.Triggers = append(st.Triggers, server.TriggerState{
+			Name:   t.Name,
+			Source: t.Source,
+			Action: t.Action,
+			Camera: t.Camera,
+			Active: t.Active,
+		})
+	}
+
+	if pc := s.PerfController(); pc != nil {
+		st.FPS = server.FPSState{
+			Current:   pc.GetCurrentFPS(),
+			SweetSpot: pc.GetSweetSpotFPS(),
+			State:     pc.GetState(),
+			Dynamic:   pc.IsDynamic(),
+		}
+	} else {
+		st.FPS.Current = s.cfg.CaptureFPS
+	}
+	return st
+}
+
+func (c Controller) Restart() { c.s.Restart() }
+
+func (c Controller) Exit() { c.s.Stop() }
+
+func (c Controller) Snapshot(camIndex int) ([]string, error) {
+	return c.s.Snapshot(camIndex)
+}
+
+// SaveClip accepts a slot number as well as the names Service.SaveClip matches.
+func (c Controller) SaveClip(name string) int {
+	if slot, err := strconv.Atoi(name); err == nil {
+		cam, ok := c.s.Camera(slot)
+		if !ok {
+			return 0
+		}
+		name = cam.DeviceID
+	}
+	return c.s.SaveClip(name, recording.TriggerAPI, "REST API")
+}
+
+func (c Controller) SetMotionDetection(slot int, enabled bool) error {
+	return c.s.SetMotionDetection(slot, enabled)
+}
+
+func (c Controller) SetTrigger(name string, active bool) error {
+	return c.s.SetTrigger(name, active)
+}
+
+func (c Controller) SetSteering(value float64) error {
+	return c.s.SetSteering(value)
+}
+
+// DisplayName returns the on-screen label of a camera: its role, else its
+// device name, else "Camera <index>".
+func DisplayName(cam camera.Camera, camIndex int) string {
+	if cam.Role == "" && cam.Name != "" {
+		return cam.Name
+	}
+	return cam.Label(camIndex)
+}
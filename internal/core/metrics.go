@@ -0,0 +1,99 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/helpers"
+	"camera-dashboard-go/internal/perf"
+	"camera-dashboard-go/internal/server"
+	"camera-dashboard-go/internal/supervisor"
+	"strconv"
+	"time"
+)
+
+// metricsCollector exports the health data otherwise only logged (health
+// summary, frame/drop counters, capture errors, restarts, thermals and the
+// FPS controller) for the HTTP server's /metrics endpoint.
+type metricsCollector struct {
+	s *Service
+}
+
+func (c metricsCollector) CollectMetrics(m *server.Metrics) {
+	s := c.s
+	now := time.Now()
+
+	// Global camera health, same classification as the health summary
+	counts := map[string]int{supervisor.HealthOnline: 0, supervisor.HealthStale: 0, supervisor.HealthDisconnected: 0}
+	slots := helpers.MinInt(s.cfg.CameraSlotCount, s.slots)
+	statuses := make([]string, slots)
+	ages := make([]time.Duration, slots)
+	for i := 0; i < slots; i++ {
+		statuses[i], ages[i] = s.supervisor.CameraHealth(i, now)
+		counts[statuses[i]]++
+	}
+	for _, status := range []string{supervisor.HealthOnline, supervisor.HealthStale, supervisor.HealthDisconnected} {
+		m.Add("camera_dashboard_cameras", "Camera slots by health status.", server.Gauge,
+			float64(counts[status]), "status", status)
+	}
+	m.Add("camera_dashboard_camera_slots", "Configured camera slots.", server.Gauge, float64(s.cfg.CameraSlotCount))
+
+	// FPS controller and system monitor
+	if pc := s.PerfController(); pc != nil {
+		m.Add("camera_dashboard_fps_current", "Capture FPS currently applied by the controller.", server.Gauge, float64(pc.GetCurrentFPS()))
+		m.Add("camera_dashboard_fps_sweet_spot", "Best known stable capture FPS.", server.Gauge, float64(pc.GetSweetSpotFPS()))
+		m.AddBool("camera_dashboard_fps_dynamic", "1 if dynamic FPS adaptation is enabled.", pc.IsDynamic())
+		state := pc.GetState()
+		for _, name := range perf.StateNames() {
+			m.AddBool("camera_dashboard_fps_controller_state", "FPS controller state (1 = current).", name == state, "state", name)
+		}
+		mon := pc.GetMonitor()
+		m.Add("camera_dashboard_cpu_temperature_celsius", "CPU temperature.", server.Gauge, mon.GetTemperature())
+		m.Add("camera_dashboard_cpu_load_ratio", "1-minute load average per CPU (capped at 1).", server.Gauge, mon.GetLoadAverage())
+		m.Add("camera_dashboard_memory_used_ratio", "Fraction of memory in use.", server.Gauge, mon.GetMemoryUsage()/100)
+	}
+
+	if s.recorder != nil {
+		m.Add("camera_dashboard_recording_dropped_frames_total", "Frames the loop recorder dropped because its queue was full.",
+			server.Counter, float64(s.recorder.DroppedFrames()))
+	}
+
+	// Per-camera metrics
+	cams := s.Cameras()
+	recent, totals := s.supervisor.RestartCounts()
+	manager := s.Manager()
+
+	for i := 0; i < slots; i++ {
+		labels := []string{"slot", strconv.Itoa(i), "camera", s.supervisor.CameraLabel(i)}
+		m.AddBool("camera_dashboard_camera_connected", "1 if the camera is connected.", statuses[i] != supervisor.HealthDisconnected, labels...)
+		m.AddBool("camera_dashboard_camera_stale", "1 if a connected camera has not produced a frame within the stale timeout.",
+			statuses[i] == supervisor.HealthStale, labels...)
+		if ages[i] >= 0 {
+			m.Add("camera_dashboard_camera_last_frame_age_seconds", "Time since the camera's last captured frame.",
+				server.Gauge, ages[i].Seconds(), labels...)
+		}
+		m.Add("camera_dashboard_camera_restarts_in_window", "Capture restarts within the restart window.",
+			server.Gauge, float64(recent[i]), labels...)
+		m.Add("camera_dashboard_camera_restarts_total", "Capture restarts after stale frames since startup.",
+			server.Counter, float64(totals[i]), labels...)
+
+		if i >= len(cams) || !cams[i].Available || manager == nil {
+			continue
+		}
+		id := cams[i].DeviceID
+		if fb := manager.GetFrameBuffer(id); fb != nil {
+			m.Add("camera_dashboard_camera_frames_total", "Frames written to the camera's frame buffer.",
+				server.Counter, float64(fb.GetFrameCount()), labels...)
+			m.Add("camera_dashboard_camera_dropped_frames_total", "Frames the frame buffer dropped.",
+				server.Counter, float64(fb.GetDroppedCount()), labels...)
+			m.Add("camera_dashboard_camera_capture_fps", "Measured capture FPS.",
+				server.Gauge, fb.GetActualFPS(), labels...)
+		}
+		if w := manager.GetWorker(id); w != nil {
+			_, _, errs := w.GetStats()
+			m.Add("camera_dashboard_camera_errors_total", "Capture errors reported by the worker.",
+				server.Counter, float64(errs), labels...)
+			m.Add("camera_dashboard_camera_skipped_frames_total", "Frames skipped to honor the target FPS.",
+				server.Counter, float64(w.GetSkippedFrames()), labels...)
+			m.Add("camera_dashboard_camera_target_fps", "Target FPS of the capture worker.",
+				server.Gauge, float64(w.GetFPS()), labels...)
+		}
+	}
+}
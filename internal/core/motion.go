@@ -0,0 +1,271 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/events"
+	"camera-dashboard-go/internal/motion"
+	"camera-dashboard-go/internal/recording"
+	"fmt"
+	"log"
+	"time"
+)
+
+// =============================================================================
+// Motion Detection
+// =============================================================================
+// Frames are sampled from each camera's FrameBuffer at [motion] check_fps and
+// compared by a motion.Detector. Motion stays flagged until no change was
+// seen for hold_sec, so the tile border does not flicker between frames.
+// Detection is on for every camera when [motion] enabled is set, except
+// cameras with "motion = off" in their [camera.<id>] section; the REST API
+// can toggle it per slot until another camera takes the slot. A camera's
+// "zones" key restricts detection to polygons (e.g. the adjacent lane, not
+// the vehicle's own body); the UI edits them on the fullscreen view.
+// =============================================================================
+
+// motionSlot is the detector state of one slot, owned by the motion loop.
+type motionSlot struct {
+	detector   *motion.Detector
+	deviceID   string              // Camera the state belongs to
+	buffer     *camera.FrameBuffer // Buffer lastRead counts in
+	lastRead   uint64
+	lastMotion time.Time
+	zonesGen   uint64 // motionZonesGen the detector's zones belong to
+}
+
+// SetMotionHandler registers fn to be called when motion starts (active)
+// or ends on a camera slot. Call before Start.
+func (s *Service) SetMotionHandler(fn func(slot int, active bool)) {
+	s.onMotion = fn
+}
+
+// SetZonesHandler registers fn to be called when the detection zones of a
+// slot change, including when another camera takes the slot. Call before
+// Start.
+func (s *Service) SetZonesHandler(fn func(slot int, zones []motion.Polygon)) {
+	s.onZones = fn
+}
+
+// MotionZones returns the detection zones of slot; nil means the whole frame.
+func (s *Service) MotionZones(slot int) []motion.Polygon {
+	zones, _ := s.motionZonesOf(slot)
+	return zones
+}
+
+// SetMotionZones replaces the detection zones of slot (nil = whole frame)
+// until another camera takes the slot.
+func (s *Service) SetMotionZones(slot int, zones []motion.Polygon) error {
+	if slot < 0 || slot >= s.slots {
+		return fmt.Errorf("camera slot %d out of range (0-%d)", slot, s.slots-1)
+	}
+	for i, poly := range zones {
+		if len(poly) < 3 {
+			return fmt.Errorf("zone %d: need at least 3 points, got %d", i+1, len(poly))
+		}
+	}
+	s.setZones(slot, zones)
+	log.Printf("[Motion] %s: zones set to %q", s.supervisor.CameraLabel(slot), motion.FormatZones(zones))
+	return nil
+}
+
+// setZones stores a copy of zones for slot and reports the change.
+func (s *Service) setZones(slot int, zones []motion.Polygon) {
+	var cp []motion.Polygon
+	for _, poly := range zones {
+		cp = append(cp, append(motion.Polygon(nil), poly...))
+	}
+	s.motionMu.Lock()
+	s.motionZones[slot] = cp
+	s.motionZonesGen[slot]++
+	s.motionMu.Unlock()
+	if s.onZones != nil {
+		s.onZones(slot, s.MotionZones(slot))
+	}
+}
+
+// motionZonesOf returns a copy of the zones of slot and their generation.
+func (s *Service) motionZonesOf(slot int) ([]motion.Polygon, uint64) {
+	s.motionMu.Lock()
+	defer s.motionMu.Unlock()
+	if slot < 0 || slot >= len(s.motionZones) {
+		return nil, 0
+	}
+	var zones []motion.Polygon
+	for _, poly := range s.motionZones[slot] {
+		zones = append(zones, append(motion.Polygon(nil), poly...))
+	}
+	return zones, s.motionZonesGen[slot]
+}
+
+// MotionDetection reports whether motion detection runs for slot.
+func (s *Service) MotionDetection(slot int) bool {
+	s.motionMu.Lock()
+	defer s.motionMu.Unlock()
+	return slot >= 0 && slot < len(s.motionEnabled) && s.motionEnabled[slot]
+}
+
+// Motion reports whether motion is currently flagged for slot.
+func (s *Service) Motion(slot int) bool {
+	s.motionMu.Lock()
+	defer s.motionMu.Unlock()
+	return slot >= 0 && slot < len(s.motionActive) && s.motionActive[slot]
+}
+
+// SetMotionDetection turns motion detection for slot on or off. The setting
+// lasts until another camera takes the slot.
+func (s *Service) SetMotionDetection(slot int, enabled bool) error {
+	if slot < 0 || slot >= s.slots {
+		return fmt.Errorf("camera slot %d out of range (0-%d)", slot, s.slots-1)
+	}
+	s.motionMu.Lock()
+	changed := s.motionEnabled[slot] != enabled
+	s.motionEnabled[slot] = enabled
+	s.motionMu.Unlock()
+	if changed {
+		log.Printf("[Motion] %s: detection %s", s.supervisor.CameraLabel(slot), onOff(enabled))
+	}
+	if !enabled {
+		s.setMotion(slot, false, 0)
+	}
+	return nil
+}
+
+// runMotionDetection checks the cameras for motion until Stop.
+func (s *Service) runMotionDetection() {
+	fps := s.cfg.MotionCheckFPS
+	if fps < 1 {
+		fps = 1
+	}
+	if s.cfg.MotionEnabled {
+		log.Printf("[Motion] Starting motion detection (%d checks/s, sensitivity %d, min area %.1f%%)",
+			fps, s.cfg.MotionSensitivity, s.cfg.MotionMinAreaPct)
+	}
+
+	settings := s.cameraSettings()
+	slots := make([]motionSlot, s.slots)
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.checkMotion(slots, settings, now)
+		}
+	}
+}
+
+// checkMotion runs the detectors on the newest frame of each enabled slot.
+func (s *Service) checkMotion(slots []motionSlot, settings camera.Settings, now time.Time) {
+	m := s.Manager()
+	if m == nil {
+		return
+	}
+	cams := s.Cameras()
+	hold := time.Duration(s.cfg.MotionHoldSec * float64(time.Second))
+
+	for i := range slots {
+		st := &slots[i]
+		var cam camera.Camera
+		if i < len(cams) {
+			cam = cams[i]
+		}
+
+		// A different camera in the slot starts over with its configured default
+		if cam.DeviceID != st.deviceID {
+			*st = motionSlot{deviceID: cam.DeviceID}
+			o, _ := settings.OverrideFor(cam)
+			s.motionMu.Lock()
+			s.motionEnabled[i] = s.cfg.MotionEnabled && cam.DeviceID != "" && !o.NoMotion
+			s.motionMu.Unlock()
+			s.setMotion(i, false, 0)
+
+			zones, err := motion.ParseZones(o.Zones)
+			if err != nil {
+				log.Printf("[Motion] %s: ignoring invalid zones %q: %v", cam.Label(i), o.Zones, err)
+				zones = nil
+			}
+			s.setZones(i, zones)
+		}
+
+		if cam.DeviceID == "" || !s.MotionDetection(i) || !s.Connected(i) {
+			st.detector = nil
+			s.setMotion(i, false, 0)
+			continue
+		}
+
+		fb := m.GetFrameBuffer(cam.DeviceID)
+		if fb == nil {
+			continue
+		}
+		if fb != st.buffer {
+			// Restarted or replaced manager: frame counts start over
+			st.buffer = fb
+			st.lastRead = 0
+			st.detector = nil
+		}
+		frame, frameNum, hasNew := fb.ReadIfNew(st.lastRead)
+		if !hasNew || frame == nil {
+			continue
+		}
+		st.lastRead = frameNum
+
+		zones, gen := s.motionZonesOf(i)
+		if st.detector == nil {
+			st.detector = motion.NewDetector(motion.Config{
+				Sensitivity: s.cfg.MotionSensitivity,
+				MinAreaPct:  s.cfg.MotionMinAreaPct,
+				Zones:       zones,
+			})
+		} else if gen != st.zonesGen {
+			st.detector.SetZones(zones)
+		}
+		st.zonesGen = gen
+		r := st.detector.Detect(frame)
+		if r.Motion {
+			st.lastMotion = now
+			s.setMotion(i, true, r.ChangedPct)
+		} else if now.Sub(st.lastMotion) >= hold {
+			s.setMotion(i, false, 0)
+		}
+	}
+}
+
+// setMotion flags or clears motion on slot and reports changes.
+func (s *Service) setMotion(slot int, active bool, changedPct float64) {
+	s.motionMu.Lock()
+	changed := s.motionActive[slot] != active
+	s.motionActive[slot] = active
+	s.motionMu.Unlock()
+	if !changed {
+		return
+	}
+
+	label := s.supervisor.CameraLabel(slot)
+	if active {
+		log.Printf("[Motion] %s: motion detected (%.1f%% of frame changed)", label, changedPct)
+		ev := events.Camera(events.MotionStart, slot, label, "")
+		ev.Data = map[string]interface{}{"changed_pct": changedPct}
+		s.events.Publish(ev)
+		if s.cfg.MotionClips {
+			if cam, ok := s.Camera(slot); ok {
+				s.SaveClip(cam.DeviceID, recording.TriggerMotion, fmt.Sprintf("%.1f%% changed", changedPct))
+			}
+		}
+	} else {
+		log.Printf("[Motion] %s: motion ended", label)
+		s.events.Publish(events.Camera(events.MotionEnd, slot, label, ""))
+	}
+
+	if s.onMotion != nil {
+		s.onMotion(slot, active)
+	}
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,544 @@
+// Package core runs the camera dashboard's services without a display: the
+// camera manager and its supervisor (stale-frame restarts, hotplug, health
+// logging), the adaptive FPS controller, motion detection, triggers,
+// recording and the HTTP server.
+//
+// The Fyne UI (package ui) is built on a Service and subscribes to its
+// status updates; --headless mode runs a Service on its own.
+package core
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/events"
+	"camera-dashboard-go/internal/helpers"
+	"camera-dashboard-go/internal/motion"
+	"camera-dashboard-go/internal/perf"
+	"camera-dashboard-go/internal/recording"
+	"camera-dashboard-go/internal/server"
+	"camera-dashboard-go/internal/supervisor"
+	"camera-dashboard-go/internal/trigger"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// managerStopDelay lets FFmpeg processes exit before a replacement manager
+// opens the same devices.
+const managerStopDelay = 500 * time.Millisecond
+
+var errStopped = errors.New("service stopped")
+
+// MaxSlots is the largest supported number of camera slots.
+const MaxSlots = 8
+
+// Service owns the camera manager and the goroutines that supervise it.
+type Service struct {
+	cfg   *config.Config
+	slots int
+
+	// The manager is replaced on hotplug; always read it through Manager()
+	managerMu      sync.RWMutex
+	manager        *camera.Manager
+	perfController *perf.AdaptiveController
+	newManager     func(camera.Settings) *camera.Manager
+
+	// Camera state, stale-frame restarts, hotplug and health logging
+	supervisor *supervisor.Supervisor
+
+	// Motion detection per slot
+	motionMu       sync.Mutex // Protects motionEnabled, motionActive, motionZones, motionZonesGen
+	motionEnabled  []bool
+	motionActive   []bool
+	motionZones    [][]motion.Polygon // Detection zones; nil = whole frame
+	motionZonesGen []uint64           // Bumped when a slot's zones change
+	onMotion       func(slot int, active bool)
+	onZones        func(slot int, zones []motion.Polygon)
+
+	// Crops set at runtime, by [camera.<key>]; they override the config
+	cropMu sync.Mutex
+	crops  map[string]camera.Crop
+
+	// Parking guide steering (-1 full left .. 1 full right)
+	guidesMu sync.Mutex
+	steering float64
+	onGuides func()
+
+	// Trigger inputs (nil without [trigger.<name>] sections). The fullscreen
+	// state is owned by the trigger polling goroutine.
+	triggers      *trigger.Set
+	triggerActive []config.TriggerConfig // Active fullscreen triggers, oldest first
+	triggerSlot   int                    // Camera shown by triggers, -1 = grid
+	onTrigger     func(slot int)
+
+	// Loop recording and event clips (nil when disabled)
+	recorder *recording.Recorder
+	clips    *recording.ClipRecorder
+
+	// Embedded HTTP server (nil when disabled)
+	httpServer *server.Server
+	controller server.Controller
+
+	// State changes for remote monitors (served at /events)
+	events *events.Bus
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a service for cfg (defaults when nil). Nothing runs until Start.
+func New(cfg *config.Config) *Service {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	slots := cfg.CameraSlotCount
+	if slots < 1 {
+		slots = 1
+	}
+	if slots > MaxSlots {
+		slots = MaxSlots
+	}
+
+	s := &Service{
+		cfg:            cfg,
+		slots:          slots,
+		newManager:     func(cs camera.Settings) *camera.Manager { return camera.NewManagerWithSettings(cs, true) },
+		motionEnabled:  make([]bool, slots),
+		motionActive:   make([]bool, slots),
+		motionZones:    make([][]motion.Polygon, slots),
+		motionZonesGen: make([]uint64, slots),
+		triggerSlot:    -1,
+		crops:          make(map[string]camera.Crop),
+		events:         events.NewBus(),
+		stopCh:         make(chan struct{}),
+	}
+	s.controller = Controller{s}
+	s.supervisor = supervisor.New(cfg, slots, managerAdapter{s})
+	s.supervisor.SetEventBus(s.events)
+	return s
+}
+
+// SetManagerFactory changes how camera managers are created, e.g. to use
+// synthetic discovery and sources. Call before Start.
+func (s *Service) SetManagerFactory(factory func(camera.Settings) *camera.Manager) {
+	s.newManager = factory
+}
+
+// SetController replaces the REST API controller, e.g. with one that also
+// drives the display. Call before Start.
+func (s *Service) SetController(c server.Controller) {
+	s.controller = c
+}
+
+// SetStatusHandler registers fn to be called with every camera status
+// update, including repeated ones. Call before Start.
+func (s *Service) SetStatusHandler(fn func(slot int, connected bool)) {
+	s.supervisor.SetStatusHandler(fn)
+}
+
+// SetCamerasHandler registers fn to be called after the camera list changes
+// (initialization and hotplug). Call before Start.
+func (s *Service) SetCamerasHandler(fn func(cams []camera.Camera)) {
+	s.supervisor.SetCamerasHandler(fn)
+}
+
+// Supervisor returns the supervisor that owns the per-slot camera state.
+func (s *Service) Supervisor() *supervisor.Supervisor {
+	return s.supervisor
+}
+
+// Config returns the service configuration.
+func (s *Service) Config() *config.Config {
+	return s.cfg
+}
+
+// Slots returns the number of camera slots.
+func (s *Service) Slots() int {
+	return s.slots
+}
+
+// Events returns the bus state changes are published on.
+func (s *Service) Events() *events.Bus {
+	return s.events
+}
+
+// Manager returns the current camera manager, or nil before initialization.
+func (s *Service) Manager() *camera.Manager {
+	s.managerMu.RLock()
+	defer s.managerMu.RUnlock()
+	return s.manager
+}
+
+// PerfController returns the adaptive FPS controller, or nil before the
+// cameras are initialized.
+func (s *Service) PerfController() *perf.AdaptiveController {
+	s.managerMu.RLock()
+	defer s.managerMu.RUnlock()
+	return s.perfController
+}
+
+// Recorder returns the loop recorder, or nil when recording is disabled.
+func (s *Service) Recorder() *recording.Recorder {
+	return s.recorder
+}
+
+// Done is closed when the service starts stopping.
+func (s *Service) Done() <-chan struct{} {
+	return s.stopCh
+}
+
+// CaptureFPS is the capture FPS currently applied by the adaptive controller.
+func (s *Service) CaptureFPS() int {
+	if pc := s.PerfController(); pc != nil {
+		return pc.GetCurrentFPS()
+	}
+	return s.cfg.CaptureFPS
+}
+
+// Cameras returns a copy of the camera list; index == slot.
+func (s *Service) Cameras() []camera.Camera {
+	return s.supervisor.Cameras()
+}
+
+// Camera returns the camera in slot.
+func (s *Service) Camera(slot int) (camera.Camera, bool) {
+	return s.supervisor.Camera(slot)
+}
+
+// Connected reports whether the camera in slot is connected.
+func (s *Service) Connected(slot int) bool {
+	return s.supervisor.Connected(slot)
+}
+
+// cameraSettings builds the capture settings from config, including the
+// per-camera [camera.<id-or-port>] overrides and the crops set at runtime.
+func (s *Service) cameraSettings() camera.Settings {
+	cs := camera.Settings{
+		Width:      s.cfg.CaptureWidth,
+		Height:     s.cfg.CaptureHeight,
+		FPS:        s.cfg.CaptureFPS,
+		Format:     s.cfg.CaptureFormat,
+		MaxCameras: s.slots,
+		Backend:    s.cfg.CaptureBackend,
+		SlotPins:   s.cfg.CameraSlotPins,
+	}
+	if len(s.cfg.Cameras) > 0 {
+		cs.Cameras = make(map[string]camera.CameraOverride, len(s.cfg.Cameras))
+		for key, cc := range s.cfg.Cameras {
+			crop, err := camera.ParseCrop(cc.Crop)
+			if err != nil {
+				log.Printf("[Core] [camera.%s]: ignoring invalid crop %q: %v", key, cc.Crop, err)
+			}
+			cs.Cameras[key] = camera.CameraOverride{
+				Width:    cc.Width,
+				Height:   cc.Height,
+				FPS:      cc.FPS,
+				Format:   cc.Format,
+				Name:     cc.Name,
+				Role:     cc.Role,
+				Rotation: cc.Rotation,
+				Mirror:   cc.Mirror,
+				Flip:     cc.Flip,
+				Crop:     crop,
+				NoMotion: cc.NoMotion,
+				Zones:    cc.Zones,
+				Filters:  cc.Filters,
+
+				DewarpK1:    cc.DewarpK1,
+				DewarpK2:    cc.DewarpK2,
+				DewarpScale: cc.DewarpScale,
+				DewarpFile:  cc.DewarpFile,
+			}
+		}
+	}
+	s.cropMu.Lock()
+	for key, crop := range s.crops {
+		if cs.Cameras == nil {
+			cs.Cameras = make(map[string]camera.CameraOverride)
+		}
+		o := cs.Cameras[key]
+		o.Crop = crop
+		cs.Cameras[key] = o
+	}
+	s.cropMu.Unlock()
+	return cs
+}
+
+// Start begins recording, the HTTP server, camera initialization, the
+// supervisors, motion detection, the triggers and the steering input. It
+// returns immediately.
+func (s *Service) Start() {
+	s.startRecording()
+	s.startTriggers() // Before the HTTP server, which sets API triggers
+	s.startHTTPServer()
+	go s.initializeCameras()
+	s.supervisor.Start()
+	go s.runMotionDetection()
+	go s.runSteering()
+}
+
+// Stop stops the supervisors, the HTTP server, the FPS controller and the
+// camera manager (killing FFmpeg processes), and finalizes recordings.
+// It is idempotent and returns when everything has stopped.
+func (s *Service) Stop() {
+	s.stopOnce.Do(func() { s.shutdown(false) })
+}
+
+// Restart stops the service like Stop and launches a new instance of the
+// executable with the same arguments.
+func (s *Service) Restart() {
+	s.stopOnce.Do(func() { s.shutdown(true) })
+}
+
+func (s *Service) shutdown(relaunch bool) {
+	log.Println("[Core] Stopping all processes...")
+
+	// Stop hot-plug, stale detection, health logging, motion detection and
+	// the triggers
+	close(s.stopCh)
+	s.supervisor.Stop()
+	s.stopTriggers()
+
+	// Close HTTP streams before their cameras go away (and free the port
+	// for a new instance)
+	s.stopHTTPServer()
+
+	// Stop performance controller
+	if pc := s.PerfController(); pc != nil {
+		pc.Stop()
+	}
+
+	// Stop camera manager (kills FFmpeg processes)
+	if m := s.Manager(); m != nil {
+		m.Stop()
+		log.Println("[Core] Stopped camera manager")
+	}
+
+	// Finalize open recording segments and clips
+	s.stopRecording()
+
+	if relaunch {
+		s.relaunch()
+	}
+	log.Println("[Core] Stopped")
+}
+
+// relaunch starts a new instance of the current executable.
+func (s *Service) relaunch() {
+	log.Println("[Core] Restart: relaunching application...")
+
+	executable, err := os.Executable()
+	if err != nil {
+		log.Printf("[Core] Restart: failed to get executable path: %v", err)
+		return
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+
+	if err := cmd.Start(); err != nil {
+		log.Printf("[Core] Restart: failed to start new instance: %v", err)
+		return
+	}
+	log.Println("[Core] Restart: new instance started")
+}
+
+// startRecording creates the loop recorder ([recording]) and the event clip
+// recorder ([clips]) when enabled. They outlive camera managers and are
+// attached to each one in startManager.
+func (s *Service) startRecording() {
+	if s.cfg.RecordingEnabled {
+		rec, err := recording.New(recording.Config{
+			Dir:             s.cfg.RecordingDir,
+			SegmentDuration: time.Duration(s.cfg.RecordingSegmentSec) * time.Second,
+			MaxTotalBytes:   int64(s.cfg.RecordingMaxTotalMB) << 20,
+			MinFreeBytes:    int64(s.cfg.RecordingMinFreeMB) << 20,
+			JPEGQuality:     s.cfg.RecordingJPEGQuality,
+		})
+		if err != nil {
+			log.Printf("[Core] Recording disabled: %v", err)
+		} else {
+			s.recorder = rec
+		}
+	}
+
+	if s.cfg.ClipsEnabled {
+		clips, err := recording.NewClipRecorder(recording.ClipConfig{
+			Dir:          s.cfg.ClipsDir,
+			PreDuration:  time.Duration(s.cfg.ClipPreSec) * time.Second,
+			PostDuration: time.Duration(s.cfg.ClipPostSec) * time.Second,
+			RingBytes:    s.cfg.ClipRingMB << 20,
+			JPEGQuality:  s.cfg.RecordingJPEGQuality,
+		})
+		if err != nil {
+			log.Printf("[Core] Event clips disabled: %v", err)
+		} else {
+			s.clips = clips
+		}
+	}
+}
+
+// stopRecording finalizes open recording segments and clips.
+func (s *Service) stopRecording() {
+	if s.recorder != nil {
+		s.recorder.Close()
+	}
+	if s.clips != nil {
+		s.clips.Close()
+	}
+}
+
+// startManager creates a camera manager with the recorders attached,
+// initializes and starts it. The caller passes the cameras to the supervisor.
+func (s *Service) startManager() ([]camera.Camera, error) {
+	m := s.newManager(s.cameraSettings())
+	if s.recorder != nil {
+		m.AddFrameSink(s.recorder)
+	}
+	if s.clips != nil {
+		m.AddFrameSink(s.clips)
+	}
+	s.managerMu.Lock()
+	select {
+	case <-s.stopCh:
+		s.managerMu.Unlock()
+		return nil, errStopped
+	default:
+	}
+	s.manager = m
+	s.managerMu.Unlock()
+
+	if err := m.Initialize(); err != nil {
+		return nil, err
+	}
+	if err := m.Start(); err != nil {
+		return nil, err
+	}
+
+	return m.GetCameras(), nil
+}
+
+func (s *Service) initializeCameras() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Core] PANIC in camera init: %v", r)
+		}
+	}()
+
+	log.Println("[Core] Starting camera initialization...")
+
+	// Kill any processes holding camera devices (e.g., stale FFmpeg from previous run)
+	if s.cfg.KillDeviceHolders {
+		maxScan := helpers.MaxInt(10, s.slots*4+4)
+		for devNum := 0; devNum <= maxScan; devNum += 2 {
+			devPath := fmt.Sprintf("/dev/video%d", devNum)
+			if _, err := os.Stat(devPath); err == nil {
+				helpers.KillDeviceHolders(devPath, true)
+			}
+		}
+	}
+
+	cams, err := s.startManager()
+	if err != nil {
+		log.Printf("[Core] Camera init error: %v", err)
+		return
+	}
+	log.Println("[Core] Manager initialized (buffer mode, config-driven settings)")
+
+	log.Printf("[Core] Discovered %d cameras", len(cams))
+	for i, cam := range cams {
+		if !cam.Available {
+			log.Printf("[Core]   - slot %d (%s): waiting for pinned camera %s", i, cam.Label(i), cam.Name)
+			continue
+		}
+		log.Printf("[Core]   - %s: %s %s [%s]", cam.Label(i), cam.DeviceID, cam.DevicePath, cam.Identity)
+	}
+	s.supervisor.SetCameras(cams)
+
+	pc := perf.NewAdaptiveController(s.Manager(), s.cfg)
+	pc.SetEventBus(s.events)
+	s.managerMu.Lock()
+	select {
+	case <-s.stopCh:
+		// Stopped during initialization
+		s.managerMu.Unlock()
+		return
+	default:
+	}
+	s.perfController = pc
+	s.managerMu.Unlock()
+	pc.Start()
+}
+
+// managerAdapter lets the supervisor watch whichever camera manager is
+// current; the manager is replaced when a new camera is plugged in.
+type managerAdapter struct {
+	s *Service
+}
+
+func (m managerAdapter) LastFrameTime(slot int) time.Time {
+	mgr := m.s.Manager()
+	cam, ok := m.s.Camera(slot)
+	if mgr == nil || !ok {
+		return time.Time{}
+	}
+	if fb := mgr.GetFrameBuffer(cam.DeviceID); fb != nil {
+		return fb.GetLastFrameTime()
+	}
+	return time.Time{}
+}
+
+func (m managerAdapter) RestartCamera(slot int) error {
+	mgr := m.s.Manager()
+	if mgr == nil {
+		return camera.ErrManagerNotInitialized
+	}
+	return mgr.RestartCameraByIndex(slot)
+}
+
+// Reinitialize stops the current manager and starts a new one, which
+// rediscovers all cameras.
+func (m managerAdapter) Reinitialize() ([]camera.Camera, error) {
+	if mgr := m.s.Manager(); mgr != nil {
+		mgr.Stop()
+		time.Sleep(managerStopDelay)
+	}
+	return m.s.startManager()
+}
+
+// Snapshot writes the current frame of the camera at camIndex, or of every
+// camera when camIndex < 0, to the [snapshot] directory. Returns the written
+// file paths.
+func (s *Service) Snapshot(camIndex int) ([]string, error) {
+	m := s.Manager()
+	if m == nil {
+		return nil, camera.ErrManagerNotInitialized
+	}
+	return m.Snapshot(camIndex, camera.SnapshotOptions{
+		Dir:         s.cfg.SnapshotDir,
+		Format:      s.cfg.SnapshotFormat,
+		JPEGQuality: s.cfg.SnapshotJPEGQuality,
+	})
+}
+
+// SaveClip saves a pre/post-event clip for the named camera (key, role or
+// device ID), or for all cameras when cameraName is "". trigger is one of
+// the recording.Trigger* values. Returns the number of cameras triggered.
+func (s *Service) SaveClip(cameraName, trigger, detail string) int {
+	if s.clips == nil {
+		log.Printf("[Core] Save clip ignored: [clips] not enabled")
+		return 0
+	}
+	return s.clips.Trigger(recording.Event{
+		Trigger: trigger,
+		Detail:  detail,
+		Camera:  cameraName,
+		Time:    time.Now(),
+	})
+}
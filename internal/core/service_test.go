@@ -0,0 +1,484 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/dewarp"
+	"camera-dashboard-go/internal/events"
+	"camera-dashboard-go/internal/motion"
+	"camera-dashboard-go/internal/supervisor"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSource delivers a small image every 5ms; after stallAfter frames per
+// Open (0 = never) it stops delivering until it is closed and reopened.
+// With flicker set, frames alternate between black and white every 100ms.
+type testSource struct {
+	mu         sync.Mutex
+	stallAfter int
+	flicker    bool
+	sent       int
+	closeCh    chan struct{}
+}
+
+func (f *testSource) Open() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = 0
+	f.closeCh = make(chan struct{})
+	return nil
+}
+
+func (f *testSource) NextFrame() (camera.Frame, error) {
+	f.mu.Lock()
+	closeCh := f.closeCh
+	stalled := f.stallAfter > 0 && f.sent >= f.stallAfter
+	white := f.flicker && time.Now().UnixNano()/int64(100*time.Millisecond)%2 == 1
+	f.sent++
+	f.mu.Unlock()
+	if closeCh == nil {
+		return camera.Frame{}, io.EOF
+	}
+	if stalled {
+		<-closeCh
+		return camera.Frame{}, io.EOF
+	}
+	select {
+	case <-closeCh:
+		return camera.Frame{}, io.EOF
+	case <-time.After(5 * time.Millisecond):
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		img.Set(0, 0, color.White)
+		if white {
+			draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+		}
+		return camera.Frame{Image: img}, nil
+	}
+}
+
+func (f *testSource) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closeCh != nil {
+		close(f.closeCh)
+		f.closeCh = nil
+	}
+	return nil
+}
+
+func (f *testSource) Capabilities() camera.CameraCapabilities {
+	return camera.CameraCapabilities{MaxWidth: 8, MaxHeight: 8, MaxFPS: 30}
+}
+
+// newTestService returns a service with two synthetic cameras and no
+// device scanning, HTTP or health logging.
+func newTestService(sources ...*testSource) *Service {
+	cfg := config.DefaultConfig()
+	cfg.CameraSlotCount = 2
+	cfg.KillDeviceHolders = false
+	cfg.HealthLogIntervalSec = 0
+	cfg.HTTPEnabled = false
+	cfg.RecordingEnabled = false
+	cfg.ClipsEnabled = false
+	cfg.RescanIntervalMS = 60000
+	cfg.StaleFrameTimeoutSec = 0.5
+	cfg.RestartCooldownSec = 0
+
+	s := New(cfg)
+	s.SetManagerFactory(func(cs camera.Settings) *camera.Manager {
+		m := camera.NewManagerWithSettings(cs, true)
+		m.SetDiscovery(func(camera.Settings) ([]camera.Camera, error) {
+			return []camera.Camera{
+				{DeviceID: "synthetic0", Available: true},
+				{DeviceID: "synthetic1", Available: true},
+			}, nil
+		})
+		m.SetSourceFactory(func(cam camera.Camera, _ camera.Settings) camera.FrameSource {
+			if cam.DeviceID == "synthetic1" && len(sources) > 1 {
+				return sources[1]
+			}
+			if len(sources) > 0 {
+				return sources[0]
+			}
+			return &testSource{}
+		})
+		return m
+	})
+	return s
+}
+
+func TestService_StartReportsStatusAndStops(t *testing.T) {
+	s := newTestService()
+	status := make(chan int, 8)
+	s.SetStatusHandler(func(slot int, connected bool) {
+		if connected {
+			status <- slot
+		}
+	})
+	var labelled []camera.Camera
+	var mu sync.Mutex
+	s.SetCamerasHandler(func(cams []camera.Camera) {
+		mu.Lock()
+		labelled = cams
+		mu.Unlock()
+	})
+	s.Start()
+	defer s.Stop()
+
+	seen := map[int]bool{}
+	for len(seen) < 2 {
+		select {
+		case slot := <-status:
+			seen[slot] = true
+		case <-time.After(3 * time.Second):
+			t.Fatalf("connected slots = %v, want 0 and 1", seen)
+		}
+	}
+	mu.Lock()
+	if len(labelled) != 2 {
+		t.Errorf("cameras handler got %d cameras, want 2", len(labelled))
+	}
+	mu.Unlock()
+
+	// Frames are tracked from the frame buffers, without a UI reading them
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if status, _ := s.Supervisor().CameraHealth(1, time.Now()); status == supervisor.HealthOnline {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("camera 1 never became healthy")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	st := NewController(s).State()
+	if len(st.Cameras) != 2 || !st.Cameras[0].Connected || st.Fullscreen != -1 || len(st.Grid) != 0 {
+		t.Errorf("headless state = %+v", st)
+	}
+
+	s.Stop()
+	s.Stop() // Idempotent
+	select {
+	case <-s.Done():
+	default:
+		t.Error("Done not closed after Stop")
+	}
+}
+
+func TestService_RestartsStalledCamera(t *testing.T) {
+	s := newTestService(&testSource{}, &testSource{stallAfter: 20})
+	sub := s.Events().Subscribe(0)
+	defer sub.Close()
+	s.Start()
+	defer s.Stop()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-sub.C:
+			if ev.Type != events.CameraRestart {
+				continue
+			}
+			if *ev.Slot != 1 {
+				t.Fatalf("restart event for slot %d, want 1", *ev.Slot)
+			}
+			_, totals := s.Supervisor().RestartCounts()
+			if totals[0] != 0 || totals[1] != 1 {
+				t.Errorf("restart totals = %v, want [0 1]", totals)
+			}
+			return
+		case <-timeout:
+			t.Fatal("stalled camera was not restarted")
+		}
+	}
+}
+
+func TestService_MotionDetection(t *testing.T) {
+	s := newTestService(&testSource{}, &testSource{flicker: true})
+	s.cfg.MotionEnabled = true
+	s.cfg.MotionCheckFPS = 30
+	motion := make(chan int, 8)
+	s.SetMotionHandler(func(slot int, active bool) {
+		if active {
+			motion <- slot
+		}
+	})
+	sub := s.Events().Subscribe(0)
+	defer sub.Close()
+	s.Start()
+	defer s.Stop()
+
+	select {
+	case slot := <-motion:
+		if slot != 1 {
+			t.Fatalf("motion on slot %d, want 1", slot)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no motion detected on the flickering camera")
+	}
+	if !s.Motion(1) || s.Motion(0) {
+		t.Errorf("motion = %v/%v, want only slot 1", s.Motion(0), s.Motion(1))
+	}
+	if st := NewController(s).State(); !st.Cameras[1].Motion || !st.Cameras[0].MotionDetection {
+		t.Errorf("state cameras = %+v", st.Cameras)
+	}
+
+	if err := s.SetMotionDetection(1, false); err != nil {
+		t.Fatal(err)
+	}
+	if s.Motion(1) || s.MotionDetection(1) {
+		t.Error("motion still flagged after disabling detection")
+	}
+	if err := s.SetMotionDetection(5, true); err == nil {
+		t.Error("SetMotionDetection accepted slot 5")
+	}
+
+	var started, ended bool
+	for !ended {
+		select {
+		case ev := <-sub.C:
+			started = started || ev.Type == events.MotionStart
+			ended = ev.Type == events.MotionEnd
+		case <-time.After(time.Second):
+			t.Fatalf("motion events: start=%v end=%v, want both", started, ended)
+		}
+	}
+	if !started {
+		t.Error("no motion_start event before motion_end")
+	}
+}
+
+func TestService_MotionZones(t *testing.T) {
+	s := newTestService()
+	s.cfg.MotionEnabled = true
+	s.cfg.MotionCheckFPS = 30
+	s.cfg.Cameras = map[string]config.CameraConfig{
+		"synthetic0": {Slot: -1, Zones: "0,0 0.5,0 0.5,1 0,1"},
+		"synthetic1": {Slot: -1, Zones: "0,0 2,2"},
+	}
+	type update struct {
+		slot  int
+		zones []motion.Polygon
+	}
+	updates := make(chan update, 8)
+	s.SetZonesHandler(func(slot int, zones []motion.Polygon) {
+		updates <- update{slot, zones}
+	})
+	s.Start()
+	defer s.Stop()
+
+	// Zones from config arrive when the cameras are assigned; invalid ones
+	// fall back to the whole frame
+	got := map[int][]motion.Polygon{}
+	for len(got) < 2 {
+		select {
+		case u := <-updates:
+			got[u.slot] = u.zones
+		case <-time.After(3 * time.Second):
+			t.Fatalf("zone updates = %v, want slots 0 and 1", got)
+		}
+	}
+	if len(got[0]) != 1 || len(got[0][0]) != 4 || got[1] != nil {
+		t.Errorf("zones = %v, want one zone on slot 0, none on slot 1", got)
+	}
+
+	lane, _ := motion.ParseZones("0.5,0 1,0 1,1")
+	if err := s.SetMotionZones(1, lane); err != nil {
+		t.Fatal(err)
+	}
+	if u := <-updates; u.slot != 1 || len(u.zones) != 1 {
+		t.Errorf("update after SetMotionZones = %+v", u)
+	}
+	if z := s.MotionZones(1); len(z) != 1 || z[0][2] != (motion.Point{X: 1, Y: 1}) {
+		t.Errorf("MotionZones(1) = %v", z)
+	}
+	if err := s.SetMotionZones(1, []motion.Polygon{lane[0][:2]}); err == nil {
+		t.Error("SetMotionZones accepted a two-point zone")
+	}
+}
+
+func TestService_TriggerFullscreen(t *testing.T) {
+	s := newTestService()
+	s.cfg.Cameras = map[string]config.CameraConfig{"synthetic1": {Slot: -1, Role: "Rear"}}
+	s.cfg.Triggers = []config.TriggerConfig{
+		{Name: "left", Source: "api", Line: -1, Action: "fullscreen", Camera: "0"},
+		{Name: "reverse", Source: "api", Line: -1, Action: "fullscreen", Camera: "rear"},
+	}
+	shown := make(chan int, 8)
+	s.SetTriggerHandler(func(slot int) { shown <- slot })
+	s.Start()
+	defer s.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(s.Cameras()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("cameras not initialized")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	expect := func(want int) {
+		t.Helper()
+		select {
+		case slot := <-shown:
+			if slot != want {
+				t.Fatalf("trigger fullscreen = %d, want %d", slot, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no trigger fullscreen change, want %d", want)
+		}
+	}
+
+	// The most recent active trigger wins; clearing it falls back to the
+	// other one, clearing both returns to the grid
+	s.SetTrigger("left", true)
+	expect(0)
+	s.SetTrigger("reverse", true)
+	expect(1)
+	s.SetTrigger("reverse", false)
+	expect(0)
+	s.SetTrigger("left", false)
+	expect(-1)
+
+	if err := s.SetTrigger("missing", true); err == nil {
+		t.Error("SetTrigger on an unknown trigger succeeded")
+	}
+	if st := s.Triggers(); len(st) != 2 || st[0].Active || st[1].Active {
+		t.Errorf("Triggers() = %+v, want two inactive", st)
+	}
+}
+
+func TestService_GuidesSteering(t *testing.T) {
+	s := newTestService()
+	path := filepath.Join(t.TempDir(), "steering")
+	os.WriteFile(path, []byte("0.5\n"), 0o644)
+	s.cfg.Cameras = map[string]config.CameraConfig{"synthetic1": {Slot: -1, Role: "Rear"}}
+	s.cfg.GuidesEnabled = true
+	s.cfg.GuidesSteeringPath = path
+	changed := make(chan struct{}, 8)
+	s.SetGuidesHandler(func() { changed <- struct{}{} })
+	s.Start()
+	defer s.Stop()
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("steering file not read")
+	}
+	if got := s.Steering(); got != 0.5 {
+		t.Errorf("Steering() = %v, want 0.5 from the file", got)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	slot, lines := s.GuideLines()
+	for slot < 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no guide lines for the Rear camera")
+		}
+		time.Sleep(10 * time.Millisecond)
+		slot, lines = s.GuideLines()
+	}
+	if slot != 1 || len(lines) == 0 {
+		t.Errorf("GuideLines() = %d with %d lines, want slot 1", slot, len(lines))
+	}
+
+	if err := s.SetSteering(1.5); err == nil {
+		t.Error("SetSteering(1.5) accepted")
+	}
+	s.cfg.GuidesEnabled = false
+	if slot, lines := s.GuideLines(); slot != -1 || lines != nil {
+		t.Errorf("GuideLines() with guides off = %d, %v", slot, lines)
+	}
+}
+
+func TestService_Crop(t *testing.T) {
+	s := newTestService()
+	s.cfg.Path = filepath.Join(t.TempDir(), "config.ini")
+	os.WriteFile(s.cfg.Path, []byte("[camera.synthetic0]\nrole = Left\ncrop = 0,0,0.5,1\n"), 0o644)
+	s.cfg.Cameras = map[string]config.CameraConfig{"synthetic0": {Slot: -1, Role: "Left", Crop: "0,0,0.5,1"}}
+	s.Start()
+	defer s.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(s.Cameras()) < 2 || s.Manager().GetWorker("synthetic1") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("cameras not initialized")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c := s.Crop(0); c != (camera.Crop{W: 0.5, H: 1}) {
+		t.Errorf("Crop(0) = %+v, want crop from config", c)
+	}
+
+	// Runtime crops reach the worker; persisted ones land in the config file
+	if err := s.SetCrop(0, camera.Crop{}, true); err != nil {
+		t.Fatalf("SetCrop(0, full): %v", err)
+	}
+	crop := camera.Crop{X: 0.25, Y: 0.25, W: 0.5, H: 0.5}
+	if err := s.SetCrop(1, crop, true); err != nil {
+		t.Fatalf("SetCrop(1): %v", err)
+	}
+	if c := s.Manager().GetWorker("synthetic1").Crop(); c != crop {
+		t.Errorf("worker crop = %+v, want %+v", c, crop)
+	}
+	if c := s.cameraSettings().ForCamera(camera.Camera{DeviceID: "synthetic1"}).Crop; c != crop {
+		t.Errorf("crop for a restarted manager = %+v, want %+v", c, crop)
+	}
+	saved, err := config.Load(s.cfg.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc := saved.Cameras["synthetic0"]; cc.Crop != "" || cc.Role != "Left" {
+		t.Errorf("saved synthetic0 = %+v, want role kept and crop removed", cc)
+	}
+	if cc := saved.Cameras["synthetic1"]; cc.Crop != "0.25,0.25,0.5,0.5" {
+		t.Errorf("saved synthetic1 crop = %q", cc.Crop)
+	}
+
+	if err := s.SetCrop(1, camera.Crop{X: 0.9, W: 0.5, H: 1}, false); err == nil {
+		t.Error("SetCrop accepted a crop outside the frame")
+	}
+}
+
+func TestService_Display(t *testing.T) {
+	s := newTestService()
+	s.cfg.DisplayFilters = "nightmode"
+	dir := t.TempDir()
+	s.cfg.Path = filepath.Join(dir, "config.ini")
+	os.WriteFile(filepath.Join(dir, "rear.cal"), []byte("k1 = -0.3\nk2 = 0.05\ncx = 0.55\n"), 0o644)
+	s.cfg.Cameras = map[string]config.CameraConfig{
+		"synthetic0": {Slot: -1, DewarpFile: "rear.cal", DewarpK1: -0.2, Filters: "dewarp, mirror"},
+		"synthetic1": {Slot: -1, DewarpFile: "missing.cal"},
+	}
+	if p := s.Dewarp(0); p.Enabled() {
+		t.Errorf("Dewarp(0) before start = %+v, want none", p)
+	}
+	s.Start()
+	defer s.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(s.Cameras()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("cameras not initialized")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The file is read next to the config; the keys override it
+	if p, want := s.Dewarp(0), (dewarp.Params{K1: -0.2, K2: 0.05, CX: 0.55}); p != want {
+		t.Errorf("Dewarp(0) = %+v, want %+v", p, want)
+	}
+	if p := s.Dewarp(1); p.Enabled() {
+		t.Errorf("Dewarp(1) with a missing file = %+v, want none", p)
+	}
+	if f0, f1 := s.Filters(0), s.Filters(1); f0 != "dewarp, mirror" || f1 != "nightmode" {
+		t.Errorf("Filters = %q, %q, want the camera's own and the global chain", f0, f1)
+	}
+}
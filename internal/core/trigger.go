@@ -0,0 +1,157 @@
+package core
+
+import (
+	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/events"
+	"camera-dashboard-go/internal/recording"
+	"camera-dashboard-go/internal/trigger"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Triggers
+// =============================================================================
+// [trigger.<name>] inputs (GPIO lines, files or FIFOs, API calls) drive
+// display actions. A "fullscreen" trigger shows its camera fullscreen while
+// active; with several active the most recently activated one wins, and
+// when the last one clears the display returns to the grid. A "clip"
+// trigger saves an event clip of its camera (all cameras when none is set)
+// when it becomes active. The UI performs the fullscreen changes through
+// SetTriggerHandler; headless, they are only logged and published.
+// =============================================================================
+
+// SetTriggerHandler registers fn to be called when triggers change the
+// fullscreen camera: slot >= 0 shows that camera, -1 returns to the grid.
+// Call before Start.
+func (s *Service) SetTriggerHandler(fn func(slot int)) {
+	s.onTrigger = fn
+}
+
+// SetTrigger sets the value of a trigger with source "api".
+func (s *Service) SetTrigger(name string, active bool) error {
+	if s.triggers == nil {
+		return fmt.Errorf("unknown trigger %q", name)
+	}
+	return s.triggers.Set(name, active)
+}
+
+// Triggers returns the state of the opened triggers.
+func (s *Service) Triggers() []trigger.State {
+	if s.triggers == nil {
+		return nil
+	}
+	return s.triggers.States()
+}
+
+// startTriggers opens the configured triggers and polls them until Stop.
+func (s *Service) startTriggers() {
+	if len(s.cfg.Triggers) == 0 {
+		return
+	}
+	s.triggers = trigger.Open(s.cfg.Triggers)
+	s.triggers.SetHandler(s.handleTrigger)
+	go s.triggers.Run(s.stopCh)
+}
+
+// stopTriggers releases the trigger inputs (GPIO lines, FIFOs).
+func (s *Service) stopTriggers() {
+	if s.triggers != nil {
+		s.triggers.Close()
+	}
+}
+
+// handleTrigger runs on the trigger polling goroutine.
+func (s *Service) handleTrigger(cfg config.TriggerConfig, active bool) {
+	slot := s.findCamera(cfg.Camera)
+	state := "cleared"
+	evType := events.TriggerCleared
+	if active {
+		state = "active"
+		evType = events.TriggerActive
+	}
+	log.Printf("[Trigger] %s: %s", cfg.Name, state)
+
+	ev := events.Event{Type: evType, Message: cfg.Name}
+	if slot >= 0 {
+		ev = events.Camera(evType, slot, s.supervisor.CameraLabel(slot), cfg.Name)
+	}
+	ev.Data = map[string]interface{}{"action": cfg.Action}
+	s.events.Publish(ev)
+
+	switch cfg.Action {
+	case trigger.ActionFullscreen:
+		s.updateTriggerFullscreen(cfg, active)
+	case trigger.ActionClip:
+		if !active {
+			return
+		}
+		var name string
+		if cfg.Camera != "" {
+			cam, ok := s.Camera(slot)
+			if !ok {
+				log.Printf("[Trigger] %s: no camera %q for clip", cfg.Name, cfg.Camera)
+				return
+			}
+			name = cam.DeviceID
+		}
+		s.SaveClip(name, recording.TriggerInput, cfg.Name)
+	}
+}
+
+// updateTriggerFullscreen records a fullscreen trigger change and moves the
+// display to the camera of the most recently activated active trigger.
+func (s *Service) updateTriggerFullscreen(cfg config.TriggerConfig, active bool) {
+	for i, t := range s.triggerActive {
+		if t.Name == cfg.Name {
+			s.triggerActive = append(s.triggerActive[:i], s.triggerActive[i+1:]...)
+			break
+		}
+	}
+	if active {
+		s.triggerActive = append(s.triggerActive, cfg)
+	}
+
+	target := -1
+	for i := len(s.triggerActive) - 1; i >= 0 && target < 0; i-- {
+		target = s.findCamera(s.triggerActive[i].Camera)
+		if target < 0 {
+			log.Printf("[Trigger] %s: no camera %q", s.triggerActive[i].Name, s.triggerActive[i].Camera)
+		}
+	}
+	if target == s.triggerSlot {
+		return
+	}
+	s.triggerSlot = target
+	if target >= 0 {
+		log.Printf("[Trigger] Showing %s fullscreen", s.supervisor.CameraLabel(target))
+	} else {
+		log.Println("[Trigger] Returning to the grid")
+	}
+	if s.onTrigger != nil {
+		s.onTrigger(target)
+	}
+}
+
+// findCamera returns the slot of the camera name refers to - a slot number,
+// role, device ID or identity - or -1.
+func (s *Service) findCamera(name string) int {
+	if name == "" {
+		return -1
+	}
+	cams := s.Cameras()
+	if slot, err := strconv.Atoi(name); err == nil {
+		if slot >= 0 && slot < len(cams) && cams[slot].DeviceID != "" {
+			return slot
+		}
+		return -1
+	}
+	for i, cam := range cams {
+		if cam.DeviceID != "" && (strings.EqualFold(name, cam.Role) || cam.Matches(name)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,289 @@
+// Package dewarp straightens fisheye (barrel) lens distortion. A Remapper
+// precomputes, for every pixel of the corrected picture, which camera pixel
+// to show, so correcting a frame costs one table lookup per pixel. The table
+// is built once per frame layout (size, crop and pixel format) and reused
+// until the layout or the lens parameters change.
+//
+// The lens model is the radial polynomial most calibration tools use: a
+// point at distance r from the optical centre of the corrected picture (in
+// units of half the frame width) is found at r*(1 + K1*r² + K2*r⁴) in the
+// camera frame. Wide-angle and fisheye lenses have a negative K1.
+package dewarp
+
+import (
+	"bufio"
+	"camera-dashboard-go/internal/camera"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Params describes a lens. The zero value corrects nothing.
+type Params struct {
+	K1, K2 float64 // Radial distortion coefficients
+	CX, CY float64 // Optical centre (normalized); 0 = frame centre
+	Scale  float64 // Zoom of the corrected picture; 0 = 1, below 1 shows more of the edges
+}
+
+// Enabled reports whether p corrects anything.
+func (p Params) Enabled() bool {
+	return p.K1 != 0 || p.K2 != 0
+}
+
+// Validate checks the optical centre and scale.
+func (p Params) Validate() error {
+	if p.CX < 0 || p.CX > 1 || p.CY < 0 || p.CY > 1 {
+		return fmt.Errorf("optical centre %g,%g is outside the frame", p.CX, p.CY)
+	}
+	if p.Scale < 0 {
+		return fmt.Errorf("scale %g is negative", p.Scale)
+	}
+	return nil
+}
+
+// withDefaults fills in the zero centre and scale.
+func (p Params) withDefaults() Params {
+	if p.CX == 0 {
+		p.CX = 0.5
+	}
+	if p.CY == 0 {
+		p.CY = 0.5
+	}
+	if p.Scale == 0 {
+		p.Scale = 1
+	}
+	return p
+}
+
+// LoadFile reads a calibration file: "key = value" lines with the keys k1,
+// k2, cx, cy and scale. Blank lines and lines starting with # or ; are
+// skipped, as are unknown keys.
+func LoadFile(path string) (Params, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Params{}, err
+	}
+	defer f.Close()
+
+	var p Params
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			return Params{}, fmt.Errorf("%s:%d: want key = value", path, n)
+		}
+		var dst *float64
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "k1":
+			dst = &p.K1
+		case "k2":
+			dst = &p.K2
+		case "cx":
+			dst = &p.CX
+		case "cy":
+			dst = &p.CY
+		case "scale":
+			dst = &p.Scale
+		default:
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return Params{}, fmt.Errorf("%s:%d: bad number %q", path, n, strings.TrimSpace(value))
+		}
+		*dst = v
+	}
+	if err := scanner.Err(); err != nil {
+		return Params{}, err
+	}
+	if err := p.Validate(); err != nil {
+		return Params{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// layout identifies the frames a table fits: the same size, crop, pixel
+// format and strides give the same pixel offsets.
+type layout struct {
+	rect    image.Rectangle
+	ycbcr   bool
+	ratio   image.YCbCrSubsampleRatio
+	stride  int // Pix stride (RGBA) or Y stride (YCbCr)
+	cstride int
+	crop    camera.Crop
+}
+
+// table holds the source offset of every corrected pixel, row by row; -1
+// shows black (outside the camera frame).
+type table struct {
+	layout layout
+	off    []int32 // Pix offset (RGBA) or Y offset (YCbCr)
+	coff   []int32 // Cb/Cr offset (YCbCr only)
+}
+
+// Remapper corrects the frames of one camera. It is safe for concurrent
+// use, e.g. by a camera tile and the fullscreen view.
+type Remapper struct {
+	mu     sync.Mutex
+	params Params
+	table  *table // Built for the last layout seen; nil after SetParams
+}
+
+// SetParams changes the lens; the zero value turns correction off.
+func (r *Remapper) SetParams(p Params) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p != r.params {
+		r.params = p
+		r.table = nil
+	}
+}
+
+// Params returns the lens.
+func (r *Remapper) Params() Params {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.params
+}
+
+// Enabled reports whether the lens corrects anything.
+func (r *Remapper) Enabled() bool {
+	return r.Params().Enabled()
+}
+
+// Apply writes the corrected src into dst and returns it. dst is reused
+// when it is a w*h *image.RGBA at the origin, else a new one is allocated;
+// the caller keeps it for the next frame. crop is the part of the camera
+// frame src shows, so the optical centre stays where the lens put it. With
+// correction off src is copied unchanged.
+//
+// *image.YCbCr and *image.RGBA frames are read directly; other types are
+// converted to RGBA first.
+func (r *Remapper) Apply(src image.Image, crop camera.Crop, dst *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if dst == nil || dst.Rect != image.Rect(0, 0, w, h) || dst.Stride != w*4 {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+	if !r.Enabled() {
+		draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+		return dst
+	}
+
+	switch s := src.(type) {
+	case *image.YCbCr:
+		t := r.tableFor(layout{rect: b, ycbcr: true, ratio: s.SubsampleRatio, stride: s.YStride, cstride: s.CStride, crop: crop}, func(x, y int) (int, int) {
+			return s.YOffset(x, y), s.COffset(x, y)
+		})
+		remapYCbCr(t, s, dst)
+	case *image.RGBA:
+		t := r.tableFor(layout{rect: b, stride: s.Stride, crop: crop}, func(x, y int) (int, int) {
+			return s.PixOffset(x, y), 0
+		})
+		remapRGBA(t, s, dst)
+	default:
+		rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+		draw.Draw(rgba, rgba.Rect, src, b.Min, draw.Src)
+		return r.Apply(rgba, crop, dst)
+	}
+	return dst
+}
+
+// tableFor returns the table for l, building it when the layout changed.
+// at returns the pixel and chroma offsets of a source pixel.
+func (r *Remapper) tableFor(l layout, at func(x, y int) (int, int)) *table {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.table == nil || r.table.layout != l {
+		r.table = buildTable(r.params, l, at)
+	}
+	return r.table
+}
+
+// buildTable maps every pixel of the corrected picture to its camera pixel
+// (nearest neighbour).
+func buildTable(p Params, l layout, at func(x, y int) (int, int)) *table {
+	p = p.withDefaults()
+	c := l.crop
+	if c.IsFull() {
+		c = camera.FullFrame
+	}
+	w, h := l.rect.Dx(), l.rect.Dy()
+	t := &table{layout: l, off: make([]int32, w*h)}
+	if l.ycbcr {
+		t.coff = make([]int32, w*h)
+	}
+
+	// Work in camera frame pixels, so a crop keeps the lens centre in place
+	fw, fh := float64(w)/c.W, float64(h)/c.H
+	ox, oy := c.X*fw, c.Y*fh
+	cx, cy := p.CX*fw, p.CY*fh
+	f := fw / 2 // Unit radius
+
+	for y := 0; y < h; y++ {
+		v := (float64(y) + 0.5 + oy - cy) / f / p.Scale
+		for x := 0; x < w; x++ {
+			u := (float64(x) + 0.5 + ox - cx) / f / p.Scale
+			r2 := u*u + v*v
+			i := y*w + x
+			t.off[i] = -1
+			// Past the point where the radius stops growing, the polynomial
+			// folds back over the picture: leave it black
+			if 1+3*p.K1*r2+5*p.K2*r2*r2 <= 0 {
+				continue
+			}
+			k := 1 + p.K1*r2 + p.K2*r2*r2
+			sx := math.Floor(cx + u*k*f - ox)
+			sy := math.Floor(cy + v*k*f - oy)
+			if sx < 0 || sy < 0 || sx >= float64(w) || sy >= float64(h) {
+				continue
+			}
+			off, coff := at(l.rect.Min.X+int(sx), l.rect.Min.Y+int(sy))
+			t.off[i] = int32(off)
+			if t.coff != nil {
+				t.coff[i] = int32(coff)
+			}
+		}
+	}
+	return t
+}
+
+// remapRGBA copies the table's source pixels into dst.
+func remapRGBA(t *table, src, dst *image.RGBA) {
+	for i, off := range t.off {
+		d := dst.Pix[i*4 : i*4+4 : i*4+4]
+		if off < 0 {
+			d[0], d[1], d[2], d[3] = 0, 0, 0, 255
+			continue
+		}
+		s := src.Pix[off : off+4 : off+4]
+		d[0], d[1], d[2], d[3] = s[0], s[1], s[2], s[3]
+	}
+}
+
+// remapYCbCr converts the table's source pixels into dst.
+func remapYCbCr(t *table, src *image.YCbCr, dst *image.RGBA) {
+	for i, off := range t.off {
+		d := dst.Pix[i*4 : i*4+4 : i*4+4]
+		if off < 0 {
+			d[0], d[1], d[2], d[3] = 0, 0, 0, 255
+			continue
+		}
+		coff := t.coff[i]
+		d[0], d[1], d[2] = color.YCbCrToRGB(src.Y[off], src.Cb[coff], src.Cr[coff])
+		d[3] = 255
+	}
+}
@@ -0,0 +1,207 @@
+package dewarp
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// coordFrame returns a w*h RGBA frame whose pixels encode their position
+// (R = x, G = y), so the source of every corrected pixel can be read back.
+func coordFrame(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(x, y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = uint8(x), uint8(y), 1, 255
+		}
+	}
+	return img
+}
+
+// sourceOf returns the camera pixel shown at (x, y) of a corrected
+// coordFrame, or ok=false for black.
+func sourceOf(img *image.RGBA, x, y int) (sx, sy int, ok bool) {
+	i := img.PixOffset(x, y)
+	if img.Pix[i+2] == 0 {
+		return 0, 0, false
+	}
+	return int(img.Pix[i]), int(img.Pix[i+1]), true
+}
+
+func TestRemapper_Barrel(t *testing.T) {
+	const w, h = 64, 48
+	var r Remapper
+	r.SetParams(Params{K1: -0.3})
+	out := r.Apply(coordFrame(w, h), camera.Crop{}, nil)
+	if out.Bounds() != image.Rect(0, 0, w, h) {
+		t.Fatalf("bounds = %v", out.Bounds())
+	}
+
+	// The centre stays put
+	if sx, sy, ok := sourceOf(out, w/2, h/2); !ok || sx != w/2 || sy != h/2 {
+		t.Errorf("centre shows %d,%d (ok=%v), want %d,%d", sx, sy, ok, w/2, h/2)
+	}
+	// The edges are pulled in from closer to the centre
+	if sx, sy, ok := sourceOf(out, w-1, h/2); !ok || sx >= w-1 || sx <= w/2 || sy != h/2 {
+		t.Errorf("right edge shows %d,%d (ok=%v), want x between %d and %d", sx, sy, ok, w/2, w-1)
+	}
+	if sx, sy, ok := sourceOf(out, w/2, 0); !ok || sy <= 0 || sx != w/2 {
+		t.Errorf("top edge shows %d,%d (ok=%v), want y > 0", sx, sy, ok)
+	}
+	// Symmetric around the centre
+	for _, p := range []image.Point{{5, 5}, {60, 10}, {20, 40}} {
+		ax, ay, aok := sourceOf(out, p.X, p.Y)
+		bx, by, bok := sourceOf(out, w-1-p.X, h-1-p.Y)
+		if aok != bok || ax != w-1-bx || ay != h-1-by {
+			t.Errorf("%v shows %d,%d but its mirror shows %d,%d", p, ax, ay, bx, by)
+		}
+	}
+}
+
+func TestRemapper_Fold(t *testing.T) {
+	// Strong enough to fold back before the corners: they stay black
+	var r Remapper
+	r.SetParams(Params{K1: -1})
+	out := r.Apply(coordFrame(64, 48), camera.Crop{}, nil)
+	if _, _, ok := sourceOf(out, 0, 0); ok {
+		t.Error("corner past the fold is not black")
+	}
+	if _, _, ok := sourceOf(out, 32, 24); !ok {
+		t.Error("centre is black")
+	}
+}
+
+func TestRemapper_YCbCrMatchesRGBA(t *testing.T) {
+	const w, h = 64, 48
+	ycc := image.NewYCbCr(image.Rect(0, 0, w, h), image.YCbCrSubsampleRatio420)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ycc.Y[ycc.YOffset(x, y)] = uint8(x*3 + y)
+			c := ycc.COffset(x, y)
+			ycc.Cb[c], ycc.Cr[c] = uint8(100+x), uint8(150-y)
+		}
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Rect, ycc, image.Point{}, draw.Src)
+
+	var r Remapper
+	r.SetParams(Params{K1: -0.25, K2: 0.05})
+	want := r.Apply(rgba, camera.Crop{}, nil)
+	got := r.Apply(ycc, camera.Crop{}, nil)
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatalf("pixel byte %d = %d, want %d", i, got.Pix[i], want.Pix[i])
+		}
+	}
+}
+
+func TestRemapper_Crop(t *testing.T) {
+	// Correcting a cropped frame shows the same pixels as correcting the
+	// whole frame and cropping afterwards, wherever they lie in the crop
+	const w, h = 64, 48
+	crop := camera.Crop{X: 0.25, Y: 0.25, W: 0.5, H: 0.5} // 16,12 32x24
+	frame := coordFrame(w, h)
+	params := Params{K1: -0.3, CX: 0.45}
+
+	var full, cropped Remapper
+	full.SetParams(params)
+	cropped.SetParams(params)
+	whole := full.Apply(frame, camera.Crop{}, nil)
+	part := cropped.Apply(crop.Apply(frame), crop, nil)
+	if part.Bounds() != image.Rect(0, 0, 32, 24) {
+		t.Fatalf("bounds = %v", part.Bounds())
+	}
+	shown := 0
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 32; x++ {
+			sx, sy, ok := sourceOf(part, x, y)
+			if !ok {
+				continue
+			}
+			shown++
+			wx, wy, _ := sourceOf(whole, 16+x, 12+y)
+			if sx != wx || sy != wy {
+				t.Fatalf("%d,%d shows %d,%d, want %d,%d", x, y, sx, sy, wx, wy)
+			}
+		}
+	}
+	if shown == 0 {
+		t.Fatal("cropped picture is all black")
+	}
+}
+
+func TestRemapper_TableReuse(t *testing.T) {
+	var r Remapper
+	r.SetParams(Params{K1: -0.2})
+	frame := coordFrame(32, 24)
+	dst := r.Apply(frame, camera.Crop{}, nil)
+	tbl := r.table
+
+	if again := r.Apply(frame, camera.Crop{}, dst); again != dst || r.table != tbl {
+		t.Error("same layout did not reuse the buffer and table")
+	}
+	r.Apply(coordFrame(40, 24), camera.Crop{}, dst)
+	if r.table == tbl {
+		t.Error("new size kept the old table")
+	}
+	tbl = r.table
+	r.SetParams(Params{K1: -0.2})
+	if r.table != tbl {
+		t.Error("unchanged params dropped the table")
+	}
+	r.SetParams(Params{K1: -0.3})
+	if r.table != nil {
+		t.Error("new params kept the old table")
+	}
+
+	// Off: a plain copy
+	r.SetParams(Params{})
+	out := r.Apply(frame, camera.Crop{}, nil)
+	if sx, sy, ok := sourceOf(out, 3, 20); !ok || sx != 3 || sy != 20 {
+		t.Errorf("disabled remapper moved pixels: 3,20 shows %d,%d", sx, sy)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rear.cal")
+	data := "# Rear fisheye\nk1 = -0.31\nk2: 0.07\n\n; centre\ncx = 0.52\nscale = 0.9\nmodel = generic\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if want := (Params{K1: -0.31, K2: 0.07, CX: 0.52, Scale: 0.9}); p != want {
+		t.Errorf("params = %+v, want %+v", p, want)
+	}
+
+	for _, bad := range []string{"k1 = lots\n", "k1\n", "cx = 1.5\n", "scale = -1\n"} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("LoadFile(%q) succeeded", bad)
+		}
+	}
+	if _, err := LoadFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadFile of a missing file succeeded")
+	}
+}
+
+func BenchmarkRemapper_YCbCr1280x720(b *testing.B) {
+	frame := image.NewYCbCr(image.Rect(0, 0, 1280, 720), image.YCbCrSubsampleRatio420)
+	var r Remapper
+	r.SetParams(Params{K1: -0.3, K2: 0.05})
+	dst := r.Apply(frame, camera.Crop{}, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = r.Apply(frame, camera.Crop{}, dst)
+	}
+}
@@ -0,0 +1,124 @@
+// Package events is an in-process publish/subscribe bus for dashboard state
+// changes (camera connect/disconnect, restarts, hotplug, motion, triggers,
+// FPS and controller state), consumed by remote monitors through the HTTP server's event stream.
+//
+// Publish never blocks: each subscriber has a bounded queue, and events for a
+// subscriber that falls behind are dropped and counted. A nil *Bus is valid
+// and discards everything, so publishers need no "events enabled" checks.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Type identifies an event.
+type Type string
+
+// Event types
+const (
+	CameraConnected    Type = "camera_connected"
+	CameraDisconnected Type = "camera_disconnected"
+	CameraRestart      Type = "camera_restart"       // Restarted by stale-frame detection
+	CameraRestartLimit Type = "camera_restart_limit" // Restart limit reached, waiting for extended cooldown
+	CameraAdded        Type = "camera_added"         // New camera found by hotplug detection
+	MotionStart        Type = "motion_start"         // Motion detected on a camera
+	MotionEnd          Type = "motion_end"           // No motion for the hold time
+	TriggerActive      Type = "trigger_active"       // A [trigger.<name>] input became active
+	TriggerCleared     Type = "trigger_cleared"      // A trigger input cleared (after its hold time)
+	FPSChange          Type = "fps_change"           // Adaptive controller changed capture FPS
+	ControllerState    Type = "controller_state"     // Adaptive controller state change (e.g. Emergency)
+)
+
+// DefaultBuffer is the queue length of a subscription with buffer <= 0.
+const DefaultBuffer = 64
+
+// Event is one state change.
+type Event struct {
+	ID      uint64                 `json:"id"` // Assigned by Publish, increasing
+	Type    Type                   `json:"type"`
+	Time    time.Time              `json:"time"`
+	Slot    *int                   `json:"slot,omitempty"`   // Camera slot, for camera events
+	Camera  string                 `json:"camera,omitempty"` // Camera label, for camera events
+	Message string                 `json:"message,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"` // Type-specific details, e.g. "fps" or "state"
+}
+
+// Camera returns an event about the camera in slot.
+func Camera(t Type, slot int, label, message string) Event {
+	return Event{Type: t, Slot: &slot, Camera: label, Message: message}
+}
+
+// Bus delivers published events to all current subscribers.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	nextID uint64
+}
+
+// NewBus creates an empty bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Publish sends ev to every subscriber without blocking. ID and, if unset,
+// Time are filled in.
+func (b *Bus) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	ev.ID = b.nextID
+	for sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe returns a subscription receiving every event published from now
+// on. The caller must Close it.
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Subscription is one consumer's queue of events.
+type Subscription struct {
+	C <-chan Event // Closed by Close
+
+	ch      chan Event
+	bus     *Bus
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+// Close unsubscribes and closes C.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subs, s)
+		s.bus.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Dropped returns how many events were discarded because C was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestBus_DeliversToAllSubscribers(t *testing.T) {
+	b := NewBus()
+	s1 := b.Subscribe(4)
+	s2 := b.Subscribe(4)
+	defer s2.Close()
+
+	b.Publish(Camera(CameraDisconnected, 2, "Rear", "no frames"))
+	b.Publish(Event{Type: FPSChange, Data: map[string]interface{}{"fps": 10}})
+
+	for _, s := range []*Subscription{s1, s2} {
+		first := <-s.C
+		if first.ID != 1 || first.Type != CameraDisconnected || *first.Slot != 2 || first.Camera != "Rear" || first.Time.IsZero() {
+			t.Errorf("first event = %+v", first)
+		}
+		if second := <-s.C; second.ID != 2 || second.Type != FPSChange {
+			t.Errorf("second event = %+v", second)
+		}
+	}
+
+	s1.Close()
+	s1.Close() // Idempotent
+	if _, ok := <-s1.C; ok {
+		t.Error("closed subscription should have a closed channel")
+	}
+	b.Publish(Event{Type: CameraAdded}) // Must not panic on the closed subscription
+	if ev := <-s2.C; ev.Type != CameraAdded {
+		t.Errorf("s2 got %+v after s1 closed", ev)
+	}
+}
+
+func TestBus_SlowSubscriberDropsInsteadOfBlocking(t *testing.T) {
+	b := NewBus()
+	s := b.Subscribe(2)
+	defer s.Close()
+
+	for i := 0; i < 5; i++ {
+		b.Publish(Event{Type: CameraRestart})
+	}
+	if s.Dropped() != 3 {
+		t.Errorf("Dropped = %d, want 3", s.Dropped())
+	}
+	if ev := <-s.C; ev.ID != 1 {
+		t.Errorf("oldest queued event ID = %d, want 1", ev.ID)
+	}
+}
+
+func TestBus_NilBusDiscards(t *testing.T) {
+	var b *Bus
+	b.Publish(Event{Type: CameraAdded}) // No panic
+}
@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"fmt"
+	"image"
+	"strings"
+)
+
+// =============================================================================
+// Display Crop
+// =============================================================================
+// crop=<x> <y> <w> <h> shows only part of the picture it gets (normalized,
+// space-separated since commas separate filters), e.g. to hide the bumper on
+// the screen while recordings and motion detection keep the whole frame. A
+// crop that should apply everywhere belongs in the camera's crop key, which
+// runs at capture. Like zoom it uses SubImage, so nothing is copied. Like
+// mirror and flip it reports MovesPixels, which hides the zone outlines,
+// zone editor and "Keep as Crop" for the chain.
+// =============================================================================
+
+func init() {
+	Register("crop", newCrop)
+}
+
+type cropFilter struct {
+	crop camera.Crop
+}
+
+func newCrop(arg string, in Inputs) (Filter, error) {
+	fields := strings.Fields(arg)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("want crop=<x> <y> <w> <h>, got %q", arg)
+	}
+	c, err := camera.ParseCrop(strings.Join(fields, ","))
+	if err != nil {
+		return nil, err
+	}
+	return cropFilter{crop: c}, nil
+}
+
+func (f cropFilter) MovesPixels() bool { return true }
+
+func (f cropFilter) Apply(src image.Image) image.Image {
+	return f.crop.Apply(src)
+}
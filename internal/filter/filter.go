@@ -0,0 +1,179 @@
+// Package filter is the display filter pipeline. Every camera tile and the
+// fullscreen view run their frames through a Chain built from the "filters"
+// config key, e.g. "dewarp, gamma=1.2, nightmode, brightness". Filters are
+// found by name in a registry, so a new filter only needs a Register call
+// in its own file.
+//
+// A Filter owns the buffer it writes into and reuses it for the next frame,
+// so a chain serves one frame stream (one tile, or fullscreen) at a time.
+package filter
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/dewarp"
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+)
+
+// DefaultSpec is the chain used when none is configured.
+const DefaultSpec = "dewarp, zoom, nightmode, brightness"
+
+// Filter changes the displayed frames of one stream.
+type Filter interface {
+	// Apply returns src filtered: src itself when there is nothing to do,
+	// else a buffer the filter owns and overwrites on the next call.
+	Apply(src image.Image) image.Image
+}
+
+// Inputs are the runtime settings the filters read on every frame; nil
+// fields count as off. They are called from the frame loops, so they must
+// be cheap and safe for concurrent use.
+type Inputs struct {
+	NightMode  func() bool
+	Brightness func() int         // Percent, 100 = unchanged
+	Lens       *dewarp.Remapper   // Lens of the camera shown
+	Crop       func() camera.Crop // Part of the camera frame the frames show
+	View       func() camera.Crop // Zoomed part of the frame (fullscreen)
+}
+
+// mover is implemented by filters that can move pixels around, so that
+// positions in their output no longer match the camera frame.
+type mover interface {
+	MovesPixels() bool
+}
+
+// Factory makes a filter from its argument ("" when the spec has none).
+type Factory func(arg string, in Inputs) (Filter, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a filter available to Parse under name. Call it from init.
+func Register(name string, f Factory) {
+	if _, dup := registry[name]; dup {
+		panic("filter: " + name + " registered twice")
+	}
+	registry[name] = f
+}
+
+// Names returns the registered filter names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain runs frames through filters in order.
+type Chain struct {
+	specs   []string
+	filters []Filter
+}
+
+// Parse builds a chain from a spec: filter names separated by commas, each
+// optionally followed by "=argument". "" is DefaultSpec. When in has a View
+// and the spec has no zoom filter, one is added after dewarp (or first), so
+// fullscreen zoom keeps working with any chain.
+func Parse(spec string, in Inputs) (*Chain, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultSpec
+	}
+	var specs []string
+	for _, part := range strings.Split(spec, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			specs = append(specs, part)
+		}
+	}
+	if in.View != nil {
+		specs = withZoom(specs)
+	}
+
+	c := &Chain{}
+	for _, s := range specs {
+		name, arg, _ := strings.Cut(s, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		arg = strings.TrimSpace(arg)
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q (have %s)", name, strings.Join(Names(), ", "))
+		}
+		f, err := factory(arg, in)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", name, err)
+		}
+		if arg != "" {
+			name += "=" + arg
+		}
+		c.specs = append(c.specs, name)
+		c.filters = append(c.filters, f)
+	}
+	return c, nil
+}
+
+// withZoom adds "zoom" to specs when missing, after "dewarp" or first.
+func withZoom(specs []string) []string {
+	at := 0
+	for i, s := range specs {
+		name, _, _ := strings.Cut(s, "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "zoom":
+			return specs
+		case "dewarp":
+			at = i + 1
+		}
+	}
+	out := make([]string, 0, len(specs)+1)
+	out = append(out, specs[:at]...)
+	out = append(out, "zoom")
+	return append(out, specs[at:]...)
+}
+
+// Apply runs img through the filters in order.
+func (c *Chain) Apply(img image.Image) image.Image {
+	for _, f := range c.filters {
+		img = f.Apply(img)
+	}
+	return img
+}
+
+// MovesPixels reports whether a filter of the chain currently moves pixels
+// (e.g. dewarp with a lens set), so a position in the shown picture is not
+// the same position in the camera frame. Crops and motion zones are
+// measured on the camera frame, so they cannot be drawn or picked on such
+// a picture. zoom does not count; its view is known.
+func (c *Chain) MovesPixels() bool {
+	for _, f := range c.filters {
+		if m, ok := f.(mover); ok && m.MovesPixels() {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the chain in spec form.
+func (c *Chain) String() string {
+	return strings.Join(c.specs, ", ")
+}
+
+// noArg rejects an argument for filters that take none.
+func noArg(arg string) error {
+	if arg != "" {
+		return fmt.Errorf("takes no argument, got %q", arg)
+	}
+	return nil
+}
+
+// reuseRGBA returns dst resized to w*h at the origin, or a new image when
+// its buffer is too small.
+func reuseRGBA(dst *image.RGBA, w, h int) *image.RGBA {
+	if dst != nil && cap(dst.Pix) >= w*h*4 {
+		dst.Pix = dst.Pix[:w*h*4]
+		dst.Stride = w * 4
+		dst.Rect = image.Rect(0, 0, w, h)
+		return dst
+	}
+	return image.NewRGBA(image.Rect(0, 0, w, h))
+}
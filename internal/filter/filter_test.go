@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/dewarp"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// gradient returns a w*h RGBA image with R = 10*x, G = 10*y and B = 100.
+func gradient(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(10 * x), uint8(10 * y), 100, 255})
+		}
+	}
+	return img
+}
+
+func rgbaAt(img image.Image, x, y int) color.RGBA {
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func on() bool { return true }
+
+func TestParse(t *testing.T) {
+	c, err := Parse("", Inputs{})
+	if err != nil {
+		t.Fatalf("Parse(\"\"): %v", err)
+	}
+	if c.String() != DefaultSpec {
+		t.Errorf("empty spec = %q, want %q", c, DefaultSpec)
+	}
+
+	c, err = Parse(" Gamma=1.5 ,, nightmode, brightness=80% ", Inputs{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := "gamma=1.5, nightmode, brightness=80%"; c.String() != want {
+		t.Errorf("chain = %q, want %q", c, want)
+	}
+
+	for _, bad := range []string{"sepia", "gamma", "gamma=9", "contrast=x", "nightmode=1", "brightness=0", "zoom=2", "crop", "crop=0 0 1", "crop=0.5 0 1 1"} {
+		if _, err := Parse(bad, Inputs{}); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+	if _, err := Parse("sepia", Inputs{}); err == nil || !strings.Contains(err.Error(), "nightmode") {
+		t.Errorf("unknown filter error %v does not list the filters", err)
+	}
+}
+
+func TestParse_AddsZoom(t *testing.T) {
+	view := func() camera.Crop { return camera.Crop{} }
+	for spec, want := range map[string]string{
+		"nightmode":              "zoom, nightmode",
+		"gamma=2, dewarp, flip":  "gamma=2, dewarp, zoom, flip",
+		"brightness, zoom, flip": "brightness, zoom, flip",
+	} {
+		c, err := Parse(spec, Inputs{View: view})
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+		if c.String() != want {
+			t.Errorf("Parse(%q) = %q, want %q", spec, c, want)
+		}
+	}
+	// Tiles have no view and get no zoom
+	if c, _ := Parse("nightmode", Inputs{}); c.String() != "nightmode" {
+		t.Errorf("chain without view = %q", c)
+	}
+}
+
+func TestChain_Order(t *testing.T) {
+	src := gradient(4, 4)
+	in := Inputs{NightMode: on, Brightness: func() int { return 50 }}
+
+	// Both orders give a red picture, but rounding the dimmed colours
+	// before or after the grayscale conversion differs
+	a, _ := Parse("nightmode, brightness", in)
+	b, _ := Parse("brightness, nightmode", in)
+	pa, pb := rgbaAt(a.Apply(src), 3, 3), rgbaAt(b.Apply(src), 3, 3)
+	if pa.G != 0 || pb.G != 0 || pa.R == 0 {
+		t.Errorf("night mode output not red: %v / %v", pa, pb)
+	}
+	if pa == pb {
+		t.Errorf("order made no difference: both %v", pa)
+	}
+
+	// Off inputs pass frames through untouched, without copying
+	c, _ := Parse("nightmode, brightness, dewarp, zoom", Inputs{
+		NightMode:  func() bool { return false },
+		Brightness: func() int { return DefaultBrightness },
+	})
+	if out := c.Apply(src); out != image.Image(src) {
+		t.Error("idle chain copied the frame")
+	}
+}
+
+func TestChain_ReusesBuffers(t *testing.T) {
+	c, _ := Parse("gamma=2, mirror", Inputs{})
+	first := c.Apply(gradient(8, 6))
+	second := c.Apply(gradient(8, 6))
+	if first != second {
+		t.Error("second frame got a new buffer")
+	}
+	if third := c.Apply(gradient(4, 3)); third.Bounds() != image.Rect(0, 0, 4, 3) {
+		t.Errorf("smaller frame bounds = %v", third.Bounds())
+	}
+}
+
+func TestToneFilters(t *testing.T) {
+	src := gradient(2, 1) // R = 0 and 10, G = 0, B = 100
+	g, _ := Parse("gamma=2", Inputs{})
+	if p := rgbaAt(g.Apply(src), 0, 0); p.R != 0 || p.B != 160 {
+		t.Errorf("gamma 2 = %v, want R 0 and B 160 (255*sqrt(100/255))", p)
+	}
+	c, _ := Parse("contrast=2", Inputs{})
+	if p := rgbaAt(c.Apply(src), 1, 0); p.R != 0 || p.B != 72 {
+		t.Errorf("contrast 2 = %v, want R clamped to 0 and B 72", p)
+	}
+	if id, _ := Parse("gamma=1, contrast=1", Inputs{}); id.Apply(src) != image.Image(src) {
+		t.Error("identity tone curves copied the frame")
+	}
+}
+
+func TestFlipFilters(t *testing.T) {
+	src := gradient(3, 2)
+	m, _ := Parse("mirror", Inputs{})
+	out := m.Apply(src)
+	if p := rgbaAt(out, 0, 1); p.R != 20 || p.G != 10 {
+		t.Errorf("mirror 0,1 = %v, want the pixel from 2,1", p)
+	}
+	f, _ := Parse("flip", Inputs{})
+	out = f.Apply(src)
+	if p := rgbaAt(out, 2, 0); p.R != 20 || p.G != 10 {
+		t.Errorf("flip 2,0 = %v, want the pixel from 2,1", p)
+	}
+
+	// Works on YCbCr frames and sub-images too
+	ycc := image.NewYCbCr(image.Rect(0, 0, 4, 4), image.YCbCrSubsampleRatio444)
+	for i := range ycc.Y {
+		ycc.Y[i], ycc.Cb[i], ycc.Cr[i] = uint8(i*10), 128, 128
+	}
+	sub := ycc.SubImage(image.Rect(1, 1, 3, 3))
+	out = m.Apply(sub)
+	if out.Bounds() != image.Rect(0, 0, 2, 2) || rgbaAt(out, 0, 0) != rgbaAt(sub, 2, 1) {
+		t.Errorf("mirrored sub-image: bounds %v, 0,0 = %v, want %v", out.Bounds(), rgbaAt(out, 0, 0), rgbaAt(sub, 2, 1))
+	}
+}
+
+func TestLensFilters(t *testing.T) {
+	lens := &dewarp.Remapper{}
+	var view camera.Crop
+	c, _ := Parse("dewarp, zoom", Inputs{Lens: lens, View: func() camera.Crop { return view }})
+	src := gradient(8, 8)
+	if c.Apply(src) != image.Image(src) {
+		t.Error("dewarp without a lens or zoom changed the frame")
+	}
+	if c.MovesPixels() {
+		t.Error("dewarp without a lens moves pixels")
+	}
+
+	lens.SetParams(dewarp.Params{K1: -0.3})
+	if !c.MovesPixels() {
+		t.Error("dewarp with a lens does not move pixels")
+	}
+	view = camera.Crop{X: 0.5, Y: 0.5, W: 0.5, H: 0.5}
+	out := c.Apply(src)
+	if out.Bounds().Dx() != 4 || out.Bounds().Dy() != 4 {
+		t.Errorf("zoomed bounds = %v, want 4x4", out.Bounds())
+	}
+	if _, ok := out.(*image.RGBA); !ok {
+		t.Errorf("dewarped frame is %T, want *image.RGBA", out)
+	}
+}
+
+func TestChain_MovesPixels(t *testing.T) {
+	for spec, want := range map[string]bool{
+		"nightmode, brightness, gamma=1.2": false,
+		"dewarp, zoom":                     false, // No lens
+		"mirror":                           true,
+		"nightmode, flip":                  true,
+		"crop=0 0 1 0.8":                   true,
+	} {
+		c, err := Parse(spec, Inputs{})
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", spec, err)
+		}
+		if got := c.MovesPixels(); got != want {
+			t.Errorf("Parse(%q).MovesPixels() = %v, want %v", spec, got, want)
+		}
+	}
+}
+
+func TestCropFilter(t *testing.T) {
+	c, err := Parse("crop=0.25 0.5 0.5 0.5, mirror", Inputs{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if want := "crop=0.25 0.5 0.5 0.5, mirror"; c.String() != want {
+		t.Errorf("chain = %q, want %q", c, want)
+	}
+	src := gradient(8, 4)
+	out := c.Apply(src)
+	if out.Bounds() != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("bounds = %v, want 4x2", out.Bounds())
+	}
+	// Mirrored, the left edge of the output is the right edge of the crop (5,3)
+	if p := rgbaAt(out, 0, 1); p.R != 50 || p.G != 30 {
+		t.Errorf("0,1 = %v, want the pixel from 5,3", p)
+	}
+
+	// On its own it shares the frame's pixels
+	c, _ = Parse("crop=0 0 0.5 1", Inputs{})
+	sub, ok := c.Apply(src).(*image.RGBA)
+	if !ok || &sub.Pix[0] != &src.Pix[0] {
+		t.Error("crop copied the frame")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-zoom", newZoom)
+	defer delete(registry, "test-zoom")
+
+	if c, err := Parse("test-zoom", Inputs{}); err != nil || c.String() != "test-zoom" {
+		t.Errorf("Parse(test-zoom) = %v, %v", c, err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice did not panic")
+		}
+	}()
+	Register("nightmode", newNightMode)
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"image"
+	"image/draw"
+)
+
+// =============================================================================
+// Display Mirror / Flip
+// =============================================================================
+// mirror and flip turn only the displayed picture, e.g. to show a rear
+// camera like a rear-view mirror while recordings and motion zones keep the
+// camera's own orientation. Since the shown picture then no longer lines
+// up with the camera frame, the chain reports MovesPixels and the UI offers
+// no zone outlines, zone editor or "Keep as Crop" for it. Orientation that
+// should apply everywhere belongs in the camera's mirror/flip/rotation keys
+// instead, which run at capture.
+// =============================================================================
+
+func init() {
+	Register("mirror", func(arg string, in Inputs) (Filter, error) {
+		if err := noArg(arg); err != nil {
+			return nil, err
+		}
+		return &flipFilter{horizontal: true}, nil
+	})
+	Register("flip", func(arg string, in Inputs) (Filter, error) {
+		if err := noArg(arg); err != nil {
+			return nil, err
+		}
+		return &flipFilter{vertical: true}, nil
+	})
+}
+
+// flipFilter swaps left/right (horizontal) or top/bottom (vertical).
+type flipFilter struct {
+	horizontal, vertical bool
+	buf                  *image.RGBA
+}
+
+func (f *flipFilter) MovesPixels() bool { return true }
+
+func (f *flipFilter) Apply(src image.Image) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	f.buf = reuseRGBA(f.buf, w, h)
+	draw.Draw(f.buf, f.buf.Rect, src, b.Min, draw.Src) // Fast path for YCbCr and RGBA
+
+	pix, stride := f.buf.Pix, f.buf.Stride
+	if f.horizontal {
+		for y := 0; y < h; y++ {
+			row := pix[y*stride : y*stride+w*4]
+			for l, r := 0, (w-1)*4; l < r; l, r = l+4, r-4 {
+				row[l], row[l+1], row[l+2], row[l+3], row[r], row[r+1], row[r+2], row[r+3] =
+					row[r], row[r+1], row[r+2], row[r+3], row[l], row[l+1], row[l+2], row[l+3]
+			}
+		}
+	}
+	if f.vertical {
+		for t, u := 0, h-1; t < u; t, u = t+1, u-1 {
+			top, bottom := pix[t*stride:t*stride+w*4], pix[u*stride:u*stride+w*4]
+			for i := range top {
+				top[i], bottom[i] = bottom[i], top[i]
+			}
+		}
+	}
+	return f.buf
+}
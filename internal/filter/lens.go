@@ -0,0 +1,66 @@
+package filter
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"image"
+)
+
+// =============================================================================
+// Lens Correction and Zoom
+// =============================================================================
+// dewarp straightens fisheye distortion with the Inputs.Lens remapper (the
+// lens of the camera shown, see dewarp.Remapper). zoom cuts the fullscreen
+// view out of the frame with SubImage, no copy; it must follow dewarp, which
+// needs the whole frame around the lens centre.
+// =============================================================================
+
+func init() {
+	Register("dewarp", newDewarp)
+	Register("zoom", newZoom)
+}
+
+type dewarpFilter struct {
+	in  Inputs
+	buf *image.RGBA
+}
+
+func newDewarp(arg string, in Inputs) (Filter, error) {
+	if err := noArg(arg); err != nil {
+		return nil, err
+	}
+	return &dewarpFilter{in: in}, nil
+}
+
+func (f *dewarpFilter) Apply(src image.Image) image.Image {
+	if f.in.Lens == nil || !f.in.Lens.Enabled() {
+		return src
+	}
+	var crop camera.Crop
+	if f.in.Crop != nil {
+		crop = f.in.Crop()
+	}
+	f.buf = f.in.Lens.Apply(src, crop, f.buf)
+	return f.buf
+}
+
+func (f *dewarpFilter) MovesPixels() bool {
+	return f.in.Lens != nil && f.in.Lens.Enabled()
+}
+
+type zoomFilter struct {
+	view func() camera.Crop
+}
+
+func newZoom(arg string, in Inputs) (Filter, error) {
+	if err := noArg(arg); err != nil {
+		return nil, err
+	}
+	return zoomFilter{view: in.View}, nil
+}
+
+func (f zoomFilter) Apply(src image.Image) image.Image {
+	if f.view == nil {
+		return src
+	}
+	return f.view().Apply(src)
+}
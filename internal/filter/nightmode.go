@@ -0,0 +1,288 @@
+package filter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// Night Mode Filter
+// =============================================================================
+// Red-tinted, brightness-enhanced rendering for night driving.
+// Matches Python's LUT-based grayscale-to-red conversion (1.6x brightness gain).
+// Algorithm:
+//   1. Convert pixel to grayscale luminance
+//   2. Apply 1.6x brightness boost (clamped to 255)
+//   3. Map result to red channel only (R = boosted, G = 0, B = 0)
+// =============================================================================
+
+// nightModeLUT is a pre-computed lookup table: grayscale value -> boosted value.
+// Equivalent to Python's: np.clip(np.arange(256) * 1.6, 0, 255).astype(np.uint8)
+var nightModeLUT [256]uint8
+var brightnessLUTs = map[int][256]uint8{}
+
+func buildBrightnessLUT(factor float64) [256]uint8 {
+	if factor <= 0 {
+		factor = 1.0
+	}
+	var lut [256]uint8
+	for i := 0; i < 256; i++ {
+		v := float64(i) * factor
+		if v > 255 {
+			v = 255
+		}
+		lut[i] = uint8(v)
+	}
+	return lut
+}
+
+func init() {
+	for i := 0; i < 256; i++ {
+		v := float64(i) * 1.6
+		if v > 255 {
+			v = 255
+		}
+		nightModeLUT[i] = uint8(v)
+	}
+
+	for _, pct := range []int{15, 60, 80, 100, 150} {
+		brightnessLUTs[pct] = buildBrightnessLUT(float64(pct) / 100.0)
+	}
+}
+
+// applyNightMode converts an image to a red-tinted night vision image.
+// It converts to grayscale, applies 1.6x brightness gain, and maps
+// the result to the red channel only.
+//
+// If dst is non-nil and has sufficient capacity, it will be reused to avoid
+// allocation. The filters keep their buffer (and pixelTask) for this purpose.
+//
+// *image.YCbCr (what jpeg.Decode returns) takes the luminance straight from
+// the Y plane; *image.RGBA and *image.NRGBA have fast paths too, and other
+// types fall back to the generic color.Model interface.
+func applyNightMode(src image.Image) *image.RGBA {
+	return applyNightModeReuse(src, nil)
+}
+
+// applyNightModeReuse is like applyNightMode but reuses dst if possible.
+func applyNightModeReuse(src image.Image, dst *image.RGBA) *image.RGBA {
+	t := &pixelTask{night: true}
+	return t.run(src, dst)
+}
+
+// pixelTask writes a frame into an RGBA buffer, pixel by pixel, either as
+// night mode or through a per-channel LUT. Its rows run in parallel bands
+// (see runRows); a filter keeps one and reuses it for every frame.
+type pixelTask struct {
+	night bool       // Night mode instead of lut
+	lut   [256]uint8 // Per-channel table, when !night
+	src   image.Image
+	dst   *image.RGBA // w*h at the origin; row y is row Min.Y+y of src
+	wg    sync.WaitGroup
+}
+
+// run filters src into dst (reused when large enough) and returns it.
+func (t *pixelTask) run(src image.Image, dst *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst = reuseRGBA(dst, b.Dx(), b.Dy())
+	t.src, t.dst = src, dst
+	runRows(t, b.Dy(), &t.wg)
+	t.src, t.dst = nil, nil // Don't keep the frame alive
+	return dst
+}
+
+func (t *pixelTask) doRows(y0, y1 int) {
+	switch s := t.src.(type) {
+	case *image.YCbCr:
+		t.ycbcrRows(s, y0, y1)
+	case *image.RGBA:
+		t.pixRows(s.Pix, s.Stride, s.PixOffset(s.Rect.Min.X, s.Rect.Min.Y), y0, y1)
+	case *image.NRGBA:
+		t.pixRows(s.Pix, s.Stride, s.PixOffset(s.Rect.Min.X, s.Rect.Min.Y), y0, y1)
+	default:
+		t.genericRows(y0, y1)
+	}
+}
+
+// pixRows handles 4-byte RGBA-ordered pixels starting at pix[base].
+func (t *pixelTask) pixRows(pix []uint8, stride, base, y0, y1 int) {
+	w := t.dst.Rect.Dx()
+	for y := y0; y < y1; y++ {
+		src := pix[base+y*stride : base+y*stride+w*4]
+		dst := t.dst.Pix[y*t.dst.Stride : y*t.dst.Stride+w*4]
+		if t.night {
+			for i := 0; i < len(src); i += 4 {
+				gray := uint8((299*uint32(src[i]) + 587*uint32(src[i+1]) + 114*uint32(src[i+2])) / 1000)
+				dst[i], dst[i+1], dst[i+2], dst[i+3] = nightModeLUT[gray], 0, 0, 255
+			}
+			continue
+		}
+		for i := 0; i < len(src); i += 4 {
+			dst[i], dst[i+1], dst[i+2], dst[i+3] = t.lut[src[i]], t.lut[src[i+1]], t.lut[src[i+2]], 255
+		}
+	}
+}
+
+// ycbcrRows reads the planes directly: night mode only needs Y, which is
+// the BT.601 luminance; the LUT converts each pixel to RGB on the fly.
+func (t *pixelTask) ycbcrRows(s *image.YCbCr, y0, y1 int) {
+	r := s.Rect
+	w := r.Dx()
+	hdiv, vdiv := chromaDivisors(s.SubsampleRatio)
+	for y := y0; y < y1; y++ {
+		sy := r.Min.Y + y
+		yRow := s.Y[y*s.YStride : y*s.YStride+w]
+		dst := t.dst.Pix[y*t.dst.Stride : y*t.dst.Stride+w*4]
+		if t.night {
+			for x, v := range yRow {
+				i := x * 4
+				dst[i], dst[i+1], dst[i+2], dst[i+3] = nightModeLUT[v], 0, 0, 255
+			}
+			continue
+		}
+		// Same chroma offsets as image.YCbCr.COffset, without its per-pixel switch
+		cRow := (sy/vdiv - r.Min.Y/vdiv) * s.CStride
+		for x, v := range yRow {
+			c := cRow + (r.Min.X+x)/hdiv - r.Min.X/hdiv
+			red, green, blue := color.YCbCrToRGB(v, s.Cb[c], s.Cr[c])
+			i := x * 4
+			dst[i], dst[i+1], dst[i+2], dst[i+3] = t.lut[red], t.lut[green], t.lut[blue], 255
+		}
+	}
+}
+
+// chromaDivisors returns how many pixels share a chroma sample across and
+// down for a subsample ratio.
+func chromaDivisors(r image.YCbCrSubsampleRatio) (h, v int) {
+	switch r {
+	case image.YCbCrSubsampleRatio422:
+		return 2, 1
+	case image.YCbCrSubsampleRatio420:
+		return 2, 2
+	case image.YCbCrSubsampleRatio440:
+		return 1, 2
+	case image.YCbCrSubsampleRatio411:
+		return 4, 1
+	case image.YCbCrSubsampleRatio410:
+		return 4, 2
+	}
+	return 1, 1
+}
+
+// genericRows goes through the color.Model interface for other types.
+func (t *pixelTask) genericRows(y0, y1 int) {
+	b := t.src.Bounds()
+	for y := y0; y < y1; y++ {
+		dst := t.dst.Pix[y*t.dst.Stride:]
+		for x := 0; x < b.Dx(); x++ {
+			r, g, bl, _ := t.src.At(x+b.Min.X, y+b.Min.Y).RGBA()
+			// Convert to 8-bit
+			r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(bl>>8)
+			i := x * 4
+			if t.night {
+				// ITU-R BT.601 luminance
+				gray := uint8((299*uint32(r8) + 587*uint32(g8) + 114*uint32(b8)) / 1000)
+				dst[i], dst[i+1], dst[i+2], dst[i+3] = nightModeLUT[gray], 0, 0, 255
+			} else {
+				dst[i], dst[i+1], dst[i+2], dst[i+3] = t.lut[r8], t.lut[g8], t.lut[b8], 255
+			}
+		}
+	}
+}
+
+// nightModeColor returns the night-mode equivalent of a single color.
+// Useful for UI elements that should respect night mode.
+func nightModeColor(c color.Color) color.RGBA {
+	r, g, b, _ := c.RGBA()
+	r8 := uint8(r >> 8)
+	g8 := uint8(g >> 8)
+	b8 := uint8(b >> 8)
+	gray := uint8((299*uint32(r8) + 587*uint32(g8) + 114*uint32(b8)) / 1000)
+	boosted := nightModeLUT[gray]
+	return color.RGBA{R: boosted, G: 0, B: 0, A: 255}
+}
+
+func brightnessLUTForPercent(percent int) [256]uint8 {
+	if lut, ok := brightnessLUTs[percent]; ok {
+		return lut
+	}
+	return buildBrightnessLUT(float64(percent) / 100.0)
+}
+
+func applyBrightnessPercentReuse(src image.Image, percent int, dst *image.RGBA) *image.RGBA {
+	lut := brightnessLUTForPercent(percent)
+	return applyBrightnessLUTReuse(src, lut, dst)
+}
+
+// applyBrightnessLUTReuse maps every colour channel of src through lut
+// into dst (reused when large enough).
+func applyBrightnessLUTReuse(src image.Image, lut [256]uint8, dst *image.RGBA) *image.RGBA {
+	t := &pixelTask{lut: lut}
+	return t.run(src, dst)
+}
+
+// DefaultBrightness is the brightness percent that leaves frames unchanged.
+const DefaultBrightness = 100
+
+func init() {
+	Register("nightmode", newNightMode)
+	Register("brightness", newBrightness)
+}
+
+// nightModeFilter applies night mode while the night mode input is on.
+type nightModeFilter struct {
+	enabled func() bool
+	task    pixelTask
+	buf     *image.RGBA
+}
+
+func newNightMode(arg string, in Inputs) (Filter, error) {
+	if err := noArg(arg); err != nil {
+		return nil, err
+	}
+	return &nightModeFilter{enabled: in.NightMode, task: pixelTask{night: true}}, nil
+}
+
+func (f *nightModeFilter) Apply(src image.Image) image.Image {
+	if f.enabled == nil || !f.enabled() {
+		return src
+	}
+	f.buf = f.task.run(src, f.buf)
+	return f.buf
+}
+
+// brightnessFilter scales the colour channels by the brightness input, or
+// by a fixed percent given as its argument.
+type brightnessFilter struct {
+	percent func() int
+	task    pixelTask
+	buf     *image.RGBA
+}
+
+func newBrightness(arg string, in Inputs) (Filter, error) {
+	if arg == "" {
+		return &brightnessFilter{percent: in.Brightness}, nil
+	}
+	pct, err := strconv.Atoi(strings.TrimSuffix(arg, "%"))
+	if err != nil || pct < 1 || pct > 400 {
+		return nil, fmt.Errorf("want a percent from 1 to 400, got %q", arg)
+	}
+	return &brightnessFilter{percent: func() int { return pct }}, nil
+}
+
+func (f *brightnessFilter) Apply(src image.Image) image.Image {
+	if f.percent == nil {
+		return src
+	}
+	pct := f.percent()
+	if pct == DefaultBrightness || pct <= 0 {
+		return src
+	}
+	f.task.lut = brightnessLUTForPercent(pct)
+	f.buf = f.task.run(src, f.buf)
+	return f.buf
+}
@@ -1,8 +1,10 @@
-package ui
+package filter
 
 import (
 	"image"
 	"image/color"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -209,3 +211,133 @@ func TestApplyBrightnessPercentReuse(t *testing.T) {
 			uint8(r>>8), uint8(g>>8), uint8(b>>8))
 	}
 }
+
+// opaque hides an image's concrete type, forcing the generic At() path.
+type opaque struct{ image.Image }
+
+// patternYCbCr returns a YCbCr frame with varying luma and chroma.
+func patternYCbCr(r image.Rectangle, ratio image.YCbCrSubsampleRatio) *image.YCbCr {
+	img := image.NewYCbCr(r, ratio)
+	for i := range img.Y {
+		img.Y[i] = uint8(i * 7)
+	}
+	for i := range img.Cb {
+		img.Cb[i], img.Cr[i] = uint8(90+i*3), uint8(200-i*5)
+	}
+	return img
+}
+
+func TestApplyNightMode_YCbCr(t *testing.T) {
+	// A sub-image with odd bounds, as a crop produces
+	src := patternYCbCr(image.Rect(0, 0, 9, 7), image.YCbCrSubsampleRatio420).SubImage(image.Rect(1, 1, 8, 6)).(*image.YCbCr)
+	dst := applyNightMode(src)
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 7; x++ {
+			want := nightModeLUT[src.Y[src.YOffset(x+1, y+1)]]
+			if got := dst.RGBAAt(x, y); got != (color.RGBA{want, 0, 0, 255}) {
+				t.Fatalf("pixel %d,%d = %v, want R %d from luma", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestApplyBrightness_YCbCrMatchesGeneric(t *testing.T) {
+	ratios := []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444, image.YCbCrSubsampleRatio422, image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440, image.YCbCrSubsampleRatio411, image.YCbCrSubsampleRatio410,
+	}
+	for _, ratio := range ratios {
+		full := patternYCbCr(image.Rect(0, 0, 13, 9), ratio)
+		for _, src := range []*image.YCbCr{full, full.SubImage(image.Rect(3, 1, 12, 8)).(*image.YCbCr)} {
+			got := applyBrightnessPercentReuse(src, 150, nil)
+			want := applyBrightnessPercentReuse(opaque{src}, 150, nil)
+			for i := range want.Pix {
+				if got.Pix[i] != want.Pix[i] {
+					t.Fatalf("%v %v: byte %d = %d, want %d", ratio, src.Rect, i, got.Pix[i], want.Pix[i])
+				}
+			}
+		}
+	}
+}
+
+// countRows records how often each row is processed.
+type countRows struct{ rows []int32 }
+
+func (c *countRows) doRows(y0, y1 int) {
+	for y := y0; y < y1; y++ {
+		atomic.AddInt32(&c.rows[y], 1)
+	}
+}
+
+func TestRunBands(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, tc := range []struct{ h, bands int }{{200, 4}, {200, 3}, {33, 8}, {1, 4}, {0, 4}} {
+		c := &countRows{rows: make([]int32, tc.h)}
+		runBands(c, tc.h, tc.bands, &wg)
+		for y, n := range c.rows {
+			if n != 1 {
+				t.Fatalf("h=%d bands=%d: row %d ran %d times", tc.h, tc.bands, y, n)
+			}
+		}
+	}
+
+	// A banded frame matches a single pass
+	src := patternYCbCr(image.Rect(0, 0, 64, 256), image.YCbCrSubsampleRatio420)
+	want := applyBrightnessPercentReuse(src, 60, nil)
+	task := &pixelTask{lut: brightnessLUTs[60], src: src, dst: image.NewRGBA(image.Rect(0, 0, 64, 256))}
+	runBands(task, 256, 4, &task.wg)
+	for i := range want.Pix {
+		if task.dst.Pix[i] != want.Pix[i] {
+			t.Fatalf("banded byte %d = %d, want %d", i, task.dst.Pix[i], want.Pix[i])
+		}
+	}
+}
+
+func TestFilters_NoAllocs(t *testing.T) {
+	src := patternYCbCr(image.Rect(0, 0, 320, 240), image.YCbCrSubsampleRatio420)
+	night, _ := newNightMode("", Inputs{NightMode: func() bool { return true }})
+	bright, _ := newBrightness("150", Inputs{})
+	for name, f := range map[string]Filter{"nightmode": night, "brightness": bright} {
+		f.Apply(src) // Allocates the buffer
+		if n := testing.AllocsPerRun(20, func() { f.Apply(src) }); n != 0 {
+			t.Errorf("%s: %v allocations per frame, want 0", name, n)
+		}
+	}
+}
+
+// benchPixelTask compares, on a 1280x720 4:2:0 frame, the generic At()
+// path YCbCr frames used to take, the direct YCbCr path on one goroutine,
+// and the direct path in row bands on the worker pool (one per CPU; run
+// with -cpu 1,4 to compare core counts).
+func benchPixelTask(b *testing.B, night bool, lut [256]uint8) {
+	frame := patternYCbCr(image.Rect(0, 0, 1280, 720), image.YCbCrSubsampleRatio420)
+	single := func(src image.Image) func(b *testing.B) {
+		return func(b *testing.B) {
+			t := &pixelTask{night: night, lut: lut, src: src, dst: image.NewRGBA(frame.Rect)}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				t.doRows(0, 720)
+			}
+		}
+	}
+	b.Run("generic", single(opaque{frame}))
+	b.Run("serial", single(frame))
+	b.Run("bands", func(b *testing.B) {
+		t := &pixelTask{night: night, lut: lut}
+		dst := t.run(frame, nil)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			dst = t.run(frame, dst)
+		}
+	})
+}
+
+func BenchmarkNightMode_YCbCr720p(b *testing.B) {
+	benchPixelTask(b, true, [256]uint8{})
+}
+
+func BenchmarkBrightness_YCbCr720p(b *testing.B) {
+	benchPixelTask(b, false, brightnessLUTs[150])
+}
@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"runtime"
+	"sync"
+)
+
+// =============================================================================
+// Row-Band Worker Pool
+// =============================================================================
+// Per-pixel filters split a frame into horizontal bands and run them on a
+// fixed set of worker goroutines (one per CPU, shared by all chains), so a
+// 4-core Pi filters a frame on 4 cores instead of one. The calling goroutine
+// filters the first band itself and then waits for the others, so a busy
+// pool slows a frame down but never deadlocks it. Tasks and their
+// WaitGroups live in the filters and are reused, so a frame allocates
+// nothing.
+// =============================================================================
+
+// minBandRows is the smallest band worth handing to another goroutine.
+const minBandRows = 32
+
+// rowTask is per-pixel work that can run on any row range independently.
+type rowTask interface {
+	doRows(y0, y1 int)
+}
+
+type rowJob struct {
+	task   rowTask
+	y0, y1 int
+	wg     *sync.WaitGroup
+}
+
+var (
+	poolOnce    sync.Once
+	poolJobs    chan rowJob
+	poolWorkers int
+)
+
+// startPool starts one worker per CPU, less the caller (but at least one,
+// so runBands works on a single CPU too).
+func startPool() {
+	poolWorkers = runtime.NumCPU() - 1
+	if poolWorkers < 1 {
+		poolWorkers = 1
+	}
+	poolJobs = make(chan rowJob, 4*poolWorkers)
+	for i := 0; i < poolWorkers; i++ {
+		go func() {
+			for j := range poolJobs {
+				j.task.doRows(j.y0, j.y1)
+				j.wg.Done()
+			}
+		}()
+	}
+}
+
+// runRows runs t over rows [0, h) in one band per usable CPU and returns
+// when all are done. wg is only used during the call; callers keep one per
+// task so nothing is allocated.
+func runRows(t rowTask, h int, wg *sync.WaitGroup) {
+	runBands(t, h, runtime.GOMAXPROCS(0), wg)
+}
+
+// runBands is runRows with a given number of bands.
+func runBands(t rowTask, h, bands int, wg *sync.WaitGroup) {
+	if limit := h / minBandRows; bands > limit {
+		bands = limit
+	}
+	if bands <= 1 {
+		t.doRows(0, h)
+		return
+	}
+	poolOnce.Do(startPool)
+	wg.Add(bands - 1)
+	for i := 1; i < bands; i++ {
+		poolJobs <- rowJob{task: t, y0: h * i / bands, y1: h * (i + 1) / bands, wg: wg}
+	}
+	t.doRows(0, h/bands)
+	wg.Wait()
+}
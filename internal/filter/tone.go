@@ -0,0 +1,84 @@
+package filter
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+)
+
+// =============================================================================
+// Tone Curves
+// =============================================================================
+// gamma=<g> and contrast=<c> map each colour channel through a lookup table
+// built once from their argument, like the brightness presets:
+//   gamma:    out = 255 * (in/255)^(1/g), g > 1 lifts shadows
+//   contrast: out = (in - 128) * c + 128, c > 1 spreads the tones apart
+// =============================================================================
+
+func init() {
+	Register("gamma", newGamma)
+	Register("contrast", newContrast)
+}
+
+// lutFilter maps every colour channel through a fixed table.
+type lutFilter struct {
+	task     pixelTask // Holds the table
+	identity bool
+	buf      *image.RGBA
+}
+
+func newLUTFilter(fn func(v float64) float64) *lutFilter {
+	f := &lutFilter{identity: true}
+	for i := range f.task.lut {
+		v := math.Round(fn(float64(i)))
+		if v < 0 {
+			v = 0
+		}
+		if v > 255 {
+			v = 255
+		}
+		f.task.lut[i] = uint8(v)
+		if f.task.lut[i] != uint8(i) {
+			f.identity = false
+		}
+	}
+	return f
+}
+
+func (f *lutFilter) Apply(src image.Image) image.Image {
+	if f.identity {
+		return src
+	}
+	f.buf = f.task.run(src, f.buf)
+	return f.buf
+}
+
+// parseFactor parses a filter argument between lo and hi.
+func parseFactor(arg string, lo, hi float64) (float64, error) {
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("want a number from %g to %g, got %q", lo, hi, arg)
+	}
+	return v, nil
+}
+
+func newGamma(arg string, in Inputs) (Filter, error) {
+	g, err := parseFactor(arg, 0.1, 5)
+	if err != nil {
+		return nil, err
+	}
+	return newLUTFilter(func(v float64) float64 {
+		return 255 * math.Pow(v/255, 1/g)
+	}), nil
+}
+
+func newContrast(arg string, in Inputs) (Filter, error) {
+	c, err := parseFactor(arg, 0, 4)
+	if err != nil {
+		return nil, err
+	}
+	return newLUTFilter(func(v float64) float64 {
+		return (v-128)*c + 128
+	}), nil
+}
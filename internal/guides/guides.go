@@ -0,0 +1,86 @@
+// Package guides computes reverse-camera parking guide lines: two rails from
+// the bottom of the image towards the horizon, split into distance bands
+// (red near the vehicle, then yellow, then green) with a crossbar at each
+// band boundary. A steering input bends the rails sideways, growing with
+// distance like the path of the wheels.
+//
+// Coordinates are normalized (0,0 top-left, 1,1 bottom-right), so the UI
+// scales the segments to the widget and draws them as vector lines instead
+// of touching frame pixels.
+package guides
+
+// Bands, from the vehicle outwards
+const (
+	BandNear = iota // Red
+	BandMid         // Yellow
+	BandFar         // Green
+)
+
+// curveSteps is the number of straight pieces each rail band is drawn with.
+const curveSteps = 6
+
+// Config places the guides in the image.
+type Config struct {
+	CenterX   float64   // Horizontal centre of the guides
+	NearY     float64   // Near end (close to the vehicle), usually near the bottom
+	FarY      float64   // Far end, towards the horizon
+	NearWidth float64   // Distance between the rails at NearY
+	FarWidth  float64   // Distance between the rails at FarY
+	Bands     []float64 // Band boundaries as fractions (0-1) of the guide length, ascending
+	Bend      float64   // Sideways shift of the far end at full steering lock
+}
+
+// Segment is one straight piece of a guide line.
+type Segment struct {
+	X1, Y1, X2, Y2 float64
+	Band           int // BandNear, BandMid or BandFar (later bands count as far)
+}
+
+// Lines returns the guide segments for steering from -1 (full left) to 1
+// (full right); values outside are clamped.
+func Lines(cfg Config, steering float64) []Segment {
+	if steering < -1 {
+		steering = -1
+	}
+	if steering > 1 {
+		steering = 1
+	}
+	bounds := append([]float64{0}, cfg.Bands...)
+	bounds = append(bounds, 1)
+
+	var segs []Segment
+	for b := 0; b+1 < len(bounds); b++ {
+		band := b
+		if band > BandFar {
+			band = BandFar
+		}
+		t0, t1 := bounds[b], bounds[b+1]
+		if t1 <= t0 {
+			continue
+		}
+		// Rails
+		for _, side := range []float64{-1, 1} {
+			for i := 0; i < curveSteps; i++ {
+				a := t0 + (t1-t0)*float64(i)/curveSteps
+				c := t0 + (t1-t0)*float64(i+1)/curveSteps
+				x1, y1 := point(cfg, steering, side, a)
+				x2, y2 := point(cfg, steering, side, c)
+				segs = append(segs, Segment{x1, y1, x2, y2, band})
+			}
+		}
+		// Crossbar at the far edge of the band
+		x1, y1 := point(cfg, steering, -1, t1)
+		x2, y2 := point(cfg, steering, 1, t1)
+		segs = append(segs, Segment{x1, y1, x2, y2, band})
+	}
+	return segs
+}
+
+// point returns a rail position at fraction t of the guide length; side is
+// -1 for the left rail and 1 for the right one.
+func point(cfg Config, steering, side, t float64) (float64, float64) {
+	y := cfg.NearY + (cfg.FarY-cfg.NearY)*t
+	half := (cfg.NearWidth + (cfg.FarWidth-cfg.NearWidth)*t) / 2
+	x := cfg.CenterX + side*half + steering*cfg.Bend*t*t
+	return x, y
+}
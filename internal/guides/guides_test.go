@@ -0,0 +1,73 @@
+package guides
+
+import (
+	"math"
+	"testing"
+)
+
+var testConfig = Config{
+	CenterX:   0.5,
+	NearY:     0.9,
+	FarY:      0.5,
+	NearWidth: 0.6,
+	FarWidth:  0.2,
+	Bands:     []float64{0.3, 0.6},
+	Bend:      0.2,
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestLines_Straight(t *testing.T) {
+	segs := Lines(testConfig, 0)
+	// Three bands, each with two rails of curveSteps pieces and a crossbar
+	if want := 3 * (2*curveSteps + 1); len(segs) != want {
+		t.Fatalf("segments = %d, want %d", len(segs), want)
+	}
+	counts := map[int]int{}
+	for _, s := range segs {
+		counts[s.Band]++
+		// Symmetric around the centre without steering
+		mirrored := false
+		for _, o := range segs {
+			if near(o.X1, 1-s.X1) && near(o.Y1, s.Y1) && near(o.X2, 1-s.X2) && near(o.Y2, s.Y2) ||
+				near(o.X1, 1-s.X2) && near(o.Y1, s.Y2) && near(o.X2, 1-s.X1) && near(o.Y2, s.Y1) {
+				mirrored = true
+				break
+			}
+		}
+		if !mirrored {
+			t.Errorf("segment %+v has no mirror image", s)
+		}
+	}
+	if counts[BandNear] != counts[BandMid] || counts[BandMid] != counts[BandFar] {
+		t.Errorf("segments per band = %v", counts)
+	}
+
+	// The near end spans NearWidth at NearY
+	first := segs[0]
+	if !near(first.X1, 0.2) || !near(first.Y1, 0.9) {
+		t.Errorf("left rail starts at (%v, %v), want (0.2, 0.9)", first.X1, first.Y1)
+	}
+	// The last crossbar spans FarWidth at FarY
+	last := segs[len(segs)-1]
+	if !near(last.X1, 0.4) || !near(last.X2, 0.6) || !near(last.Y1, 0.5) || last.Band != BandFar {
+		t.Errorf("far crossbar = %+v", last)
+	}
+}
+
+func TestLines_Steering(t *testing.T) {
+	straight := Lines(testConfig, 0)
+	right := Lines(testConfig, 1)
+	// The near end stays put, the far end moves by Bend
+	if !near(right[0].X1, straight[0].X1) {
+		t.Errorf("near end moved: %v -> %v", straight[0].X1, right[0].X1)
+	}
+	n := len(right) - 1
+	if !near(right[n].X1-straight[n].X1, 0.2) {
+		t.Errorf("far end moved by %v, want 0.2", right[n].X1-straight[n].X1)
+	}
+	// Clamped beyond full lock
+	if over := Lines(testConfig, 3); !near(over[n].X1, right[n].X1) {
+		t.Errorf("steering 3 not clamped to 1")
+	}
+}
@@ -190,3 +190,16 @@ func TestIsPIDAlive_Nonexistent(t *testing.T) {
 		t.Skip("PID 2147483647 actually exists (unlikely)")
 	}
 }
+
+// ===========================================================================
+// MinInt / MaxInt tests
+// ===========================================================================
+
+func TestMinMaxInt(t *testing.T) {
+	if MinInt(3, -1) != -1 || MinInt(-1, 3) != -1 || MinInt(2, 2) != 2 {
+		t.Error("MinInt returned the wrong value")
+	}
+	if MaxInt(3, -1) != 3 || MaxInt(-1, 3) != 3 || MaxInt(2, 2) != 2 {
+		t.Error("MaxInt returned the wrong value")
+	}
+}
@@ -0,0 +1,23 @@
+package helpers
+
+// =============================================================================
+// Integer Helpers
+// =============================================================================
+// go.mod targets Go 1.19, which has no min/max builtins.
+// =============================================================================
+
+// MinInt returns the smaller of a and b.
+func MinInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// MaxInt returns the larger of a and b.
+func MaxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,205 @@
+// Package motion detects motion in camera frames by frame differencing.
+//
+// Each frame is reduced to a small grayscale grid (DefaultWidth cells wide)
+// by averaging a few luma samples per cell, and compared with the previous
+// grid. A cell has changed when its brightness moved by more than a
+// threshold derived from the sensitivity; there is motion when the changed
+// cells cover at least MinAreaPct of the frame, or of the detection zones
+// when the camera has any (see Polygon). Reading the Y plane of
+// YCbCr frames directly keeps this cheap enough to run on several cameras
+// of a Raspberry Pi at a few checks per second.
+package motion
+
+import (
+	"image"
+	"image/color"
+)
+
+// Defaults for a zero Config
+const (
+	DefaultWidth       = 80
+	DefaultSensitivity = 50
+	DefaultMinAreaPct  = 1.0
+)
+
+// samplesPerAxis bounds the luma samples averaged per cell to 4x4.
+const samplesPerAxis = 4
+
+// Config tunes a Detector.
+type Config struct {
+	Width       int     // Grid width in cells; height follows the frame's aspect ratio
+	Sensitivity int     // 1 (large changes only) .. 100 (small changes)
+	MinAreaPct  float64 // Percent of the frame (or of the zones) that must change
+
+	// Zones restricts detection to these polygons; none means the whole frame
+	Zones []Polygon
+}
+
+// Result is the outcome of one Detect call.
+type Result struct {
+	Motion     bool
+	ChangedPct float64 // Percent of the grid (inside the zones) that changed
+}
+
+// Detector compares each frame with the previous one. It is not safe for
+// concurrent use; run one per camera.
+type Detector struct {
+	cfg       Config
+	threshold int // Per-cell luma difference counted as a change
+
+	gw, gh    int
+	prev, cur []uint8
+	haveFrame bool
+	frameSize image.Point
+
+	mask      []bool // Cells inside the zones; nil = all cells
+	maskCount int
+}
+
+// NewDetector returns a detector with cfg's zero fields set to the defaults.
+func NewDetector(cfg Config) *Detector {
+	if cfg.Width <= 0 {
+		cfg.Width = DefaultWidth
+	}
+	if cfg.Sensitivity <= 0 {
+		cfg.Sensitivity = DefaultSensitivity
+	}
+	if cfg.Sensitivity > 100 {
+		cfg.Sensitivity = 100
+	}
+	if cfg.MinAreaPct <= 0 {
+		cfg.MinAreaPct = DefaultMinAreaPct
+	}
+	return &Detector{
+		cfg: cfg,
+		// Sensitivity 100 counts a change of 8 levels, 1 needs 56
+		threshold: 8 + (100-cfg.Sensitivity)*48/99,
+	}
+}
+
+// Reset forgets the previous frame, e.g. after the camera was replaced. The
+// next Detect reports no motion.
+func (d *Detector) Reset() {
+	d.haveFrame = false
+}
+
+// SetZones replaces the detection zones; nil means the whole frame.
+func (d *Detector) SetZones(zones []Polygon) {
+	d.cfg.Zones = zones
+	if d.gw > 0 {
+		d.mask, d.maskCount = zoneMask(zones, d.gw, d.gh)
+	}
+}
+
+// Detect compares img with the previous frame. The first frame, and the
+// first after the frame size changed, only primes the detector.
+func (d *Detector) Detect(img image.Image) Result {
+	b := img.Bounds()
+	if b.Empty() {
+		return Result{}
+	}
+	if b.Size() != d.frameSize {
+		d.resize(b.Size())
+	}
+
+	downscale(img, d.cur, d.gw, d.gh)
+	d.prev, d.cur = d.cur, d.prev
+	if !d.haveFrame {
+		d.haveFrame = true
+		return Result{}
+	}
+
+	if d.maskCount == 0 {
+		return Result{} // Zones cover no cell
+	}
+	changed := 0
+	for i, v := range d.prev {
+		if d.mask != nil && !d.mask[i] {
+			continue
+		}
+		diff := int(v) - int(d.cur[i])
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > d.threshold {
+			changed++
+		}
+	}
+	pct := float64(changed) * 100 / float64(d.maskCount)
+	return Result{Motion: pct >= d.cfg.MinAreaPct, ChangedPct: pct}
+}
+
+// resize sizes the grids for frames of size and forgets the previous frame.
+func (d *Detector) resize(size image.Point) {
+	d.frameSize = size
+	d.gw = d.cfg.Width
+	if d.gw > size.X {
+		d.gw = size.X
+	}
+	d.gh = (d.gw*size.Y + size.X/2) / size.X
+	if d.gh < 1 {
+		d.gh = 1
+	}
+	if d.gh > size.Y {
+		d.gh = size.Y
+	}
+	d.prev = make([]uint8, d.gw*d.gh)
+	d.cur = make([]uint8, d.gw*d.gh)
+	d.mask, d.maskCount = zoneMask(d.cfg.Zones, d.gw, d.gh)
+	d.haveFrame = false
+}
+
+// downscale writes the average luma of each of gw x gh cells of img to dst.
+func downscale(img image.Image, dst []uint8, gw, gh int) {
+	luma := lumaFunc(img)
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	for cy := 0; cy < gh; cy++ {
+		y0 := b.Min.Y + cy*h/gh
+		y1 := b.Min.Y + (cy+1)*h/gh
+		stepY := (y1 - y0 + samplesPerAxis - 1) / samplesPerAxis
+		for cx := 0; cx < gw; cx++ {
+			x0 := b.Min.X + cx*w/gw
+			x1 := b.Min.X + (cx+1)*w/gw
+			stepX := (x1 - x0 + samplesPerAxis - 1) / samplesPerAxis
+			sum, n := 0, 0
+			for y := y0; y < y1; y += stepY {
+				for x := x0; x < x1; x += stepX {
+					sum += int(luma(x, y))
+					n++
+				}
+			}
+			dst[cy*gw+cx] = uint8(sum / n)
+		}
+	}
+}
+
+// lumaFunc returns a function reading the luma at (x, y) of img, with fast
+// paths for the frame types the capture pipeline produces.
+func lumaFunc(img image.Image) func(x, y int) uint8 {
+	switch m := img.(type) {
+	case *image.YCbCr:
+		return func(x, y int) uint8 { return m.Y[m.YOffset(x, y)] }
+	case *image.Gray:
+		return func(x, y int) uint8 { return m.Pix[m.PixOffset(x, y)] }
+	case *image.RGBA:
+		return func(x, y int) uint8 {
+			p := m.Pix[m.PixOffset(x, y):]
+			return rgbLuma(p[0], p[1], p[2])
+		}
+	case *image.NRGBA:
+		return func(x, y int) uint8 {
+			p := m.Pix[m.PixOffset(x, y):]
+			return rgbLuma(p[0], p[1], p[2])
+		}
+	default:
+		return func(x, y int) uint8 {
+			return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+		}
+	}
+}
+
+// rgbLuma uses the same weights as color.GrayModel.
+func rgbLuma(r, g, b uint8) uint8 {
+	return uint8((19595*uint32(r) + 38470*uint32(g) + 7471*uint32(b) + 1<<15) >> 16)
+}
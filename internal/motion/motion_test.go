@@ -0,0 +1,197 @@
+package motion
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// grayFrame returns a 640x480 YCbCr frame of luma bg with a w x h square of
+// luma fg at (x, y).
+func grayFrame(bg, fg uint8, x, y, w, h int) *image.YCbCr {
+	img := image.NewYCbCr(image.Rect(0, 0, 640, 480), image.YCbCrSubsampleRatio420)
+	for i := range img.Y {
+		img.Y[i] = bg
+	}
+	for yy := y; yy < y+h; yy++ {
+		for xx := x; xx < x+w; xx++ {
+			img.Y[img.YOffset(xx, yy)] = fg
+		}
+	}
+	return img
+}
+
+func TestDetector_StaticSceneHasNoMotion(t *testing.T) {
+	d := NewDetector(Config{})
+	for i := 0; i < 3; i++ {
+		if r := d.Detect(grayFrame(100, 100, 0, 0, 0, 0)); r.Motion || r.ChangedPct != 0 {
+			t.Fatalf("frame %d: %+v, want no motion", i, r)
+		}
+	}
+}
+
+func TestDetector_MovingObject(t *testing.T) {
+	d := NewDetector(Config{MinAreaPct: 1})
+	if r := d.Detect(grayFrame(50, 200, 0, 0, 64, 64)); r.Motion {
+		t.Fatal("first frame reported motion")
+	}
+	// A 64x64 square (~1.3% of the frame) moving by its own size changes
+	// twice its area
+	r := d.Detect(grayFrame(50, 200, 320, 240, 64, 64))
+	if !r.Motion {
+		t.Fatalf("moved square: %+v, want motion", r)
+	}
+	if r.ChangedPct < 2 || r.ChangedPct > 4 {
+		t.Errorf("changed = %.2f%%, want ~2.7%%", r.ChangedPct)
+	}
+}
+
+func TestDetector_MinArea(t *testing.T) {
+	d := NewDetector(Config{MinAreaPct: 5})
+	d.Detect(grayFrame(50, 200, 0, 0, 64, 64))
+	if r := d.Detect(grayFrame(50, 200, 320, 240, 64, 64)); r.Motion {
+		t.Errorf("change below min area: %+v, want no motion", r)
+	}
+}
+
+func TestDetector_Sensitivity(t *testing.T) {
+	// A faint change of 20 luma levels over a quarter of the frame
+	before := grayFrame(100, 100, 0, 0, 0, 0)
+	after := grayFrame(100, 120, 0, 0, 320, 240)
+
+	low := NewDetector(Config{Sensitivity: 10})
+	low.Detect(before)
+	if r := low.Detect(after); r.Motion {
+		t.Errorf("sensitivity 10: %+v, want no motion", r)
+	}
+
+	high := NewDetector(Config{Sensitivity: 90})
+	high.Detect(before)
+	if r := high.Detect(after); !r.Motion {
+		t.Errorf("sensitivity 90: %+v, want motion", r)
+	}
+}
+
+func TestDetector_ResetAndResize(t *testing.T) {
+	d := NewDetector(Config{})
+	d.Detect(grayFrame(0, 0, 0, 0, 0, 0))
+	d.Reset()
+	if r := d.Detect(grayFrame(255, 255, 0, 0, 0, 0)); r.Motion {
+		t.Error("frame after Reset reported motion")
+	}
+
+	// A different frame size primes the detector again
+	small := image.NewYCbCr(image.Rect(0, 0, 320, 240), image.YCbCrSubsampleRatio420)
+	if r := d.Detect(small); r.Motion {
+		t.Error("frame after size change reported motion")
+	}
+}
+
+func TestDownscale_FormatsAgree(t *testing.T) {
+	ycc := grayFrame(30, 220, 100, 100, 200, 150)
+	rgba := image.NewRGBA(ycc.Bounds())
+	gray := image.NewGray(ycc.Bounds())
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			v := ycc.Y[ycc.YOffset(x, y)]
+			rgba.Set(x, y, color.RGBA{v, v, v, 255})
+			gray.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	want := make([]uint8, 80*60)
+	downscale(ycc, want, 80, 60)
+	for name, img := range map[string]image.Image{"rgba": rgba, "gray": gray, "paletted-fallback": toPaletted(gray)} {
+		got := make([]uint8, len(want))
+		downscale(img, got, 80, 60)
+		for i := range want {
+			if d := int(got[i]) - int(want[i]); d < -1 || d > 1 {
+				t.Errorf("%s: cell %d = %d, want %d", name, i, got[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func toPaletted(src *image.Gray) *image.Paletted {
+	pal := make(color.Palette, 256)
+	for i := range pal {
+		pal[i] = color.Gray{Y: uint8(i)}
+	}
+	dst := image.NewPaletted(src.Bounds(), pal)
+	for i, v := range src.Pix {
+		dst.Pix[i] = v
+	}
+	return dst
+}
+
+func BenchmarkDetect_YCbCr640x480(b *testing.B) {
+	frames := []image.Image{
+		grayFrame(50, 200, 0, 0, 64, 64),
+		grayFrame(50, 200, 320, 240, 64, 64),
+	}
+	d := NewDetector(Config{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Detect(frames[i%2])
+	}
+}
+
+func TestDetector_Zones(t *testing.T) {
+	// Zone over the right half; the square moves within the left half
+	right := Polygon{{0.5, 0}, {1, 0}, {1, 1}, {0.5, 1}}
+	d := NewDetector(Config{Zones: []Polygon{right}})
+	d.Detect(grayFrame(50, 200, 0, 0, 64, 64))
+	if r := d.Detect(grayFrame(50, 200, 100, 200, 64, 64)); r.Motion {
+		t.Errorf("motion outside the zone: %+v", r)
+	}
+	// Changes are measured against the zone area (half the frame): the
+	// square entering it covers ~2.7% of the zone, not ~1.3% of the frame
+	if r := d.Detect(grayFrame(50, 200, 400, 200, 64, 64)); !r.Motion || r.ChangedPct < 2 {
+		t.Errorf("motion into the zone: %+v, want motion over ~2.7%% of the zone", r)
+	}
+
+	// Replacing the zones keeps the previous frame
+	d.SetZones(nil)
+	if r := d.Detect(grayFrame(50, 200, 100, 200, 64, 64)); !r.Motion {
+		t.Errorf("motion without zones: %+v", r)
+	}
+}
+
+func TestPolygon_Contains(t *testing.T) {
+	tri := Polygon{{0, 0}, {1, 0}, {0, 1}}
+	for _, tt := range []struct {
+		x, y float64
+		want bool
+	}{
+		{0.2, 0.2, true},
+		{0.6, 0.6, false},
+		{0.45, 0.45, true},
+		{1.5, 0.1, false},
+	} {
+		if got := tri.Contains(tt.x, tt.y); got != tt.want {
+			t.Errorf("Contains(%v, %v) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestParseZones(t *testing.T) {
+	zones, err := ParseZones(" 0.5,0 1,0 1,1 0.5,1 ;0,0.5 0.25,0.75 0,1 ; ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 2 || len(zones[0]) != 4 || len(zones[1]) != 3 || zones[1][1] != (Point{0.25, 0.75}) {
+		t.Fatalf("zones = %v", zones)
+	}
+	if got, want := FormatZones(zones), "0.5,0 1,0 1,1 0.5,1; 0,0.5 0.25,0.75 0,1"; got != want {
+		t.Errorf("FormatZones = %q, want %q", got, want)
+	}
+	if zones, err := ParseZones(""); err != nil || zones != nil {
+		t.Errorf("empty zones = %v, %v", zones, err)
+	}
+	for _, bad := range []string{"0,0 1,1", "0,0 1,1 2,0", "0,0 1;1 0,1", "a,b 1,1 0,1"} {
+		if _, err := ParseZones(bad); err == nil {
+			t.Errorf("ParseZones(%q) accepted", bad)
+		}
+	}
+}
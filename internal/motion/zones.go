@@ -0,0 +1,102 @@
+package motion
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Point is a position in normalized frame coordinates: (0,0) is the
+// top-left corner and (1,1) the bottom-right one.
+type Point struct {
+	X, Y float64
+}
+
+// Polygon is a detection zone. The last point connects back to the first.
+type Polygon []Point
+
+// Contains reports whether (x, y) lies inside p (even-odd rule).
+func (p Polygon) Contains(x, y float64) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > y) != (b.Y > y) && x < (b.X-a.X)*(y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ParseZones parses zones written as "x,y x,y x,y; x,y ...": polygons are
+// separated by semicolons, points by spaces, and coordinates are normalized
+// (0-1). Each polygon needs at least three points. "" means no zones.
+func ParseZones(s string) ([]Polygon, error) {
+	var zones []Polygon
+	for i, part := range strings.Split(s, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("zone %d: need at least 3 points, got %d", i+1, len(fields))
+		}
+		poly := make(Polygon, 0, len(fields))
+		for _, f := range fields {
+			xs, ys, ok := strings.Cut(f, ",")
+			if !ok {
+				return nil, fmt.Errorf("zone %d: point %q is not x,y", i+1, f)
+			}
+			x, errX := strconv.ParseFloat(xs, 64)
+			y, errY := strconv.ParseFloat(ys, 64)
+			if errX != nil || errY != nil || x < 0 || x > 1 || y < 0 || y > 1 {
+				return nil, fmt.Errorf("zone %d: point %q is not two numbers between 0 and 1", i+1, f)
+			}
+			poly = append(poly, Point{x, y})
+		}
+		zones = append(zones, poly)
+	}
+	return zones, nil
+}
+
+// FormatZones writes zones in the format ParseZones reads, rounded to three
+// decimals.
+func FormatZones(zones []Polygon) string {
+	parts := make([]string, 0, len(zones))
+	for _, poly := range zones {
+		points := make([]string, len(poly))
+		for i, pt := range poly {
+			points[i] = formatCoord(pt.X) + "," + formatCoord(pt.Y)
+		}
+		parts = append(parts, strings.Join(points, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
+
+// zoneMask marks the cells of a gw x gh grid whose centre lies in a zone,
+// and returns how many are marked. No zones means the whole frame (nil mask).
+func zoneMask(zones []Polygon, gw, gh int) ([]bool, int) {
+	if len(zones) == 0 {
+		return nil, gw * gh
+	}
+	mask := make([]bool, gw*gh)
+	n := 0
+	for cy := 0; cy < gh; cy++ {
+		y := (float64(cy) + 0.5) / float64(gh)
+		for cx := 0; cx < gw; cx++ {
+			x := (float64(cx) + 0.5) / float64(gw)
+			for _, poly := range zones {
+				if poly.Contains(x, y) {
+					mask[cy*gw+cx] = true
+					n++
+					break
+				}
+			}
+		}
+	}
+	return mask, n
+}
@@ -3,6 +3,8 @@ package perf
 import (
 	"camera-dashboard-go/internal/camera"
 	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/events"
+	"fmt"
 	"log"
 	"sync"
 	"sync/atomic"
@@ -81,6 +83,9 @@ type SmartController struct {
 	mutex   sync.RWMutex
 	running atomic.Bool
 	stopCh  chan struct{}
+
+	// FPS and state changes are published here (nil = not published)
+	events *events.Bus
 }
 
 // NewSmartController creates a performance controller.
@@ -150,6 +155,11 @@ func NewSmartController(manager *camera.Manager, cfg *config.Config) *SmartContr
 	return sc
 }
 
+// SetEventBus publishes FPS and state changes to bus. Call before Start.
+func (sc *SmartController) SetEventBus(bus *events.Bus) {
+	sc.events = bus
+}
+
 // Start begins monitoring and optional FPS adaptation.
 func (sc *SmartController) Start() {
 	if sc.running.Swap(true) {
@@ -446,6 +456,16 @@ func (sc *SmartController) changeFPS(fps int) {
 	}
 
 	log.Printf("[SmartCtrl] FPS: %d -> %d", oldFPS, fps)
+	sc.publishFPS(oldFPS, fps)
+}
+
+// publishFPS announces an FPS change on the event bus
+func (sc *SmartController) publishFPS(oldFPS, fps int) {
+	sc.events.Publish(events.Event{
+		Type:    events.FPSChange,
+		Message: fmt.Sprintf("FPS %d -> %d", oldFPS, fps),
+		Data:    map[string]interface{}{"fps": fps, "previous_fps": oldFPS, "state": sc.GetState()},
+	})
 }
 
 // applyFPS sets FPS without logging (for initial setup)
@@ -465,9 +485,22 @@ func (sc *SmartController) enterState(state int) {
 	sc.recoverCount = 0
 
 	log.Printf("[SmartCtrl] State: %s -> %s", stateName(oldState), stateName(int32(state)))
+	sc.events.Publish(events.Event{
+		Type:    events.ControllerState,
+		Message: fmt.Sprintf("%s -> %s", stateName(oldState), stateName(int32(state))),
+		Data: map[string]interface{}{
+			"state":          stateName(int32(state)),
+			"previous_state": stateName(oldState),
+			"temperature_c":  sc.monitor.GetTemperature(),
+		},
+	})
 
 	if state == StateEmergency {
+		oldFPS := sc.currentFPS
 		sc.applyFPS(sc.minFPS)
+		if oldFPS != sc.minFPS {
+			sc.publishFPS(oldFPS, sc.minFPS)
+		}
 	}
 	if state == StateStable {
 		sc.stableSeconds.Store(0)
@@ -511,6 +544,11 @@ func (sc *SmartController) GetState() string {
 	return stateName(sc.state.Load())
 }
 
+// GetMonitor returns the system monitor the controller samples each tick
+func (sc *SmartController) GetMonitor() *Monitor {
+	return sc.monitor
+}
+
 // IsDynamic returns whether dynamic FPS adaptation is enabled
 func (sc *SmartController) IsDynamic() bool {
 	return sc.dynamicEnabled
@@ -519,6 +557,11 @@ func (sc *SmartController) IsDynamic() bool {
 // stateNames maps state constants to human-readable names.
 var stateNames = []string{"Probing", "Stable", "Recovering", "Emergency"}
 
+// StateNames returns the names of all controller states
+func StateNames() []string {
+	return append([]string(nil), stateNames...)
+}
+
 // stateName returns the name for a state value, or "Unknown" if out of range.
 func stateName(state int32) string {
 	if state >= 0 && int(state) < len(stateNames) {
@@ -2,6 +2,7 @@ package perf
 
 import (
 	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/events"
 	"testing"
 )
 
@@ -119,6 +120,30 @@ func TestChangeFPS_Clamping(t *testing.T) {
 	}
 }
 
+func TestEnterEmergency_PublishesStateAndFPS(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DynamicFPSEnabled = true
+	cfg.CaptureFPS = 20
+	cfg.MinDynamicFPS = 10
+
+	sc := NewSmartController(nil, cfg)
+	bus := events.NewBus()
+	sub := bus.Subscribe(4)
+	defer sub.Close()
+	sc.SetEventBus(bus)
+
+	sc.enterState(StateEmergency)
+
+	state := <-sub.C
+	if state.Type != events.ControllerState || state.Data["state"] != "Emergency" {
+		t.Errorf("first event = %+v, want controller_state Emergency", state)
+	}
+	fps := <-sub.C
+	if fps.Type != events.FPSChange || fps.Data["fps"] != 10 || fps.Data["previous_fps"] != 20 {
+		t.Errorf("second event = %+v, want fps_change 20 -> 10", fps)
+	}
+}
+
 func TestChangeFPS_NoOpWhenSame(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.DynamicFPSEnabled = true
@@ -161,6 +186,9 @@ func TestGetState_Names(t *testing.T) {
 	if sc.GetState() != "Emergency" {
 		t.Errorf("GetState() = %q, want Emergency", sc.GetState())
 	}
+	if names := StateNames(); len(names) != 4 || names[StateStable] != "Stable" {
+		t.Errorf("StateNames() = %v, want the four states in order", names)
+	}
 }
 
 func TestIsDynamic(t *testing.T) {
@@ -0,0 +1,273 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+)
+
+// =============================================================================
+// MJPEG-in-AVI writer
+// =============================================================================
+// A minimal RIFF AVI 1.0 writer for a single MJPEG video stream:
+//
+//   RIFF 'AVI '
+//     LIST 'hdrl'
+//       avih                 main header
+//       LIST 'strl'
+//         strh               stream header ('vids', 'MJPG')
+//         strf               BITMAPINFOHEADER
+//     LIST 'movi'
+//       00dc ...             one chunk per JPEG frame
+//     idx1                   index of the 00dc chunks
+//
+// Frame count, frame rate and chunk sizes are unknown until the segment is
+// closed, so the headers are written with placeholders and patched on Close.
+// The frame rate is taken from the real capture time span because the
+// adaptive FPS controller changes it on the fly.
+// =============================================================================
+
+// maxAVIBytes keeps segments below the 1 GB limit of plain (non-OpenDML) AVI.
+const maxAVIBytes = 1000 << 20
+
+// Byte offsets of the header fields patched on Close.
+const (
+	aviRIFFSizeOffset     = 4
+	aviMicroSecOffset     = 32  // avih dwMicroSecPerFrame
+	aviTotalFramesOffset  = 48  // avih dwTotalFrames
+	aviSuggestedBufOffset = 60  // avih dwSuggestedBufferSize
+	aviScaleOffset        = 128 // strh dwScale
+	aviRateOffset         = 132 // strh dwRate
+	aviLengthOffset       = 140 // strh dwLength
+	aviStrhBufOffset      = 144 // strh dwSuggestedBufferSize
+	aviMoviSizeOffset     = 216
+	aviHeaderSize         = 224 // Up to and including the 'movi' list type
+)
+
+// aviIndexEntry is one idx1 record.
+type aviIndexEntry struct {
+	offset uint32 // Relative to the 'movi' list type
+	size   uint32
+}
+
+// aviWriter writes one MJPEG AVI segment.
+type aviWriter struct {
+	f        *os.File
+	w        *bufio.Writer
+	width    int
+	height   int
+	size     int64 // Bytes written so far
+	index    []aviIndexEntry
+	maxFrame uint32
+	first    time.Time
+	last     time.Time
+}
+
+// createAVI creates path and writes the AVI headers for width x height frames.
+func createAVI(path string, width, height int) (*aviWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	aw := &aviWriter{f: f, w: bufio.NewWriterSize(f, 256<<10), width: width, height: height}
+	if err := aw.writeHeader(); err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (aw *aviWriter) writeHeader() error {
+	h := make([]byte, 0, aviHeaderSize)
+	le := binary.LittleEndian
+	u32 := func(v uint32) { h = le.AppendUint32(h, v) }
+	u16 := func(v uint16) { h = le.AppendUint16(h, v) }
+	fourcc := func(s string) { h = append(h, s...) }
+
+	fourcc("RIFF")
+	u32(0) // Patched
+	fourcc("AVI ")
+
+	fourcc("LIST")
+	u32(192) // hdrl size: 'hdrl' + avih(8+56) + strl list(8+116)
+	fourcc("hdrl")
+
+	fourcc("avih")
+	u32(56)
+	u32(0)    // dwMicroSecPerFrame (patched)
+	u32(0)    // dwMaxBytesPerSec
+	u32(0)    // dwPaddingGranularity
+	u32(0x10) // dwFlags: AVIF_HASINDEX
+	u32(0)    // dwTotalFrames (patched)
+	u32(0)    // dwInitialFrames
+	u32(1)    // dwStreams
+	u32(0)    // dwSuggestedBufferSize (patched)
+	u32(uint32(aw.width))
+	u32(uint32(aw.height))
+	u32(0)
+	u32(0)
+	u32(0)
+	u32(0) // dwReserved[4]
+
+	fourcc("LIST")
+	u32(116) // 'strl' + strh(8+56) + strf(8+40)
+	fourcc("strl")
+
+	fourcc("strh")
+	u32(56)
+	fourcc("vids")
+	fourcc("MJPG")
+	u32(0)          // dwFlags
+	u16(0)          // wPriority
+	u16(0)          // wLanguage
+	u32(0)          // dwInitialFrames
+	u32(1)          // dwScale (patched)
+	u32(0)          // dwRate (patched)
+	u32(0)          // dwStart
+	u32(0)          // dwLength (patched)
+	u32(0)          // dwSuggestedBufferSize (patched)
+	u32(0xFFFFFFFF) // dwQuality: default
+	u32(0)          // dwSampleSize: variable
+	u16(0)
+	u16(0)
+	u16(uint16(aw.width))
+	u16(uint16(aw.height)) // rcFrame
+
+	fourcc("strf")
+	u32(40)
+	u32(40) // biSize
+	u32(uint32(aw.width))
+	u32(uint32(aw.height))
+	u16(1)  // biPlanes
+	u16(24) // biBitCount
+	fourcc("MJPG")
+	u32(uint32(aw.width * aw.height * 3)) // biSizeImage
+	u32(0)
+	u32(0)
+	u32(0)
+	u32(0)
+
+	fourcc("LIST")
+	u32(0) // movi size (patched)
+	fourcc("movi")
+
+	if len(h) != aviHeaderSize {
+		return fmt.Errorf("avi header is %d bytes, want %d", len(h), aviHeaderSize)
+	}
+	n, err := aw.w.Write(h)
+	aw.size += int64(n)
+	return err
+}
+
+// WriteFrame appends one JPEG frame captured at ts.
+func (aw *aviWriter) WriteFrame(jpegData []byte, ts time.Time) error {
+	var hdr [8]byte
+	copy(hdr[:4], "00dc")
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(jpegData)))
+
+	offset := uint32(aw.size - (aviHeaderSize - 4)) // Relative to 'movi'
+	if _, err := aw.w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := aw.w.Write(jpegData); err != nil {
+		return err
+	}
+	size := int64(8 + len(jpegData))
+	if len(jpegData)%2 == 1 {
+		if err := aw.w.WriteByte(0); err != nil { // RIFF chunks are word aligned
+			return err
+		}
+		size++
+	}
+	aw.size += size
+
+	aw.index = append(aw.index, aviIndexEntry{offset: offset, size: uint32(len(jpegData))})
+	if uint32(len(jpegData)) > aw.maxFrame {
+		aw.maxFrame = uint32(len(jpegData))
+	}
+	if aw.first.IsZero() {
+		aw.first = ts
+	}
+	aw.last = ts
+	return nil
+}
+
+// Frames returns the number of frames written.
+func (aw *aviWriter) Frames() int {
+	return len(aw.index)
+}
+
+// Size returns the current file size in bytes.
+func (aw *aviWriter) Size() int64 {
+	return aw.size
+}
+
+// Close writes the index, patches the headers and closes the file.
+func (aw *aviWriter) Close() error {
+	err := aw.finish()
+	if cerr := aw.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (aw *aviWriter) finish() error {
+	moviSize := aw.size - (aviHeaderSize - 4) // From 'movi' to the end of the last chunk
+
+	idx := make([]byte, 0, 8+16*len(aw.index))
+	le := binary.LittleEndian
+	idx = append(idx, "idx1"...)
+	idx = le.AppendUint32(idx, uint32(16*len(aw.index)))
+	for _, e := range aw.index {
+		idx = append(idx, "00dc"...)
+		idx = le.AppendUint32(idx, 0x10) // AVIIF_KEYFRAME
+		idx = le.AppendUint32(idx, e.offset)
+		idx = le.AppendUint32(idx, e.size)
+	}
+	if _, err := aw.w.Write(idx); err != nil {
+		return err
+	}
+	aw.size += int64(len(idx))
+	if err := aw.w.Flush(); err != nil {
+		return err
+	}
+
+	// Frame rate as a rational: frames per elapsed milliseconds
+	frames := uint32(len(aw.index))
+	scale, rate := uint32(1), uint32(1)
+	usPerFrame := uint32(1000000)
+	if frames > 1 {
+		span := aw.last.Sub(aw.first)
+		// n frames cover n-1 intervals
+		if us := uint32(span / time.Duration(frames-1) / time.Microsecond); us > 0 {
+			usPerFrame = us
+			scale, rate = us, 1000000
+		}
+	}
+
+	patches := []struct {
+		offset int64
+		value  uint32
+	}{
+		{aviRIFFSizeOffset, uint32(aw.size - 8)},
+		{aviMicroSecOffset, usPerFrame},
+		{aviTotalFramesOffset, frames},
+		{aviSuggestedBufOffset, aw.maxFrame + 8},
+		{aviScaleOffset, scale},
+		{aviRateOffset, rate},
+		{aviLengthOffset, frames},
+		{aviStrhBufOffset, aw.maxFrame + 8},
+		{aviMoviSizeOffset, uint32(moviSize)},
+	}
+	var buf [4]byte
+	for _, p := range patches {
+		binary.LittleEndian.PutUint32(buf[:], p.value)
+		if _, err := aw.f.WriteAt(buf[:], p.offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package recording
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAVIWriter_HeadersAndIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.avi")
+	aw, err := createAVI(path, 320, 240)
+	if err != nil {
+		t.Fatalf("createAVI: %v", err)
+	}
+	start := time.Unix(1700000000, 0)
+	frames := [][]byte{{0xFF, 0xD8, 1, 0xFF, 0xD9}, {0xFF, 0xD8, 1, 2, 0xFF, 0xD9}, {0xFF, 0xD8, 0xFF, 0xD9}}
+	for i, f := range frames {
+		if err := aw.WriteFrame(f, start.Add(time.Duration(i)*100*time.Millisecond)); err != nil {
+			t.Fatalf("WriteFrame: %v", err)
+		}
+	}
+	if err := aw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	le := binary.LittleEndian
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "AVI " {
+		t.Fatalf("not a RIFF AVI file: %q", data[:12])
+	}
+	if got := le.Uint32(data[4:]); int(got) != len(data)-8 {
+		t.Errorf("RIFF size = %d, want %d", got, len(data)-8)
+	}
+	if got := le.Uint32(data[aviTotalFramesOffset:]); got != 3 {
+		t.Errorf("total frames = %d, want 3", got)
+	}
+	if got := le.Uint32(data[aviMicroSecOffset:]); got != 100000 {
+		t.Errorf("microseconds per frame = %d, want 100000", got)
+	}
+	if string(data[112:116]) != "MJPG" || le.Uint32(data[64:]) != 320 || le.Uint32(data[68:]) != 240 {
+		t.Errorf("stream header does not describe 320x240 MJPEG")
+	}
+
+	// Walk the movi list and check the index points at each chunk
+	moviStart := aviHeaderSize - 4
+	moviSize := int(le.Uint32(data[aviMoviSizeOffset:]))
+	idx := data[moviStart+moviSize:]
+	if string(idx[0:4]) != "idx1" || le.Uint32(idx[4:]) != uint32(16*len(frames)) {
+		t.Fatalf("idx1 missing after movi: %q", idx[:8])
+	}
+	for i, f := range frames {
+		entry := idx[8+16*i:]
+		offset := int(le.Uint32(entry[8:]))
+		chunk := data[moviStart+offset:]
+		if string(chunk[0:4]) != "00dc" || int(le.Uint32(chunk[4:])) != len(f) {
+			t.Errorf("frame %d: index points at %q size %d", i, chunk[:4], le.Uint32(chunk[4:]))
+			continue
+		}
+		if string(chunk[8:8+len(f)]) != string(f) {
+			t.Errorf("frame %d: payload mismatch", i)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package recording
+
+import (
+	"bytes"
+	"camera-dashboard-go/internal/camera"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// Event clips
+// =============================================================================
+// Every camera keeps a ring of its last PreDuration of JPEG frames in memory.
+// When an event fires, the ring is written out as the start of a clip and the
+// following PostDuration of frames is appended, giving footage from before
+// and after the trigger. Each clip gets a sidecar JSON file describing the
+// trigger, time and camera. A trigger that arrives while a clip is still being
+// recorded extends that clip instead of starting a second one.
+// =============================================================================
+
+// Defaults for zero ClipConfig values
+const (
+	DefaultPreDuration  = 10 * time.Second
+	DefaultPostDuration = 10 * time.Second
+	DefaultRingBytes    = 32 << 20
+)
+
+// clipIdleTimeout finishes a clip whose camera stopped delivering frames.
+const clipIdleTimeout = 5 * time.Second
+
+// Trigger sources
+const (
+	TriggerButton = "button"
+	TriggerAPI    = "api"
+	TriggerMotion = "motion"
+	TriggerInput  = "input"
+)
+
+// ClipConfig controls event clip capture.
+type ClipConfig struct {
+	Dir          string        // Directory for clips and sidecars (created if missing)
+	PreDuration  time.Duration // Footage kept before the trigger
+	PostDuration time.Duration // Footage recorded after the trigger
+	RingBytes    int           // Per-camera memory limit of the pre-event ring
+	JPEGQuality  int           // Quality for frames that must be encoded
+	QueueFrames  int           // Per-camera queue; frames are dropped when it is full
+}
+
+// Event describes why a clip is saved.
+type Event struct {
+	Trigger string    `json:"trigger"`          // TriggerButton, TriggerAPI, ...
+	Detail  string    `json:"detail,omitempty"` // Free text, e.g. zone or input name
+	Camera  string    `json:"-"`                // Camera key, role or device ID; "" = all cameras
+	Time    time.Time `json:"time"`
+}
+
+// ClipInfo is the sidecar JSON written next to each clip.
+type ClipInfo struct {
+	File       string    `json:"file"`
+	Camera     string    `json:"camera"`
+	Role       string    `json:"role,omitempty"`
+	DeviceID   string    `json:"device_id"`
+	Identity   string    `json:"identity"`
+	Slot       int       `json:"slot"`
+	Event      Event     `json:"event"`
+	Extensions []Event   `json:"extended_by,omitempty"` // Triggers that arrived during the clip
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Frames     int       `json:"frames"`
+	PreSec     float64   `json:"pre_sec"`
+	PostSec    float64   `json:"post_sec"`
+}
+
+// ClipRecorder keeps pre-event rings for every camera and saves clips on Trigger.
+type ClipRecorder struct {
+	cfg ClipConfig
+
+	mu      sync.Mutex
+	cams    map[string]*clipCamera
+	closed  bool
+	writers sync.WaitGroup
+
+	dropped atomic.Uint64
+	saved   atomic.Uint64
+}
+
+// NewClipRecorder creates a clip recorder writing into cfg.Dir.
+func NewClipRecorder(cfg ClipConfig) (*ClipRecorder, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("clip directory not set")
+	}
+	if cfg.PreDuration <= 0 {
+		cfg.PreDuration = DefaultPreDuration
+	}
+	if cfg.PostDuration <= 0 {
+		cfg.PostDuration = DefaultPostDuration
+	}
+	if cfg.RingBytes <= 0 {
+		cfg.RingBytes = DefaultRingBytes
+	}
+	if cfg.JPEGQuality <= 0 || cfg.JPEGQuality > 100 {
+		cfg.JPEGQuality = DefaultJPEGQuality
+	}
+	if cfg.QueueFrames <= 0 {
+		cfg.QueueFrames = DefaultQueueFrames
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create clip directory: %w", err)
+	}
+	log.Printf("[Clips] Saving event clips to %s (%s before, %s after)",
+		cfg.Dir, cfg.PreDuration, cfg.PostDuration)
+	return &ClipRecorder{cfg: cfg, cams: make(map[string]*clipCamera)}, nil
+}
+
+// WriteFrame adds a frame to its camera's ring. Never blocks.
+func (c *ClipRecorder) WriteFrame(f camera.CapturedFrame) {
+	if f.Image == nil && f.JPEG == nil {
+		return
+	}
+	key := cameraKey(f.Camera, f.Slot)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	cc, ok := c.cams[key]
+	if !ok {
+		cc = &clipCamera{
+			c:     c,
+			name:  key,
+			items: make(chan clipItem, c.cfg.QueueFrames),
+		}
+		c.cams[key] = cc
+		c.writers.Add(1)
+		go cc.run()
+	}
+	cc.cam, cc.slot = f.Camera, f.Slot
+
+	select {
+	case cc.items <- clipItem{frame: f}:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// Trigger saves a clip around ev.Time for the camera named by ev.Camera
+// (matched against the camera key, role, identity or device ID), or for every
+// camera when ev.Camera is empty. Returns the number of cameras triggered.
+func (c *ClipRecorder) Trigger(ev Event) int {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if ev.Trigger == "" {
+		ev.Trigger = TriggerAPI
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0
+	}
+	n := 0
+	for key, cc := range c.cams {
+		if ev.Camera != "" && !matchesCamera(ev.Camera, key, cc.cam) {
+			continue
+		}
+		select {
+		case cc.items <- clipItem{event: &ev}:
+			n++
+		default:
+			log.Printf("[Clips] %s: trigger %q dropped (queue full)", key, ev.Trigger)
+		}
+	}
+	log.Printf("[Clips] Trigger %q (%s) for %d camera(s)", ev.Trigger, ev.Detail, n)
+	return n
+}
+
+// Close finishes clips in progress and stops the recorder.
+func (c *ClipRecorder) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	for _, cc := range c.cams {
+		close(cc.items)
+	}
+	c.mu.Unlock()
+	c.writers.Wait()
+	log.Printf("[Clips] Stopped (%d clips saved, %d frames dropped)", c.saved.Load(), c.dropped.Load())
+}
+
+// Dir returns the clip directory.
+func (c *ClipRecorder) Dir() string {
+	return c.cfg.Dir
+}
+
+// matchesCamera reports whether a trigger's camera name refers to cam.
+func matchesCamera(name, key string, cam camera.Camera) bool {
+	return name == key || name == cam.DeviceID || strings.EqualFold(name, cam.Role) || cam.Matches(name)
+}
+
+// ringFrame is one compressed frame in the pre-event ring.
+type ringFrame struct {
+	jpeg   []byte
+	width  int
+	height int
+	time   time.Time
+}
+
+// clipItem is a frame or a trigger. Both travel through one queue so a
+// trigger sees exactly the frames captured before it.
+type clipItem struct {
+	frame camera.CapturedFrame
+	event *Event
+}
+
+// clipCamera owns the ring and the clip in progress of one camera. Only its
+// goroutine touches them.
+type clipCamera struct {
+	c     *ClipRecorder
+	name  string
+	items chan clipItem
+
+	// Latest camera metadata; written under ClipRecorder.mu
+	cam  camera.Camera
+	slot int
+
+	ring      []ringFrame
+	ringBytes int
+	encBuf    bytes.Buffer
+
+	clip     *aviWriter
+	clipInfo ClipInfo
+	clipPath string
+	clipEnd  time.Time
+	lastSeen time.Time
+}
+
+func (cc *clipCamera) run() {
+	defer cc.c.writers.Done()
+	defer cc.finishClip()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item, ok := <-cc.items:
+			if !ok {
+				return
+			}
+			if item.event != nil {
+				cc.trigger(*item.event)
+			} else {
+				cc.addFrame(item.frame)
+			}
+		case <-ticker.C:
+			if cc.clip != nil && time.Since(cc.lastSeen) > clipIdleTimeout {
+				cc.finishClip()
+			}
+		}
+	}
+}
+
+func (cc *clipCamera) addFrame(f camera.CapturedFrame) {
+	data := f.JPEG
+	if data == nil {
+		cc.encBuf.Reset()
+		if err := jpeg.Encode(&cc.encBuf, f.Image, &jpeg.Options{Quality: cc.c.cfg.JPEGQuality}); err != nil {
+			return
+		}
+		data = append([]byte(nil), cc.encBuf.Bytes()...) // Retained in the ring
+	}
+	width, height := frameSize(f)
+	rf := ringFrame{jpeg: data, width: width, height: height, time: f.Time}
+	cc.lastSeen = time.Now()
+
+	// Post-event part of a clip in progress
+	if cc.clip != nil {
+		if f.Time.After(cc.clipEnd) {
+			cc.finishClip()
+		} else {
+			cc.appendToClip(rf)
+		}
+	}
+
+	// Ring: drop frames older than the pre-event window or over the memory limit
+	cc.ring = append(cc.ring, rf)
+	cc.ringBytes += len(data)
+	cutoff := f.Time.Add(-cc.c.cfg.PreDuration)
+	drop := 0
+	for drop < len(cc.ring)-1 && (cc.ring[drop].time.Before(cutoff) || cc.ringBytes > cc.c.cfg.RingBytes) {
+		cc.ringBytes -= len(cc.ring[drop].jpeg)
+		cc.ring[drop] = ringFrame{}
+		drop++
+	}
+	if drop > 0 {
+		cc.ring = cc.ring[drop:]
+	}
+}
+
+func (cc *clipCamera) trigger(ev Event) {
+	if cc.clip != nil {
+		// Extend the clip in progress
+		if end := ev.Time.Add(cc.c.cfg.PostDuration); end.After(cc.clipEnd) {
+			cc.clipEnd = end
+		}
+		cc.clipInfo.Extensions = append(cc.clipInfo.Extensions, ev)
+		return
+	}
+	if len(cc.ring) == 0 {
+		log.Printf("[Clips] %s: no frames buffered, clip for %q not saved", cc.name, ev.Trigger)
+		return
+	}
+
+	c := cc.c
+	c.mu.Lock()
+	cam, slot := cc.cam, cc.slot
+	c.mu.Unlock()
+
+	base := fmt.Sprintf("%s_%s_%s", cc.name, ev.Time.Format(segmentStamp), SafeName(ev.Trigger))
+	path := filepath.Join(c.cfg.Dir, base+segmentExt)
+	first := cc.ring[0]
+	clip, err := createAVI(path, first.width, first.height)
+	if err != nil {
+		log.Printf("[Clips] %s: %v", cc.name, err)
+		return
+	}
+	cc.clip, cc.clipPath = clip, path
+	cc.clipEnd = ev.Time.Add(c.cfg.PostDuration)
+	cc.clipInfo = ClipInfo{
+		File:     filepath.Base(path),
+		Camera:   cc.name,
+		Role:     cam.Role,
+		DeviceID: cam.DeviceID,
+		Identity: cam.Identity.String(),
+		Slot:     slot,
+		Event:    ev,
+		PreSec:   c.cfg.PreDuration.Seconds(),
+		PostSec:  c.cfg.PostDuration.Seconds(),
+	}
+	cc.lastSeen = time.Now()
+
+	// Pre-event frames (the ring itself is kept for later triggers)
+	start := ev.Time.Add(-c.cfg.PreDuration)
+	for _, rf := range cc.ring {
+		if rf.time.Before(start) {
+			continue
+		}
+		if !cc.appendToClip(rf) {
+			break // The write failed and closed the clip
+		}
+	}
+}
+
+// appendToClip adds rf to the clip in progress. It returns false when the
+// write failed and the clip was closed.
+func (cc *clipCamera) appendToClip(rf ringFrame) bool {
+	if rf.width != cc.clip.width || rf.height != cc.clip.height {
+		return true // Resolution changed mid-clip; keep the clip playable
+	}
+	if err := cc.clip.WriteFrame(rf.jpeg, rf.time); err != nil {
+		log.Printf("[Clips] %s: write failed: %v", cc.name, err)
+		cc.finishClip()
+		return false
+	}
+	if cc.clipInfo.Frames == 0 {
+		cc.clipInfo.Start = rf.time
+	}
+	cc.clipInfo.Frames++
+	cc.clipInfo.End = rf.time
+	return true
+}
+
+// finishClip closes the clip in progress and writes its sidecar JSON.
+func (cc *clipCamera) finishClip() {
+	if cc.clip == nil {
+		return
+	}
+	err := cc.clip.Close()
+	cc.clip = nil
+	if err != nil {
+		log.Printf("[Clips] %s: closing %s: %v", cc.name, cc.clipPath, err)
+	}
+
+	sidecar := strings.TrimSuffix(cc.clipPath, segmentExt) + ".json"
+	data, err := json.MarshalIndent(cc.clipInfo, "", "  ")
+	if err == nil {
+		err = os.WriteFile(sidecar, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		log.Printf("[Clips] %s: writing %s: %v", cc.name, sidecar, err)
+		return
+	}
+	cc.c.saved.Add(1)
+	log.Printf("[Clips] %s: saved %s (%d frames, %.1fs)", cc.name, cc.clipInfo.File,
+		cc.clipInfo.Frames, cc.clipInfo.End.Sub(cc.clipInfo.Start).Seconds())
+}
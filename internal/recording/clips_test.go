@@ -0,0 +1,140 @@
+package recording
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClipRecorder_PreAndPostFrames(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewClipRecorder(ClipConfig{Dir: dir, PreDuration: time.Second, PostDuration: time.Second})
+	if err != nil {
+		t.Fatalf("NewClipRecorder: %v", err)
+	}
+
+	rear := camera.Camera{DeviceID: "video2", Role: "Rear", Identity: camera.CameraIdentity{PortPath: "1-1.3"}}
+	jpg := testJPEG(t, 16, 8)
+	t0 := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	frame := func(i int) camera.CapturedFrame {
+		return camera.CapturedFrame{Camera: rear, Slot: 2, Time: t0.Add(time.Duration(i) * 100 * time.Millisecond), JPEG: jpg}
+	}
+
+	// 3s of history at 10 FPS, trigger at 3s, then 2s more
+	for i := 0; i < 30; i++ {
+		c.WriteFrame(frame(i))
+	}
+	if n := c.Trigger(Event{Trigger: TriggerButton, Camera: "rear", Time: t0.Add(3 * time.Second)}); n != 1 {
+		t.Fatalf("Trigger matched %d cameras, want 1", n)
+	}
+	if n := c.Trigger(Event{Camera: "left"}); n != 0 {
+		t.Errorf("Trigger for unknown camera matched %d cameras", n)
+	}
+	for i := 30; i < 50; i++ {
+		c.WriteFrame(frame(i))
+	}
+	c.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "Rear_20260506-070812_button.json"))
+	if err != nil {
+		t.Fatalf("sidecar: %v", err)
+	}
+	var info ClipInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("sidecar JSON: %v", err)
+	}
+	// Frames 20..40: 1s before (2.0s..2.9s) and 1s after (3.0s..4.0s)
+	if info.Frames != 21 {
+		t.Errorf("clip frames = %d, want 21", info.Frames)
+	}
+	if !info.Start.Equal(t0.Add(2*time.Second)) || !info.End.Equal(t0.Add(4*time.Second)) {
+		t.Errorf("clip span = %v..%v", info.Start, info.End)
+	}
+	if info.Role != "Rear" || info.DeviceID != "video2" || info.Slot != 2 || info.Event.Trigger != TriggerButton {
+		t.Errorf("sidecar metadata = %+v", info)
+	}
+	if _, err := os.Stat(filepath.Join(dir, info.File)); err != nil {
+		t.Errorf("clip file: %v", err)
+	}
+}
+
+func TestClipRecorder_OverlappingTriggerExtends(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewClipRecorder(ClipConfig{Dir: dir, PreDuration: time.Second, PostDuration: time.Second})
+	if err != nil {
+		t.Fatalf("NewClipRecorder: %v", err)
+	}
+	cam := camera.Camera{DeviceID: "video0"}
+	jpg := testJPEG(t, 16, 8)
+	t0 := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		ts := t0.Add(time.Duration(i) * 100 * time.Millisecond)
+		c.WriteFrame(camera.CapturedFrame{Camera: cam, Time: ts, JPEG: jpg})
+		switch i {
+		case 10:
+			c.Trigger(Event{Trigger: TriggerAPI, Time: ts})
+		case 15:
+			c.Trigger(Event{Trigger: TriggerMotion, Detail: "zone1", Time: ts})
+		}
+	}
+	c.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(matches) != 1 {
+		t.Fatalf("sidecars = %v, want one extended clip", matches)
+	}
+	data, _ := os.ReadFile(matches[0])
+	var info ClipInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Extensions) != 1 || info.Extensions[0].Detail != "zone1" {
+		t.Errorf("extensions = %+v", info.Extensions)
+	}
+	if !info.End.Equal(t0.Add(2500 * time.Millisecond)) {
+		t.Errorf("clip end = %v, want extended to 2.5s", info.End.Sub(t0))
+	}
+}
+
+func TestClipRecorder_WriteFailureDuringTrigger(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full")
+	}
+	dir := t.TempDir()
+	// Writes to the clip fail with "no space left on device"
+	if err := os.Symlink("/dev/full", filepath.Join(dir, "Rear_20260506-070810_button"+segmentExt)); err != nil {
+		t.Skip(err)
+	}
+	c, err := NewClipRecorder(ClipConfig{Dir: dir, PreDuration: time.Second, PostDuration: time.Second})
+	if err != nil {
+		t.Fatalf("NewClipRecorder: %v", err)
+	}
+
+	// Frames larger than the AVI write buffer go straight to the file
+	jpg := append(testJPEG(t, 16, 8), make([]byte, 300<<10)...)
+	rear := camera.Camera{DeviceID: "video2", Role: "Rear"}
+	t0 := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		c.WriteFrame(camera.CapturedFrame{Camera: rear, Time: t0.Add(time.Duration(i) * 100 * time.Millisecond), JPEG: jpg})
+	}
+	// Every buffered frame is pre-event, so the first failed write must
+	// stop the rest instead of writing to the closed clip
+	c.Trigger(Event{Trigger: TriggerButton, Time: t0.Add(time.Second)})
+	c.WriteFrame(camera.CapturedFrame{Camera: rear, Time: t0.Add(1100 * time.Millisecond), JPEG: jpg})
+	c.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "Rear_20260506-070810_button.json"))
+	if err != nil {
+		t.Fatalf("sidecar: %v", err)
+	}
+	var info ClipInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("sidecar JSON: %v", err)
+	}
+	if info.Frames != 0 {
+		t.Errorf("clip frames = %d, want 0", info.Frames)
+	}
+}
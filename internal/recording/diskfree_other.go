@@ -0,0 +1,10 @@
+//go:build !linux
+
+package recording
+
+import "errors"
+
+// diskFree is not implemented off Linux; the free-space limit is skipped.
+func diskFree(dir string) (uint64, error) {
+	return 0, errors.New("free space check not supported on this platform")
+}
@@ -0,0 +1,450 @@
+// Package recording implements loop recording (DVR) of camera frames.
+//
+// A Recorder is attached to the camera Manager as a camera.FrameSink. Every
+// camera gets its own writer goroutine that stores frames in segmented
+// MJPEG-in-AVI files; a retention pass deletes the oldest segments when the
+// recordings exceed their size budget or the disk runs low on free space.
+// Frames are queued without blocking, so a slow disk drops recorded frames
+// instead of stalling capture.
+package recording
+
+import (
+	"bytes"
+	"camera-dashboard-go/internal/camera"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for zero Config values
+const (
+	DefaultSegmentDuration = time.Minute
+	DefaultJPEGQuality     = 85
+	DefaultQueueFrames     = 64
+)
+
+// segmentIdleTimeout closes a segment when its camera stops delivering frames
+// (unplugged or stalled), so the file is finalized and playable.
+const segmentIdleTimeout = 10 * time.Second
+
+// retentionInterval re-checks free space even when no segment is closed,
+// because other writers may fill the disk.
+const retentionInterval = time.Minute
+
+// segmentExt is the extension of segment and clip files.
+const segmentExt = ".avi"
+
+// segmentStamp is the time layout in segment and clip file names.
+const segmentStamp = "20060102-150405"
+
+// Config controls the recorder.
+type Config struct {
+	Dir             string        // Directory for segment files (created if missing)
+	SegmentDuration time.Duration // Length of one segment
+	MaxTotalBytes   int64         // Delete oldest segments above this total (0 = no limit)
+	MinFreeBytes    int64         // Delete oldest segments while free space is below this (0 = off)
+	JPEGQuality     int           // Quality for frames that must be encoded (raw formats, rotated)
+	QueueFrames     int           // Per-camera queue; frames are dropped when it is full
+}
+
+// Recorder writes segmented recordings for every camera it receives frames from.
+type Recorder struct {
+	cfg Config
+
+	mu      sync.Mutex
+	cams    map[string]*cameraRecorder
+	closed  bool
+	writers sync.WaitGroup
+
+	// Segments currently being written; retention never deletes them
+	openMu   sync.Mutex
+	openSegs map[string]bool
+
+	retentionCh chan struct{}
+	stopCh      chan struct{}
+	retentionWG sync.WaitGroup
+
+	// diskFree returns free bytes for a directory (replaced in tests)
+	diskFree func(dir string) (uint64, error)
+
+	dropped  atomic.Uint64
+	segments atomic.Uint64
+	deleted  atomic.Uint64
+}
+
+// New creates a recorder writing into cfg.Dir and starts its retention loop.
+func New(cfg Config) (*Recorder, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("recording directory not set")
+	}
+	if cfg.SegmentDuration <= 0 {
+		cfg.SegmentDuration = DefaultSegmentDuration
+	}
+	if cfg.JPEGQuality <= 0 || cfg.JPEGQuality > 100 {
+		cfg.JPEGQuality = DefaultJPEGQuality
+	}
+	if cfg.QueueFrames <= 0 {
+		cfg.QueueFrames = DefaultQueueFrames
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recording directory: %w", err)
+	}
+
+	r := &Recorder{
+		cfg:         cfg,
+		cams:        make(map[string]*cameraRecorder),
+		openSegs:    make(map[string]bool),
+		retentionCh: make(chan struct{}, 1),
+		stopCh:      make(chan struct{}),
+		diskFree:    diskFree,
+	}
+	r.retentionWG.Add(1)
+	go r.retentionLoop()
+	r.requestRetention()
+
+	log.Printf("[Recording] Recording to %s (%s segments, max %d MB, min free %d MB)",
+		cfg.Dir, cfg.SegmentDuration, cfg.MaxTotalBytes>>20, cfg.MinFreeBytes>>20)
+	return r, nil
+}
+
+// WriteFrame queues a frame for its camera's writer. Never blocks: when the
+// queue is full (disk too slow) the frame is dropped and counted.
+func (r *Recorder) WriteFrame(f camera.CapturedFrame) {
+	if f.Image == nil && f.JPEG == nil {
+		return
+	}
+	key := cameraKey(f.Camera, f.Slot)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	cr, ok := r.cams[key]
+	if !ok {
+		cr = &cameraRecorder{
+			r:      r,
+			name:   key,
+			frames: make(chan camera.CapturedFrame, r.cfg.QueueFrames),
+		}
+		r.cams[key] = cr
+		r.writers.Add(1)
+		go cr.run()
+	}
+
+	select {
+	case cr.frames <- f:
+	default:
+		if n := r.dropped.Add(1); n == 1 || n%100 == 0 {
+			log.Printf("[Recording] %s: queue full, dropped %d frames so far (disk too slow?)", key, n)
+		}
+	}
+}
+
+// Close finalizes all open segments and stops the recorder.
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	for _, cr := range r.cams {
+		close(cr.frames)
+	}
+	r.mu.Unlock()
+
+	r.writers.Wait()
+	close(r.stopCh)
+	r.retentionWG.Wait()
+	log.Printf("[Recording] Stopped (%d segments written, %d deleted, %d frames dropped)",
+		r.segments.Load(), r.deleted.Load(), r.dropped.Load())
+}
+
+// Dir returns the recording directory.
+func (r *Recorder) Dir() string {
+	return r.cfg.Dir
+}
+
+// DroppedFrames returns how many frames were dropped because a queue was full.
+func (r *Recorder) DroppedFrames() uint64 {
+	return r.dropped.Load()
+}
+
+// requestRetention schedules a retention pass without blocking.
+func (r *Recorder) requestRetention() {
+	select {
+	case r.retentionCh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Recorder) setOpen(path string, open bool) {
+	r.openMu.Lock()
+	defer r.openMu.Unlock()
+	if open {
+		r.openSegs[path] = true
+	} else {
+		delete(r.openSegs, path)
+	}
+}
+
+func (r *Recorder) isOpen(path string) bool {
+	r.openMu.Lock()
+	defer r.openMu.Unlock()
+	return r.openSegs[path]
+}
+
+// =============================================================================
+// Per-camera writer
+// =============================================================================
+
+// cameraRecorder owns the segment file of one camera. Only its goroutine
+// touches seg, so no locking is needed.
+type cameraRecorder struct {
+	r      *Recorder
+	name   string
+	frames chan camera.CapturedFrame
+
+	seg       *aviWriter
+	segPath   string
+	segStart  time.Time
+	lastWrite time.Time
+	failing   bool
+	encBuf    bytes.Buffer
+}
+
+func (cr *cameraRecorder) run() {
+	defer cr.r.writers.Done()
+	defer cr.closeSegment()
+
+	idle := time.NewTicker(segmentIdleTimeout / 2)
+	defer idle.Stop()
+
+	for {
+		select {
+		case f, ok := <-cr.frames:
+			if !ok {
+				return
+			}
+			cr.write(f)
+		case <-idle.C:
+			if cr.seg != nil && time.Since(cr.lastWrite) > segmentIdleTimeout {
+				log.Printf("[Recording] %s: no frames for %s, closing segment", cr.name, segmentIdleTimeout)
+				cr.closeSegment()
+			}
+		}
+	}
+}
+
+func (cr *cameraRecorder) write(f camera.CapturedFrame) {
+	data := f.JPEG
+	if data == nil {
+		cr.encBuf.Reset()
+		if err := jpeg.Encode(&cr.encBuf, f.Image, &jpeg.Options{Quality: cr.r.cfg.JPEGQuality}); err != nil {
+			return
+		}
+		data = cr.encBuf.Bytes()
+	}
+	width, height := frameSize(f)
+
+	if cr.seg != nil && (f.Time.Sub(cr.segStart) >= cr.r.cfg.SegmentDuration ||
+		width != cr.seg.width || height != cr.seg.height ||
+		cr.seg.Size()+int64(len(data)) > maxAVIBytes) {
+		cr.closeSegment()
+	}
+	if cr.seg == nil {
+		path := filepath.Join(cr.r.cfg.Dir, segmentName(cr.name, f.Time))
+		seg, err := createAVI(path, width, height)
+		if err != nil {
+			cr.fail(err)
+			return
+		}
+		cr.seg, cr.segPath, cr.segStart = seg, path, f.Time
+		cr.r.setOpen(path, true)
+	}
+
+	if err := cr.seg.WriteFrame(data, f.Time); err != nil {
+		cr.fail(err)
+		cr.closeSegment()
+		return
+	}
+	cr.lastWrite = time.Now()
+	if cr.failing {
+		log.Printf("[Recording] %s: writing again", cr.name)
+		cr.failing = false
+	}
+}
+
+// fail logs a write error once until writing succeeds again.
+func (cr *cameraRecorder) fail(err error) {
+	if !cr.failing {
+		log.Printf("[Recording] %s: write failed: %v", cr.name, err)
+		cr.failing = true
+	}
+	cr.r.requestRetention() // Maybe the disk is full
+}
+
+func (cr *cameraRecorder) closeSegment() {
+	if cr.seg == nil {
+		return
+	}
+	frames := cr.seg.Frames()
+	if err := cr.seg.Close(); err != nil {
+		log.Printf("[Recording] %s: closing %s: %v", cr.name, cr.segPath, err)
+	}
+	if frames == 0 {
+		os.Remove(cr.segPath)
+	} else {
+		cr.r.segments.Add(1)
+	}
+	cr.r.setOpen(cr.segPath, false)
+	cr.seg, cr.segPath = nil, ""
+	cr.r.requestRetention()
+}
+
+// frameSize returns the frame dimensions, reading the JPEG header when the
+// frame has no decoded image.
+func frameSize(f camera.CapturedFrame) (int, int) {
+	if f.Image != nil {
+		b := f.Image.Bounds()
+		return b.Dx(), b.Dy()
+	}
+	if cfg, err := jpeg.DecodeConfig(bytes.NewReader(f.JPEG)); err == nil {
+		return cfg.Width, cfg.Height
+	}
+	return 0, 0
+}
+
+// =============================================================================
+// Naming
+// =============================================================================
+
+// cameraKey names a camera in file names: its role, else its USB port path,
+// else its device ID. All of these survive a Manager reinitialization.
+func cameraKey(cam camera.Camera, slot int) string {
+	name := cam.Role
+	if name == "" {
+		name = cam.Identity.PortPath
+	}
+	if name == "" {
+		name = cam.DeviceID
+	}
+	if name == "" {
+		name = fmt.Sprintf("camera%d", slot)
+	}
+	return SafeName(name)
+}
+
+// SafeName turns a camera label into a string usable in file names.
+func SafeName(name string) string {
+	return camera.SafeFileName(name)
+}
+
+// segmentName returns the file name of a segment starting at t.
+func segmentName(camName string, t time.Time) string {
+	return fmt.Sprintf("%s_%s%s", camName, t.Format(segmentStamp), segmentExt)
+}
+
+// isSegmentName reports whether name has the segmentName form. Retention only
+// touches these, so event clips (<camera>_<time>_<trigger>.avi) survive when
+// [clips] dir is the recording directory.
+func isSegmentName(name string) bool {
+	base := strings.TrimSuffix(name, segmentExt)
+	if base == name || len(base) < len(segmentStamp)+2 || base[len(base)-len(segmentStamp)-1] != '_' {
+		return false
+	}
+	_, err := time.Parse(segmentStamp, base[len(base)-len(segmentStamp):])
+	return err == nil
+}
+
+// =============================================================================
+// Retention
+// =============================================================================
+
+func (r *Recorder) retentionLoop() {
+	defer r.retentionWG.Done()
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			r.enforceRetention()
+			return
+		case <-r.retentionCh:
+			r.enforceRetention()
+		case <-ticker.C:
+			r.enforceRetention()
+		}
+	}
+}
+
+type segmentFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// enforceRetention deletes the oldest closed segments until the total size
+// and free space limits are met.
+func (r *Recorder) enforceRetention() {
+	if r.cfg.MaxTotalBytes <= 0 && r.cfg.MinFreeBytes <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(r.cfg.Dir)
+	if err != nil {
+		log.Printf("[Recording] Retention: %v", err)
+		return
+	}
+	var files []segmentFile
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !isSegmentName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, segmentFile{filepath.Join(r.cfg.Dir, e.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	free := int64(-1) // Unknown
+	if r.cfg.MinFreeBytes > 0 {
+		if n, err := r.diskFree(r.cfg.Dir); err == nil {
+			free = int64(n)
+		}
+	}
+
+	for _, sf := range files {
+		overTotal := r.cfg.MaxTotalBytes > 0 && total > r.cfg.MaxTotalBytes
+		lowFree := free >= 0 && free < r.cfg.MinFreeBytes
+		if !overTotal && !lowFree {
+			return
+		}
+		if r.isOpen(sf.path) {
+			continue
+		}
+		if err := os.Remove(sf.path); err != nil {
+			log.Printf("[Recording] Retention: %v", err)
+			continue
+		}
+		r.deleted.Add(1)
+		total -= sf.size
+		if free >= 0 {
+			free += sf.size
+		}
+		log.Printf("[Recording] Retention: deleted %s (%d KB)", filepath.Base(sf.path), sf.size>>10)
+	}
+}
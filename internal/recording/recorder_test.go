@@ -0,0 +1,152 @@
+package recording
+
+import (
+	"bytes"
+	"camera-dashboard-go/internal/camera"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func listSegments(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func TestRecorder_SegmentsPerCamera(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(Config{Dir: dir, SegmentDuration: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	rear := camera.Camera{DeviceID: "video2", Role: "Rear"}
+	left := camera.Camera{DeviceID: "video0", Identity: camera.CameraIdentity{PortPath: "1-1.2"}}
+	jpg := testJPEG(t, 16, 8)
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// 5 seconds of rear frames at 2 FPS -> 3 segments of 2s
+	for i := 0; i < 10; i++ {
+		r.WriteFrame(camera.CapturedFrame{Camera: rear, Time: start.Add(time.Duration(i) * 500 * time.Millisecond), JPEG: jpg})
+	}
+	// Raw-format camera: encoded by the recorder
+	r.WriteFrame(camera.CapturedFrame{Camera: left, Time: start, Image: image.NewGray(image.Rect(0, 0, 8, 8))})
+	r.Close()
+
+	segs := listSegments(t, dir)
+	want := []string{
+		"1-1.2_20260102-030405.avi",
+		"Rear_20260102-030405.avi",
+		"Rear_20260102-030407.avi",
+		"Rear_20260102-030409.avi",
+	}
+	if len(segs) != len(want) {
+		t.Fatalf("segments = %v, want %v", segs, want)
+	}
+	for i := range want {
+		if filepath.Base(segs[i]) != want[i] {
+			t.Errorf("segment %d = %s, want %s", i, filepath.Base(segs[i]), want[i])
+		}
+	}
+}
+
+func TestRecorder_RetentionDeletesOldest(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	// The clip is the oldest .avi but is not a segment, so retention skips it
+	clip := "Rear_20260506-070759_button.avi"
+	a, b, c := "Rear_20260506-070800.avi", "Rear_20260506-070801.avi", "Rear_20260506-070802.avi"
+	for i, name := range []string{clip, a, b, c, "keep.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 1000), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(time.Duration(i-10) * time.Minute)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &Recorder{cfg: Config{Dir: dir, MaxTotalBytes: 2000}, openSegs: map[string]bool{}}
+	r.enforceRetention()
+	if got := listSegments(t, dir); len(got) != 3 || filepath.Base(got[1]) != b || filepath.Base(got[2]) != c {
+		t.Errorf("after size limit: %v, want %s %s %s", got, clip, b, c)
+	}
+
+	// Free space: 500 bytes free, 1200 wanted -> deletes one more, skipping the open segment
+	r = &Recorder{
+		cfg:      Config{Dir: dir, MinFreeBytes: 1200},
+		openSegs: map[string]bool{filepath.Join(dir, b): true},
+		diskFree: func(string) (uint64, error) { return 500, nil },
+	}
+	r.enforceRetention()
+	if got := listSegments(t, dir); len(got) != 2 || filepath.Base(got[1]) != b {
+		t.Errorf("after free space limit: %v, want open %s kept", got, b)
+	}
+	for _, name := range []string{clip, "keep.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("non-segment file deleted: %v", err)
+		}
+	}
+}
+
+func TestRecorder_WriteFrameNeverBlocks(t *testing.T) {
+	r, err := New(Config{Dir: t.TempDir(), QueueFrames: 1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer r.Close()
+
+	cam := camera.Camera{DeviceID: "video0"}
+	img := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 200; i++ {
+			r.WriteFrame(camera.CapturedFrame{Camera: cam, Time: time.Now(), Image: img})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("WriteFrame blocked on a full queue")
+	}
+	if r.DroppedFrames() == 0 {
+		t.Error("expected frames to be dropped with a 1-frame queue")
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	for in, want := range map[string]string{
+		"Rear":          "Rear",
+		"Trailer Cam 2": "Trailer_Cam_2",
+		"../etc":        "etc",
+		"":              "camera",
+	} {
+		if got := SafeName(in); got != want {
+			t.Errorf("SafeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
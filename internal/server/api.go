@@ -0,0 +1,495 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Controller exposes the dashboard's state and actions to the REST API.
+type Controller interface {
+	State() State
+	Restart()
+	Exit()
+	Snapshot(camIndex int) ([]string, error) // camIndex < 0 = all cameras
+	SaveClip(camera string) int              // camera "" = all; returns cameras triggered
+	SetMotionDetection(slot int, enabled bool) error
+	SetTrigger(name string, active bool) error // Triggers with source "api"
+	SetSteering(value float64) error           // Parking guide steering, -1..1
+}
+
+// DisplayController adds the settings-tile display actions. The UI
+// implements it on top of its own methods, so the API and the touch screen
+// share one code path. Without it (headless mode) the display endpoints
+// answer 501 Not Implemented.
+type DisplayController interface {
+	Controller
+	SetNightMode(enabled bool)
+	SetBrightness(percent int) error
+	Swap(pos1, pos2 int) error
+	ShowFullscreen(gridPos int) error
+	HideFullscreen()
+}
+
+// State is the dashboard state returned by GET /api/state.
+type State struct {
+	Cameras    []CameraState  `json:"cameras"`
+	Grid       []int          `json:"grid"`       // Content of each grid position: -1 = settings, >= 0 = camera slot
+	Fullscreen int            `json:"fullscreen"` // Grid position shown fullscreen, -1 = none
+	NightMode  bool           `json:"night_mode"`
+	Brightness int            `json:"brightness"` // Percent preset
+	FPS        FPSState       `json:"fps"`
+	Triggers   []TriggerState `json:"triggers"`
+	Steering   float64        `json:"steering"` // Parking guide steering, -1 (left) .. 1 (right)
+}
+
+// CameraState describes one camera slot.
+type CameraState struct {
+	Slot       int     `json:"slot"`
+	Label      string  `json:"label"`
+	Role       string  `json:"role,omitempty"`
+	Name       string  `json:"name,omitempty"`
+	DeviceID   string  `json:"device_id,omitempty"`
+	DevicePath string  `json:"device_path,omitempty"`
+	Identity   string  `json:"identity,omitempty"`
+	Connected  bool    `json:"connected"`
+	CaptureFPS float64 `json:"capture_fps"`
+
+	MotionDetection bool `json:"motion_detection"` // Motion detection enabled
+	Motion          bool `json:"motion"`           // Motion currently detected
+}
+
+// TriggerState describes one [trigger.<name>] input.
+type TriggerState struct {
+	Name   string `json:"name"`
+	Source string `json:"source"` // "gpio", "file" or "api"
+	Action string `json:"action"` // "fullscreen" or "clip"
+	Camera string `json:"camera,omitempty"`
+	Active bool   `json:"active"`
+}
+
+// FPSState is the adaptive FPS controller's view.
+type FPSState struct {
+	Current   int    `json:"current"`
+	SweetSpot int    `json:"sweet_spot"`
+	State     string `json:"state"` // Controller state, e.g. "Stable"
+	Dynamic   bool   `json:"dynamic"`
+}
+
+// shutdownDelay lets the response to restart/exit reach the client before
+// the dashboard closes the server.
+const shutdownDelay = 200 * time.Millisecond
+
+// maxRequestBody bounds API request bodies.
+const maxRequestBody = 4 << 10
+
+// EnableAPI registers the JSON control API under /api/. Must be called
+// before Start.
+//
+// With Config.APIToken set, every request needs "Authorization: Bearer
+// <token>". Requests other than GET must be sent as application/json, even
+// without a body: browsers only send that cross-site after a CORS preflight,
+// which the server never grants, so other web pages cannot drive the API.
+//
+//	GET    /api/state       dashboard state
+//	GET    /api/cameras     camera list
+//	POST   /api/restart     restart the dashboard
+//	POST   /api/exit        exit the dashboard
+//	POST   /api/nightmode   {"enabled": bool}; toggles when omitted (display)
+//	POST   /api/brightness  {"percent": 15|60|80|100|150} (display)
+//	POST   /api/swap        {"a": gridPos, "b": gridPos} (display)
+//	POST   /api/fullscreen  {"position": gridPos} (display)
+//	DELETE /api/fullscreen  leave fullscreen (display)
+//	POST   /api/snapshot    {"camera": slot}; all cameras when omitted
+//	POST   /api/clip        {"camera": slot|role|device}; all cameras when omitted
+//	POST   /api/motion      {"camera": slot, "enabled": bool}; toggles when enabled is omitted
+//	POST   /api/trigger     {"name": string, "active": bool} for triggers with source "api"
+//	POST   /api/steering    {"value": -1..1} bends the parking guides
+func (s *Server) EnableAPI(c Controller) {
+	api := &apiHandler{c: c, token: s.cfg.APIToken}
+	if api.token == "" && !isLoopback(s.cfg.Addr) {
+		log.Printf("[HTTP] Warning: control API on %s has no api_token; anyone who can reach it can control the dashboard", s.cfg.Addr)
+	}
+	handle := func(path string, h http.HandlerFunc) {
+		s.mux.HandleFunc(path, api.guard(h))
+	}
+	handle("/api/state", api.state)
+	handle("/api/cameras", api.cameras)
+	handle("/api/restart", api.restart)
+	handle("/api/exit", api.exit)
+	handle("/api/nightmode", api.nightMode)
+	handle("/api/brightness", api.brightness)
+	handle("/api/swap", api.swap)
+	handle("/api/fullscreen", api.fullscreen)
+	handle("/api/snapshot", api.snapshot)
+	handle("/api/clip", api.clip)
+	handle("/api/motion", api.motion)
+	handle("/api/trigger", api.trigger)
+	handle("/api/steering", api.steering)
+}
+
+type apiHandler struct {
+	c     Controller
+	token string
+}
+
+// guard answers 401 without the API token and 415 for a request other than
+// GET that is not JSON, before h sees it.
+func (h *apiHandler) guard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.token != "" {
+			got, ok := cutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="camera-dashboard"`)
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or wrong API token"))
+				return
+			}
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("%s needs Content-Type: application/json", r.Method))
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// cutPrefix is strings.CutPrefix, which needs Go 1.20.
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+// isLoopback reports whether a listen address only accepts local clients.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (h *apiHandler) state(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.c.State())
+}
+
+func (h *apiHandler) cameras(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.c.State().Cameras)
+}
+
+func (h *apiHandler) restart(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	log.Printf("[HTTP] API restart requested by %s", r.RemoteAddr)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "restarting"})
+	time.AfterFunc(shutdownDelay, h.c.Restart)
+}
+
+func (h *apiHandler) exit(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	log.Printf("[HTTP] API exit requested by %s", r.RemoteAddr)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "exiting"})
+	time.AfterFunc(shutdownDelay, h.c.Exit)
+}
+
+// display returns the DisplayController, or answers 501 without one.
+func (h *apiHandler) display(w http.ResponseWriter) (DisplayController, bool) {
+	d, ok := h.c.(DisplayController)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("no display (headless mode)"))
+	}
+	return d, ok
+}
+
+func (h *apiHandler) nightMode(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	d, ok := h.display(w)
+	if !ok {
+		return
+	}
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	enabled := !h.c.State().NightMode
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	d.SetNightMode(enabled)
+	writeJSON(w, http.StatusOK, h.c.State())
+}
+
+func (h *apiHandler) brightness(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	d, ok := h.display(w)
+	if !ok {
+		return
+	}
+	var req struct {
+		Percent *int `json:"percent"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Percent == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("percent is required"))
+		return
+	}
+	if err := d.SetBrightness(*req.Percent); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.c.State())
+}
+
+func (h *apiHandler) swap(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	d, ok := h.display(w)
+	if !ok {
+		return
+	}
+	var req struct {
+		A *int `json:"a"`
+		B *int `json:"b"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.A == nil || req.B == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("a and b grid positions are required"))
+		return
+	}
+	if err := d.Swap(*req.A, *req.B); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.c.State())
+}
+
+func (h *apiHandler) fullscreen(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost, http.MethodDelete) {
+		return
+	}
+	d, ok := h.display(w)
+	if !ok {
+		return
+	}
+	if r.Method == http.MethodDelete {
+		d.HideFullscreen()
+		writeJSON(w, http.StatusOK, h.c.State())
+		return
+	}
+	var req struct {
+		Position *int `json:"position"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Position == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("position is required"))
+		return
+	}
+	if err := d.ShowFullscreen(*req.Position); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.c.State())
+}
+
+func (h *apiHandler) snapshot(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Camera *int `json:"camera"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	index := -1
+	if req.Camera != nil {
+		index = *req.Camera
+	}
+	files, err := h.c.Snapshot(index)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"files": files})
+}
+
+func (h *apiHandler) clip(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Camera json.RawMessage `json:"camera"` // Slot number or name
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	var camera string
+	if len(req.Camera) > 0 && string(req.Camera) != "null" {
+		var slot int
+		if err := json.Unmarshal(req.Camera, &slot); err == nil {
+			camera = fmt.Sprint(slot)
+		} else if err := json.Unmarshal(req.Camera, &camera); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("camera must be a slot number or name"))
+			return
+		}
+	}
+	n := h.c.SaveClip(camera)
+	if n == 0 {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("no camera recording clips"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"cameras": n})
+}
+
+func (h *apiHandler) motion(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Camera  *int  `json:"camera"`
+		Enabled *bool `json:"enabled"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Camera == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("camera slot is required"))
+		return
+	}
+	st := h.c.State()
+	if *req.Camera < 0 || *req.Camera >= len(st.Cameras) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("no camera in slot %d", *req.Camera))
+		return
+	}
+	enabled := !st.Cameras[*req.Camera].MotionDetection
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	if err := h.c.SetMotionDetection(*req.Camera, enabled); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.c.State())
+}
+
+func (h *apiHandler) trigger(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Name   string `json:"name"`
+		Active *bool  `json:"active"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" || req.Active == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("name and active are required"))
+		return
+	}
+	if err := h.c.SetTrigger(req.Name, *req.Active); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	log.Printf("[HTTP] API trigger %s set to %v by %s", req.Name, *req.Active, r.RemoteAddr)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"name": req.Name, "active": *req.Active})
+}
+
+func (h *apiHandler) steering(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var req struct {
+		Value *float64 `json:"value"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Value == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("value is required"))
+		return
+	}
+	if err := h.c.SetSteering(*req.Value); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]float64{"steering": *req.Value})
+}
+
+// allowMethod answers 405 unless r uses one of methods.
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	for _, m := range methods {
+		w.Header().Add("Allow", m)
+	}
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// readJSON decodes an optional JSON body into v; an empty body leaves v unchanged.
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON body: %w", err))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[HTTP] Encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,381 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeController records API calls against a minimal dashboard state.
+type fakeController struct {
+	mu        sync.Mutex
+	state     State
+	restarted chan struct{}
+	clipFor   string
+	triggers  map[string]bool
+}
+
+func (f *fakeController) SetSteering(value float64) error {
+	if value < -1 || value > 1 {
+		return fmt.Errorf("steering %v out of range", value)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.Steering = value
+	return nil
+}
+
+func newFakeController() *fakeController {
+	return &fakeController{
+		state: State{
+			Cameras:    []CameraState{{Slot: 0, Label: "Rear", Connected: true}},
+			Grid:       []int{-1, 0},
+			Fullscreen: -1,
+			Brightness: 100,
+		},
+		restarted: make(chan struct{}, 1),
+	}
+}
+
+func (f *fakeController) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+func (f *fakeController) Restart() { f.restarted <- struct{}{} }
+func (f *fakeController) Exit()    {}
+
+func (f *fakeController) SetNightMode(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.NightMode = enabled
+}
+
+func (f *fakeController) SetBrightness(percent int) error {
+	if percent != 60 && percent != 100 {
+		return fmt.Errorf("unsupported brightness %d", percent)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.Brightness = percent
+	return nil
+}
+
+func (f *fakeController) Swap(pos1, pos2 int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g := f.state.Grid
+	if pos1 < 0 || pos2 < 0 || pos1 >= len(g) || pos2 >= len(g) {
+		return fmt.Errorf("out of range")
+	}
+	g[pos1], g[pos2] = g[pos2], g[pos1]
+	return nil
+}
+
+func (f *fakeController) ShowFullscreen(gridPos int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.Fullscreen = gridPos
+	return nil
+}
+
+func (f *fakeController) HideFullscreen() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.Fullscreen = -1
+}
+
+func (f *fakeController) Snapshot(camIndex int) ([]string, error) {
+	return []string{fmt.Sprintf("snap%d.jpg", camIndex)}, nil
+}
+
+func (f *fakeController) SaveClip(camera string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clipFor = camera
+	return 1
+}
+
+func (f *fakeController) SetMotionDetection(slot int, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state.Cameras[slot].MotionDetection = enabled
+	return nil
+}
+
+func (f *fakeController) SetTrigger(name string, active bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if name != "reverse" {
+		return fmt.Errorf("unknown trigger %q", name)
+	}
+	if f.triggers == nil {
+		f.triggers = make(map[string]bool)
+	}
+	f.triggers[name] = active
+	return nil
+}
+
+func newAPIServer(t *testing.T) (*httptest.Server, *fakeController) {
+	t.Helper()
+	return newAPIServerWith(t, Config{})
+}
+
+func newAPIServerWith(t *testing.T, cfg Config) (*httptest.Server, *fakeController) {
+	t.Helper()
+	ctrl := newFakeController()
+	srv := New(cfg, newTestSource())
+	srv.EnableAPI(ctrl)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts, ctrl
+}
+
+func doJSON(t *testing.T, method, url, body string, out interface{}) int {
+	t.Helper()
+	return doRequest(t, method, url, body, map[string]string{"Content-Type": "application/json"}, out)
+}
+
+func doRequest(t *testing.T, method, url, body string, header map[string]string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAPI_StateAndSettings(t *testing.T) {
+	ts, _ := newAPIServer(t)
+
+	var st State
+	if code := doJSON(t, "GET", ts.URL+"/api/state", "", &st); code != http.StatusOK {
+		t.Fatalf("GET /api/state = %d", code)
+	}
+	if len(st.Cameras) != 1 || st.Cameras[0].Label != "Rear" || st.Fullscreen != -1 {
+		t.Errorf("state = %+v", st)
+	}
+
+	// Night mode toggles without a body and is set explicitly with one
+	doJSON(t, "POST", ts.URL+"/api/nightmode", "", &st)
+	if !st.NightMode {
+		t.Error("empty POST /api/nightmode should toggle night mode on")
+	}
+	doJSON(t, "POST", ts.URL+"/api/nightmode", `{"enabled": true}`, &st)
+	if !st.NightMode {
+		t.Error(`{"enabled": true} should keep night mode on`)
+	}
+
+	if code := doJSON(t, "POST", ts.URL+"/api/brightness", `{"percent": 60}`, &st); code != http.StatusOK || st.Brightness != 60 {
+		t.Errorf("brightness 60 = %d, state %d", code, st.Brightness)
+	}
+	if code := doJSON(t, "POST", ts.URL+"/api/brightness", `{"percent": 42}`, nil); code != http.StatusBadRequest {
+		t.Errorf("brightness 42 = %d, want 400", code)
+	}
+
+	if code := doJSON(t, "POST", ts.URL+"/api/swap", `{"a": 0, "b": 1}`, &st); code != http.StatusOK || st.Grid[0] != 0 {
+		t.Errorf("swap = %d, grid %v", code, st.Grid)
+	}
+	if code := doJSON(t, "POST", ts.URL+"/api/swap", `{"a": 0}`, nil); code != http.StatusBadRequest {
+		t.Errorf("swap without b = %d, want 400", code)
+	}
+
+	doJSON(t, "POST", ts.URL+"/api/fullscreen", `{"position": 1}`, &st)
+	if st.Fullscreen != 1 {
+		t.Errorf("fullscreen = %d, want 1", st.Fullscreen)
+	}
+	doJSON(t, "DELETE", ts.URL+"/api/fullscreen", "", &st)
+	if st.Fullscreen != -1 {
+		t.Errorf("fullscreen after DELETE = %d, want -1", st.Fullscreen)
+	}
+}
+
+func TestAPI_Errors(t *testing.T) {
+	ts, _ := newAPIServer(t)
+
+	if code := doJSON(t, "GET", ts.URL+"/api/restart", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /api/restart = %d, want 405", code)
+	}
+	var e map[string]string
+	if code := doJSON(t, "POST", ts.URL+"/api/brightness", `{"pct": 60}`, &e); code != http.StatusBadRequest || e["error"] == "" {
+		t.Errorf("unknown field = %d %v, want 400 with error", code, e)
+	}
+	if code := doJSON(t, "POST", ts.URL+"/api/fullscreen", `not json`, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid JSON = %d, want 400", code)
+	}
+}
+
+func TestAPI_Actions(t *testing.T) {
+	ts, ctrl := newAPIServer(t)
+
+	if code := doJSON(t, "POST", ts.URL+"/api/restart", "", nil); code != http.StatusAccepted {
+		t.Errorf("restart = %d, want 202", code)
+	}
+	select {
+	case <-ctrl.restarted:
+	case <-time.After(2 * time.Second):
+		t.Error("restart not called after responding")
+	}
+
+	var files map[string][]string
+	doJSON(t, "POST", ts.URL+"/api/snapshot", `{"camera": 0}`, &files)
+	if len(files["files"]) != 1 || files["files"][0] != "snap0.jpg" {
+		t.Errorf("snapshot files = %v", files)
+	}
+
+	for body, want := range map[string]string{`{"camera": 1}`: "1", `{"camera": "Rear"}`: "Rear", ``: ""} {
+		if code := doJSON(t, "POST", ts.URL+"/api/clip", body, nil); code != http.StatusOK {
+			t.Errorf("clip %s = %d", body, code)
+		}
+		if ctrl.clipFor != want {
+			t.Errorf("clip %s camera = %q, want %q", body, ctrl.clipFor, want)
+		}
+	}
+
+	var st State
+	for _, want := range []bool{true, false} {
+		if code := doJSON(t, "POST", ts.URL+"/api/motion", `{"camera": 0}`, &st); code != http.StatusOK {
+			t.Fatalf("motion toggle = %d", code)
+		}
+		if st.Cameras[0].MotionDetection != want {
+			t.Errorf("motion toggle: detection = %v, want %v", st.Cameras[0].MotionDetection, want)
+		}
+	}
+	doJSON(t, "POST", ts.URL+"/api/motion", `{"camera": 0, "enabled": true}`, &st)
+	if !st.Cameras[0].MotionDetection {
+		t.Error("motion enable: detection still off")
+	}
+	for _, body := range []string{``, `{"camera": 5}`} {
+		if code := doJSON(t, "POST", ts.URL+"/api/motion", body, nil); code != http.StatusBadRequest {
+			t.Errorf("motion %q = %d, want 400", body, code)
+		}
+	}
+
+	if code := doJSON(t, "POST", ts.URL+"/api/trigger", `{"name": "reverse", "active": true}`, nil); code != http.StatusOK {
+		t.Errorf("trigger = %d, want 200", code)
+	}
+	if !ctrl.triggers["reverse"] {
+		t.Error("trigger reverse not set")
+	}
+	for _, body := range []string{``, `{"name": "reverse"}`, `{"name": "door", "active": true}`} {
+		if code := doJSON(t, "POST", ts.URL+"/api/trigger", body, nil); code != http.StatusBadRequest {
+			t.Errorf("trigger %q = %d, want 400", body, code)
+		}
+	}
+
+	if code := doJSON(t, "POST", ts.URL+"/api/steering", `{"value": -0.5}`, nil); code != http.StatusOK {
+		t.Errorf("steering = %d, want 200", code)
+	}
+	doJSON(t, "GET", ts.URL+"/api/state", "", &st)
+	if st.Steering != -0.5 {
+		t.Errorf("state steering = %v, want -0.5", st.Steering)
+	}
+	for _, body := range []string{``, `{"value": 2}`} {
+		if code := doJSON(t, "POST", ts.URL+"/api/steering", body, nil); code != http.StatusBadRequest {
+			t.Errorf("steering %q = %d, want 400", body, code)
+		}
+	}
+}
+
+// headlessController hides the fake's display actions, like a dashboard
+// without a window.
+type headlessController struct {
+	Controller
+}
+
+func TestAPI_HeadlessDisplayEndpoints(t *testing.T) {
+	srv := New(Config{}, newTestSource())
+	srv.EnableAPI(headlessController{newFakeController()})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	for _, req := range [][2]string{
+		{"POST", "/api/nightmode"},
+		{"POST", "/api/brightness"},
+		{"POST", "/api/swap"},
+		{"POST", "/api/fullscreen"},
+		{"DELETE", "/api/fullscreen"},
+	} {
+		var e map[string]string
+		if code := doJSON(t, req[0], ts.URL+req[1], "", &e); code != http.StatusNotImplemented || e["error"] == "" {
+			t.Errorf("%s %s = %d %v, want 501 with error", req[0], req[1], code, e)
+		}
+	}
+	var files map[string][]string
+	if code := doJSON(t, "POST", ts.URL+"/api/snapshot", "", &files); code != http.StatusOK {
+		t.Errorf("snapshot without display = %d, want 200", code)
+	}
+}
+
+func TestAPI_TokenAndContentType(t *testing.T) {
+	ts, ctrl := newAPIServerWith(t, Config{APIToken: "s3cret"})
+	auth := "Bearer s3cret"
+
+	for _, h := range []map[string]string{
+		{},
+		{"Authorization": "Bearer wrong"},
+		{"Authorization": "s3cret"},
+		{"Authorization": "Bearer s3cret-but-longer"},
+	} {
+		if code := doRequest(t, "GET", ts.URL+"/api/state", "", h, nil); code != http.StatusUnauthorized {
+			t.Errorf("GET /api/state with %v = %d, want 401", h, code)
+		}
+	}
+	if code := doRequest(t, "GET", ts.URL+"/api/state", "", map[string]string{"Authorization": auth}, nil); code != http.StatusOK {
+		t.Errorf("GET /api/state with token = %d, want 200", code)
+	}
+
+	// A cross-site form or fetch can only send these content types
+	for _, ct := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		h := map[string]string{"Authorization": auth, "Content-Type": ct}
+		if code := doRequest(t, "POST", ts.URL+"/api/restart", "", h, nil); code != http.StatusUnsupportedMediaType {
+			t.Errorf("POST /api/restart as %q = %d, want 415", ct, code)
+		}
+	}
+	select {
+	case <-ctrl.restarted:
+		t.Error("restart called by a rejected request")
+	case <-time.After(2 * shutdownDelay):
+	}
+
+	h := map[string]string{"Authorization": auth, "Content-Type": "application/json; charset=utf-8"}
+	var st State
+	if code := doRequest(t, "POST", ts.URL+"/api/nightmode", `{"enabled": true}`, h, &st); code != http.StatusOK || !st.NightMode {
+		t.Errorf("POST /api/nightmode with token = %d, night mode %v", code, st.NightMode)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"localhost:80":   true,
+		":8080":          false,
+		"0.0.0.0:8080":   false,
+		"192.168.1.5:80": false,
+		"garbage":        false,
+	} {
+		if got := isLoopback(addr); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
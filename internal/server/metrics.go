@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Metric types of the Prometheus text format
+const (
+	Gauge   = "gauge"
+	Counter = "counter"
+)
+
+// MetricsSource fills a Metrics set on every scrape of /metrics.
+type MetricsSource interface {
+	CollectMetrics(m *Metrics)
+}
+
+// Metrics collects samples for one scrape and writes them in the Prometheus
+// text exposition format (version 0.0.4). Samples of a metric are grouped
+// under one HELP/TYPE header in the order the metric was first added.
+type Metrics struct {
+	order    []string
+	families map[string]*metricFamily
+}
+
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string
+	samples []sample
+}
+
+type sample struct {
+	labels string // Rendered {k="v",...} or ""
+	value  float64
+}
+
+// NewMetrics returns an empty metric set.
+func NewMetrics() *Metrics {
+	return &Metrics{families: make(map[string]*metricFamily)}
+}
+
+// Add records one sample. labels are name/value pairs, e.g.
+// Add("frames_total", "Frames captured", Counter, 42, "camera", "Rear").
+// help and kind of the first sample of a metric apply to all its samples.
+func (m *Metrics) Add(name, help, kind string, value float64, labels ...string) {
+	f := m.families[name]
+	if f == nil {
+		f = &metricFamily{name: name, help: help, kind: kind}
+		m.families[name] = f
+		m.order = append(m.order, name)
+	}
+	f.samples = append(f.samples, sample{labels: renderLabels(labels), value: value})
+}
+
+// AddBool records b as 1 or 0.
+func (m *Metrics) AddBool(name, help string, b bool, labels ...string) {
+	v := 0.0
+	if b {
+		v = 1
+	}
+	m.Add(name, help, Gauge, v, labels...)
+}
+
+// WriteTo writes all samples in text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, name := range m.order {
+		f := m.families[name]
+		c, _ := bw.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+		n += int64(c)
+		c, _ = bw.WriteString("# TYPE " + f.name + " " + f.kind + "\n")
+		n += int64(c)
+		for _, s := range f.samples {
+			c, _ = bw.WriteString(f.name + s.labels + " " + formatValue(s.value) + "\n")
+			n += int64(c)
+		}
+	}
+	return n, bw.Flush()
+}
+
+func renderLabels(pairs []string) string {
+	if len(pairs) < 2 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// EnableMetrics serves src's metrics at /metrics. Must be called before Start.
+func (s *Server) EnableMetrics(src MetricsSource) {
+	s.mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		m := NewMetrics()
+		src.CollectMetrics(m)
+		m.Add("camera_dashboard_http_streams", "Connected MJPEG stream clients.", Gauge, float64(s.ActiveStreams()))
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteTo(w)
+	})
+}
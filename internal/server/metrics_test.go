@@ -0,0 +1,66 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_TextFormat(t *testing.T) {
+	m := NewMetrics()
+	m.Add("frames_total", "Frames captured.", Counter, 42, "camera", "Rear", "slot", "0")
+	m.Add("temp_celsius", "CPU temperature.", Gauge, math.NaN())
+	m.Add("frames_total", "ignored", Counter, 7, "camera", `Say "hi"\n`, "slot", "1")
+	m.AddBool("connected", "Connected\nflag.", true)
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP frames_total Frames captured.
+# TYPE frames_total counter
+frames_total{camera="Rear",slot="0"} 42
+frames_total{camera="Say \"hi\"\\n",slot="1"} 7
+# HELP temp_celsius CPU temperature.
+# TYPE temp_celsius gauge
+temp_celsius NaN
+# HELP connected Connected\nflag.
+# TYPE connected gauge
+connected 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("exposition =\n%s\nwant\n%s", got, want)
+	}
+}
+
+type fakeMetricsSource struct{}
+
+func (fakeMetricsSource) CollectMetrics(m *Metrics) {
+	m.Add("camera_dashboard_fps_current", "Current FPS.", Gauge, 12)
+}
+
+func TestServer_MetricsEndpoint(t *testing.T) {
+	srv := New(Config{}, newTestSource())
+	srv.EnableMetrics(fakeMetricsSource{})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	for _, line := range []string{"camera_dashboard_fps_current 12\n", "camera_dashboard_http_streams 0\n"} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("metrics missing %q:\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+// Package server implements the optional embedded HTTP server.
+//
+// It serves live camera views to phones and laptops on the vehicle network:
+// a multipart/x-mixed-replace MJPEG stream and a single-JPEG endpoint per
+// camera. Streams poll each camera's FrameBuffer for its latest frame at the
+// capture FPS currently applied by the adaptive controller, so a slow client
+// simply receives fewer frames and capture goroutines never wait on the
+// network. Each new frame is JPEG-encoded once and shared by all clients.
+package server
+
+import (
+	"bytes"
+	"camera-dashboard-go/internal/camera"
+	"context"
+	"fmt"
+	"html/template"
+	"image/jpeg"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for zero Config values
+const (
+	DefaultAddr        = "127.0.0.1:8080"
+	DefaultJPEGQuality = 80
+	DefaultMaxClients  = 8
+)
+
+const (
+	streamBoundary = "frame"
+
+	// noFramePoll is how often a stream re-checks a camera that has no
+	// frame buffer or no frame yet (unplugged, starting, hotplug re-init).
+	noFramePoll = 250 * time.Millisecond
+
+	// streamWriteTimeout drops a client that cannot take one frame in this
+	// time (phone asleep, Wi-Fi out of range) so it frees its stream slot.
+	streamWriteTimeout = 10 * time.Second
+)
+
+// Source gives the server access to the cameras. It is queried on every
+// request and frame, so the server follows Manager re-creation on hotplug.
+type Source interface {
+	Cameras() []camera.Camera                        // Cameras by slot
+	FrameBuffer(deviceID string) *camera.FrameBuffer // nil if not capturing
+	FPS() int                                        // Capture FPS currently applied (<= 0 = unknown)
+}
+
+// Config controls the HTTP server.
+type Config struct {
+	Addr        string // Listen address, e.g. "127.0.0.1:8080" or ":8080" (all interfaces)
+	JPEGQuality int    // Quality of streamed JPEGs
+	MaxClients  int    // Concurrent MJPEG streams; more are refused with 503
+	MaxFPS      int    // Cap on stream FPS below the capture FPS (0 = follow capture)
+	APIToken    string // Required as "Authorization: Bearer <token>" by /api/ ("" = none)
+}
+
+// Server serves camera streams over HTTP.
+type Server struct {
+	cfg  Config
+	src  Source
+	mux  *http.ServeMux
+	http *http.Server
+
+	streams atomic.Int32
+
+	cacheMu sync.Mutex
+	cache   map[string]*jpegCache // By camera device ID
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// jpegCache holds the last encoded frame of one camera, shared by all clients.
+type jpegCache struct {
+	mu    sync.Mutex
+	fb    *camera.FrameBuffer
+	count uint64 // FrameBuffer frame count the data was encoded from
+	data  []byte
+}
+
+// connKey stores the client's net.Conn in the request context so streams can
+// set per-frame write deadlines.
+type connKey struct{}
+
+// New creates a server for src. Call Start to begin listening.
+func New(cfg Config, src Source) *Server {
+	if cfg.Addr == "" {
+		cfg.Addr = DefaultAddr
+	}
+	if cfg.JPEGQuality <= 0 || cfg.JPEGQuality > 100 {
+		cfg.JPEGQuality = DefaultJPEGQuality
+	}
+	if cfg.MaxClients <= 0 {
+		cfg.MaxClients = DefaultMaxClients
+	}
+
+	s := &Server{
+		cfg:   cfg,
+		src:   src,
+		mux:   http.NewServeMux(),
+		cache: make(map[string]*jpegCache),
+		done:  make(chan struct{}),
+	}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/stream/", s.handleStream)
+	s.mux.HandleFunc("/jpeg/", s.handleJPEG)
+
+	s.http = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, connKey{}, c)
+		},
+	}
+	return s
+}
+
+// Handle registers an additional handler, e.g. an API or metrics endpoint.
+// Must be called before Start.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the server's request handler.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.cfg.Addr, err)
+	}
+	log.Printf("[HTTP] Serving camera streams on http://%s/", ln.Addr())
+	go func() {
+		if err := s.http.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("[HTTP] Server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Close stops the server and ends all streams.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.http.Close()
+	})
+}
+
+// ActiveStreams returns the number of connected MJPEG clients.
+func (s *Server) ActiveStreams() int {
+	return int(s.streams.Load())
+}
+
+// =============================================================================
+// Handlers
+// =============================================================================
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Camera Dashboard</title>
+<style>
+body { margin: 0; background: #141414; color: #eee; font-family: sans-serif; }
+.grid { display: grid; grid-template-columns: repeat(auto-fit, minmax(320px, 1fr)); gap: 4px; }
+figure { margin: 0; position: relative; }
+img { width: 100%; display: block; background: #000; }
+figcaption { position: absolute; top: 4px; left: 4px; padding: 2px 6px; background: rgba(0,0,0,.6); font-weight: bold; }
+</style></head><body><div class="grid">
+{{range .}}<figure><a href="/jpeg/{{.Slot}}"><img src="/stream/{{.Slot}}" alt="{{.Label}}"></a><figcaption>{{.Label}}</figcaption></figure>
+{{else}}<p>No cameras connected.</p>
+{{end}}</div></body></html>
+`))
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	type entry struct {
+		Slot  int
+		Label string
+	}
+	var entries []entry
+	for i, cam := range s.src.Cameras() {
+		if cam.Available {
+			entries = append(entries, entry{Slot: i, Label: cam.Label(i)})
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, entries); err != nil {
+		log.Printf("[HTTP] Index page: %v", err)
+	}
+}
+
+// handleJPEG serves the latest frame of /jpeg/<camera> as a single JPEG.
+func (s *Server) handleJPEG(w http.ResponseWriter, r *http.Request) {
+	cam, fb, ok := s.resolve(strings.TrimPrefix(r.URL.Path, "/jpeg/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var data []byte
+	if fb != nil {
+		data, _ = s.latestJPEG(cam.DeviceID, fb)
+	}
+	if data == nil {
+		http.Error(w, "no frame available", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+// handleStream serves /stream/<camera> as multipart/x-mixed-replace MJPEG
+// until the client disconnects or the server is closed.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/stream/")
+	if _, _, ok := s.resolve(name); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if int(s.streams.Add(1)) > s.cfg.MaxClients {
+		s.streams.Add(-1)
+		http.Error(w, "too many streams", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.streams.Add(-1)
+
+	conn, _ := r.Context().Value(connKey{}).(net.Conn)
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+streamBoundary)
+	w.Header().Set("Cache-Control", "no-store")
+
+	var lastCount uint64
+	for {
+		wait := noFramePoll
+		// Re-resolve every frame: the camera's buffer changes on hotplug
+		if cam, fb, ok := s.resolve(name); ok && fb != nil {
+			data, count := s.latestJPEG(cam.DeviceID, fb)
+			if data != nil && count != lastCount {
+				if conn != nil {
+					conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				}
+				if err := writePart(w, data); err != nil {
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+				lastCount = count
+			}
+			if data != nil {
+				wait = s.frameInterval()
+			}
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// writePart writes one JPEG as a multipart section.
+func writePart(w http.ResponseWriter, data []byte) error {
+	if _, err := fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n",
+		streamBoundary, len(data)); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte("\r\n"))
+	return err
+}
+
+// =============================================================================
+// Frames
+// =============================================================================
+
+// resolve finds a camera by slot number, role, name or device ID
+// (case-insensitive). fb is nil when the camera is not capturing.
+func (s *Server) resolve(name string) (camera.Camera, *camera.FrameBuffer, bool) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return camera.Camera{}, nil, false
+	}
+	cams := s.src.Cameras()
+	idx := -1
+	if n, err := strconv.Atoi(name); err == nil {
+		if n >= 0 && n < len(cams) {
+			idx = n
+		}
+	} else {
+		for i, cam := range cams {
+			if strings.EqualFold(cam.Role, name) || strings.EqualFold(cam.Name, name) ||
+				strings.EqualFold(cam.DeviceID, name) {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		return camera.Camera{}, nil, false
+	}
+	cam := cams[idx]
+	if !cam.Available {
+		return cam, nil, true
+	}
+	return cam, s.src.FrameBuffer(cam.DeviceID), true
+}
+
+// latestJPEG returns the camera's newest frame as JPEG and the FrameBuffer
+// frame count it was taken at. The frame is encoded once however many
+// clients ask for it. Returns nil data while the buffer has no frame.
+func (s *Server) latestJPEG(deviceID string, fb *camera.FrameBuffer) ([]byte, uint64) {
+	s.cacheMu.Lock()
+	c := s.cache[deviceID]
+	if c == nil || c.fb != fb {
+		c = &jpegCache{fb: fb}
+		s.cache[deviceID] = c
+	}
+	s.cacheMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := fb.GetFrameCount()
+	if c.data != nil && count == c.count {
+		return c.data, c.count
+	}
+	img := fb.Read()
+	if img == nil {
+		return c.data, c.count
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: s.cfg.JPEGQuality}); err != nil {
+		log.Printf("[HTTP] JPEG encode for %s failed: %v", deviceID, err)
+		return c.data, c.count
+	}
+	c.data, c.count = buf.Bytes(), count
+	return c.data, c.count
+}
+
+// frameInterval is the delay between stream frames: the capture FPS applied
+// by the adaptive controller, capped by MaxFPS.
+func (s *Server) frameInterval() time.Duration {
+	fps := s.src.FPS()
+	if s.cfg.MaxFPS > 0 && (fps <= 0 || fps > s.cfg.MaxFPS) {
+		fps = s.cfg.MaxFPS
+	}
+	if fps <= 0 {
+		fps = camera.DefaultFPS
+	}
+	return time.Second / time.Duration(fps)
+}
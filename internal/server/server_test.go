@@ -0,0 +1,175 @@
+package server
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSource serves fixed cameras and frame buffers.
+type fakeSource struct {
+	mu   sync.Mutex
+	cams []camera.Camera
+	fbs  map[string]*camera.FrameBuffer
+	fps  int
+}
+
+func (f *fakeSource) Cameras() []camera.Camera {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]camera.Camera(nil), f.cams...)
+}
+
+func (f *fakeSource) FrameBuffer(deviceID string) *camera.FrameBuffer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fbs[deviceID]
+}
+
+func (f *fakeSource) FPS() int { return f.fps }
+
+func newTestSource() *fakeSource {
+	return &fakeSource{
+		cams: []camera.Camera{
+			{DeviceID: "cam0", Role: "Rear", Available: true},
+			{DeviceID: "cam1", Available: true},
+			{Name: "usb-1.4", Available: false},
+		},
+		fbs: map[string]*camera.FrameBuffer{
+			"cam0": camera.NewFrameBuffer(),
+			"cam1": camera.NewFrameBuffer(),
+		},
+		fps: 100,
+	}
+}
+
+func testImage(c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestServer_JPEGEndpoint(t *testing.T) {
+	src := newTestSource()
+	src.fbs["cam0"].Write(testImage(color.White))
+	ts := httptest.NewServer(New(Config{}, src).Handler())
+	defer ts.Close()
+
+	for _, path := range []string{"/jpeg/0", "/jpeg/rear"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := jpeg.Decode(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || err != nil {
+			t.Fatalf("GET %s = %d, decode err %v", path, resp.StatusCode, err)
+		}
+		if img.Bounds().Dx() != 16 {
+			t.Errorf("GET %s width = %d, want 16", path, img.Bounds().Dx())
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/jpeg/1":       http.StatusServiceUnavailable, // No frame yet
+		"/jpeg/usb-1.4": http.StatusServiceUnavailable, // Pinned but not connected
+		"/jpeg/9":       http.StatusNotFound,
+		"/jpeg/front":   http.StatusNotFound,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestServer_MJPEGStream(t *testing.T) {
+	src := newTestSource()
+	fb := src.fbs["cam0"]
+	fb.Write(testImage(color.White))
+	srv := New(Config{}, src)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/stream/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/x-mixed-replace") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	mr := multipart.NewReader(resp.Body, streamBoundary)
+	for i := 0; i < 2; i++ {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if _, err := jpeg.Decode(part); err != nil {
+			t.Fatalf("part %d is not a JPEG: %v", i, err)
+		}
+		fb.Write(testImage(color.Black)) // New frame for the next part
+	}
+	if srv.ActiveStreams() != 1 {
+		t.Errorf("ActiveStreams = %d, want 1", srv.ActiveStreams())
+	}
+	resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.ActiveStreams() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stream not released after client disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_MaxClients(t *testing.T) {
+	src := newTestSource()
+	src.fbs["cam0"].Write(testImage(color.White))
+	srv := New(Config{MaxClients: 1}, src)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	defer srv.Close() // Ends the open stream before ts.Close waits for it
+
+	first, err := http.Get(ts.URL + "/stream/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Body.Close()
+
+	second, err := http.Get(ts.URL + "/stream/rear")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("second stream = %d, want 503", second.StatusCode)
+	}
+}
+
+func TestServer_FrameIntervalFollowsFPS(t *testing.T) {
+	src := newTestSource()
+	src.fps = 10
+	if got := New(Config{}, src).frameInterval(); got != 100*time.Millisecond {
+		t.Errorf("interval at 10 FPS = %v, want 100ms", got)
+	}
+	if got := New(Config{MaxFPS: 5}, src).frameInterval(); got != 200*time.Millisecond {
+		t.Errorf("interval capped at 5 FPS = %v, want 200ms", got)
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"camera-dashboard-go/internal/events"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sseHeartbeat keeps idle event streams open through proxies and lets the
+// server notice clients that went away.
+const sseHeartbeat = 15 * time.Second
+
+// EnableEvents serves bus as Server-Sent Events at /events. Each event is
+// sent as "event: <type>" with the JSON-encoded events.Event as data;
+// ?type=a,b limits the stream to those types. Must be called before Start.
+func (s *Server) EnableEvents(bus *events.Bus) {
+	s.mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodGet) {
+			return
+		}
+		s.serveEvents(w, r, bus)
+	})
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request, bus *events.Bus) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	var only map[events.Type]bool
+	if types := r.URL.Query().Get("type"); types != "" {
+		only = make(map[events.Type]bool)
+		for _, t := range strings.Split(types, ",") {
+			only[events.Type(strings.TrimSpace(t))] = true
+		}
+	}
+
+	sub := bus.Subscribe(0)
+	defer sub.Close()
+
+	conn, _ := r.Context().Value(connKey{}).(net.Conn)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if only != nil && !only[ev.Type] {
+				continue
+			}
+			if conn != nil {
+				conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			}
+			err = writeEvent(w, ev)
+		case <-heartbeat.C:
+			if conn != nil {
+				conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			}
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one SSE message.
+func writeEvent(w http.ResponseWriter, ev events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
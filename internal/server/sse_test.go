@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bufio"
+	"camera-dashboard-go/internal/events"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_EventStream(t *testing.T) {
+	bus := events.NewBus()
+	srv := New(Config{}, newTestSource())
+	srv.EnableEvents(bus)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	defer srv.Close()
+
+	resp, err := http.Get(ts.URL + "/events?type=camera_disconnected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	lines := bufio.NewReader(resp.Body)
+	if l, _ := lines.ReadString('\n'); !strings.HasPrefix(l, ": connected") {
+		t.Fatalf("first line = %q", l)
+	}
+
+	// The handler subscribes before the greeting, so these are delivered;
+	// the filter drops the FPS change.
+	bus.Publish(events.Event{Type: events.FPSChange})
+	bus.Publish(events.Camera(events.CameraDisconnected, 1, "Rear", "no frames"))
+
+	got := make(chan map[string]string, 1)
+	go func() {
+		fields := map[string]string{}
+		for {
+			l, err := lines.ReadString('\n')
+			if err != nil {
+				return
+			}
+			l = strings.TrimRight(l, "\n")
+			if l == "" && len(fields) > 0 {
+				got <- fields
+				return
+			}
+			if k, v, ok := strings.Cut(l, ": "); ok && !strings.HasPrefix(l, ":") {
+				fields[k] = v
+			}
+		}
+	}()
+
+	select {
+	case f := <-got:
+		if f["event"] != "camera_disconnected" || f["id"] != "2" {
+			t.Errorf("event fields = %v, want camera_disconnected with id 2", f)
+		}
+		var ev events.Event
+		if err := json.Unmarshal([]byte(f["data"]), &ev); err != nil || ev.Camera != "Rear" || *ev.Slot != 1 {
+			t.Errorf("event data = %s (%v)", f["data"], err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+	}
+}
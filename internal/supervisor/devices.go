@@ -0,0 +1,79 @@
+package supervisor
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/helpers"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Devices probes camera device nodes for the hotplug scanner.
+type Devices interface {
+	// Exists reports whether the device node exists.
+	Exists(devPath string) bool
+	// IsNewCamera reports whether devPath is a USB capture device that is
+	// not a secondary node of one of the tracked devices.
+	IsNewCamera(devPath string, tracked map[string]bool) bool
+	// KillHolders terminates processes holding devPath when enabled.
+	KillHolders(devPath string, enabled bool)
+}
+
+// sysfsDevices probes /dev and sysfs.
+type sysfsDevices struct{}
+
+func (sysfsDevices) Exists(devPath string) bool {
+	_, err := os.Stat(devPath)
+	return err == nil
+}
+
+func (sysfsDevices) KillHolders(devPath string, enabled bool) {
+	helpers.KillDeviceHolders(devPath, enabled)
+}
+
+// IsNewCamera checks if a device path is a USB video capture device
+// that is NOT a secondary node of an already-tracked camera.
+// Uses sysfs instead of v4l2-ctl to avoid conflicts with active FFmpeg capture.
+func (sysfsDevices) IsNewCamera(devPath string, existingPaths map[string]bool) bool {
+	// Extract video number from path (e.g., /dev/video0 -> 0)
+	var videoNum int
+	_, err := fmt.Sscanf(devPath, "/dev/video%d", &videoNum)
+	if err != nil {
+		return false
+	}
+
+	// Check sysfs for device type - USB capture devices have specific characteristics
+	// USB cameras typically create even-numbered video devices (video0, video2, video4)
+	// Odd numbers are usually metadata devices
+	if videoNum%2 != 0 {
+		return false // Skip odd-numbered devices (metadata)
+	}
+
+	// Check if it's a capture device by looking at sysfs
+	sysfsPath := fmt.Sprintf("/sys/class/video4linux/video%d/device/modalias", videoNum)
+	data, err := os.ReadFile(sysfsPath)
+	if err != nil {
+		return false
+	}
+
+	// USB devices have modalias starting with "usb:"
+	if !strings.HasPrefix(string(data), "usb:") {
+		return false
+	}
+
+	// Reject secondary nodes that share a USB parent with an already-tracked camera.
+	// Multi-function USB cameras (e.g. UVC webcams) register multiple /dev/videoX nodes
+	// under the same physical USB device. Only the primary capture node (typically the
+	// lowest-numbered) should be treated as a camera.
+	candidateParent := camera.USBParent(devPath)
+	if candidateParent == "" {
+		return false
+	}
+	for existingPath := range existingPaths {
+		if camera.USBParent(existingPath) == candidateParent {
+			return false // Same physical device as an already-tracked camera
+		}
+	}
+
+	return true
+}
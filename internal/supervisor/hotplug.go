@@ -0,0 +1,257 @@
+package supervisor
+
+import (
+	"camera-dashboard-go/internal/events"
+	"camera-dashboard-go/internal/helpers"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Device settle delays after a hotplug before capture is (re)started
+const (
+	deviceSettleDelay = 1500 * time.Millisecond
+)
+
+// runHotplugDetection polls for camera connect/disconnect until Stop
+func (s *Supervisor) runHotplugDetection() {
+	log.Println("[Hotplug] Starting camera hot-plug detection...")
+
+	interval := time.Duration(s.cfg.RescanIntervalMS) * time.Millisecond
+	if interval < 500*time.Millisecond {
+		interval = 500 * time.Millisecond
+	}
+	ticker := s.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			log.Println("[Hotplug] Stopping hot-plug detection")
+			return
+		case <-ticker.C():
+			s.checkCameraChanges()
+		}
+	}
+}
+
+// checkCameraChanges polls for camera connect/disconnect events
+func (s *Supervisor) checkCameraChanges() {
+	// Simple check: just verify device files exist (don't use v4l2-ctl to avoid conflicts with FFmpeg)
+	// Check for disconnections in our existing cameras
+	cameras := s.Cameras()
+	s.stateLock.RLock()
+	statusSnapshot := append([]bool(nil), s.cameraStatus...)
+	s.stateLock.RUnlock()
+
+	limit := helpers.MinInt(len(cameras), len(statusSnapshot))
+	for i := 0; i < limit; i++ {
+		cam := cameras[i]
+
+		// Check if device file still exists
+		deviceExists := s.devices.Exists(cam.DevicePath)
+		wasConnected := statusSnapshot[i]
+
+		if wasConnected && !deviceExists {
+			// Camera disconnected - record time for debouncing
+			s.reinitLock.Lock()
+			s.lastDisconnectTime[i] = s.clock.Now()
+			s.reinitLock.Unlock()
+			log.Printf("[Hotplug] %s (%s) disconnected", cam.Label(i), cam.DevicePath)
+			s.updateCameraStatus(i, false)
+		} else if !wasConnected && deviceExists {
+			// Camera reconnected
+			log.Printf("[Hotplug] %s (%s) reconnected", cam.Label(i), cam.DevicePath)
+			s.handleCameraReconnect(i)
+		}
+	}
+
+	// Check for new cameras at common device paths
+	s.checkForNewCameras()
+}
+
+// checkForNewCameras looks for new cameras dynamically
+func (s *Supervisor) checkForNewCameras() {
+	// Skip if reinit is already in progress
+	s.reinitLock.Lock()
+	if s.reinitInProgress {
+		s.reinitLock.Unlock()
+		return
+	}
+	s.reinitLock.Unlock()
+
+	// Only check if we have empty slots.
+	connectedCount := 0
+	existingPaths := make(map[string]bool)
+	s.stateLock.RLock()
+	for i := 0; i < s.slots; i++ {
+		if i < len(s.cameras) && s.cameraStatus[i] {
+			connectedCount++
+		}
+	}
+	// Build set of existing device paths we're already tracking
+	for _, cam := range s.cameras {
+		existingPaths[cam.DevicePath] = true
+	}
+	s.stateLock.RUnlock()
+	if connectedCount >= s.slots {
+		return // All slots full
+	}
+
+	cooldown := time.Duration(s.cfg.FailedCameraCooldownS * float64(time.Second))
+	if cooldown < time.Second {
+		cooldown = time.Second
+	}
+	now := s.clock.Now()
+	maxScan := helpers.MaxInt(10, s.slots*4+4)
+
+	// Scan /dev/video* for potential new USB cameras.
+	for i := 0; i <= maxScan; i += 2 {
+		devPath := fmt.Sprintf("/dev/video%d", i)
+
+		if existingPaths[devPath] {
+			continue // Already tracking this device
+		}
+		if last, ok := s.failedNewDevice[devPath]; ok && now.Sub(last) < cooldown {
+			continue
+		}
+
+		// Verify it's a USB camera by checking if it's a capture device
+		if s.devices.Exists(devPath) && s.devices.IsNewCamera(devPath, existingPaths) {
+			log.Printf("[Hotplug] New USB camera detected at %s", devPath)
+			s.failedNewDevice[devPath] = now
+			s.handleNewCameraDevice(devPath)
+			return // Only handle one at a time
+		}
+	}
+}
+
+// handleNewCameraDevice handles a newly detected camera device
+func (s *Supervisor) handleNewCameraDevice(devPath string) {
+	s.reinitLock.Lock()
+	if s.reinitInProgress {
+		s.reinitLock.Unlock()
+		log.Printf("[Hotplug] Reinit already in progress, skipping new camera %s", devPath)
+		return
+	}
+	s.reinitInProgress = true
+	s.reinitLock.Unlock()
+
+	// Find an empty/disconnected slot
+	emptySlot := -1
+	s.stateLock.RLock()
+	for i := 0; i < s.slots; i++ {
+		if i >= len(s.cameras) || !s.cameraStatus[i] {
+			emptySlot = i
+			break
+		}
+	}
+	s.stateLock.RUnlock()
+
+	if emptySlot < 0 {
+		log.Printf("[Hotplug] New camera detected (%s) but no empty slots available", devPath)
+		s.reinitLock.Lock()
+		s.reinitInProgress = false
+		s.reinitLock.Unlock()
+		return
+	}
+
+	log.Printf("[Hotplug] Assigning new camera (%s) to slot %d", devPath, emptySlot)
+
+	s.actions.Add(1)
+	go func() {
+		defer s.actions.Done()
+		defer func() {
+			s.reinitLock.Lock()
+			s.reinitInProgress = false
+			s.reinitLock.Unlock()
+		}()
+
+		// Let device settle
+		s.clock.Sleep(deviceSettleDelay)
+
+		cams, err := s.manager.Reinitialize()
+		if err != nil {
+			log.Printf("[Hotplug] Failed to reinitialize manager: %v", err)
+			return
+		}
+		log.Printf("[Hotplug] Reinitialized with %d cameras", len(cams))
+
+		s.SetCameras(cams)
+		addedSlot := emptySlot
+		for i, cam := range cams {
+			if i < s.slots && cam.Available && cam.DevicePath == devPath {
+				addedSlot = i
+			}
+		}
+		s.events.Publish(events.Camera(events.CameraAdded, addedSlot, s.CameraLabel(addedSlot), devPath))
+	}()
+}
+
+// reconnectDebounce is how long a camera must have been gone before a
+// reappearing device node is treated as a reconnect.
+func (s *Supervisor) reconnectDebounce() time.Duration {
+	debounce := defaultReconnectDebounce
+	if cfgDelay := time.Duration(s.cfg.FailedCameraCooldownS * float64(time.Second)); cfgDelay > 0 && cfgDelay < debounce {
+		debounce = cfgDelay
+	}
+	return debounce
+}
+
+// handleCameraReconnect handles a camera that was disconnected and is now reconnected
+// Uses per-camera restart to avoid disrupting other cameras
+func (s *Supervisor) handleCameraReconnect(camIndex int) {
+	// Debounce reconnect checks to avoid flapping on unstable USB links.
+	debounce := s.reconnectDebounce()
+
+	s.reinitLock.Lock()
+	if camIndex < 0 || camIndex >= len(s.lastDisconnectTime) {
+		s.reinitLock.Unlock()
+		return
+	}
+	timeSinceDisconnect := s.clock.Now().Sub(s.lastDisconnectTime[camIndex])
+	if timeSinceDisconnect < debounce {
+		s.reinitLock.Unlock()
+		log.Printf("[Hotplug] %s: Ignoring reconnect (%.1fs since disconnect, need %.1fs debounce)",
+			s.CameraLabel(camIndex), timeSinceDisconnect.Seconds(), debounce.Seconds())
+		return
+	}
+
+	if s.reinitInProgress {
+		s.reinitLock.Unlock()
+		log.Printf("[Hotplug] Reinit already in progress, skipping reconnect for %s", s.CameraLabel(camIndex))
+		return
+	}
+	s.reinitInProgress = true
+	s.reinitLock.Unlock()
+
+	log.Printf("[Hotplug] %s: Attempting per-camera restart (other cameras unaffected)...", s.CameraLabel(camIndex))
+
+	s.actions.Add(1)
+	go func() {
+		defer s.actions.Done()
+		defer func() {
+			s.reinitLock.Lock()
+			s.reinitInProgress = false
+			s.reinitLock.Unlock()
+		}()
+
+		// Let the device settle after reconnection
+		s.clock.Sleep(deviceSettleDelay)
+
+		// Kill any stale processes holding the device before restart
+		if cam, ok := s.Camera(camIndex); ok && cam.DevicePath != "" {
+			s.devices.KillHolders(cam.DevicePath, s.cfg.KillDeviceHolders)
+		}
+
+		// Restart only this camera's worker
+		if err := s.manager.RestartCamera(camIndex); err != nil {
+			log.Printf("[Hotplug] %s: Failed to restart: %v", s.CameraLabel(camIndex), err)
+			return
+		}
+
+		// Mark camera as connected
+		s.updateCameraStatus(camIndex, true)
+		log.Printf("[Hotplug] %s: Successfully restarted", s.CameraLabel(camIndex))
+	}()
+}
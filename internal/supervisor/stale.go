@@ -0,0 +1,314 @@
+package supervisor
+
+import (
+	"camera-dashboard-go/internal/events"
+	"camera-dashboard-go/internal/helpers"
+	"fmt"
+	"log"
+	"time"
+)
+
+// =============================================================================
+// Health Logging
+// =============================================================================
+// Periodic summary of camera health: online, stale, and disconnected counts.
+// Matches Python's log_health_summary() from utils/helpers.py.
+// =============================================================================
+
+// Camera health states, as counted by the health summary and /metrics
+const (
+	HealthOnline       = "online"
+	HealthStale        = "stale"
+	HealthDisconnected = "disconnected"
+)
+
+// runHealthLogging periodically logs camera health status.
+// Disabled when HealthLogIntervalSec <= 0.
+func (s *Supervisor) runHealthLogging() {
+	interval := s.cfg.HealthLogIntervalSec
+	if interval <= 0 {
+		log.Println("[Health] Health logging disabled (interval <= 0)")
+		return
+	}
+
+	log.Printf("[Health] Starting health logging (every %.0fs)...", interval)
+
+	ticker := s.clock.NewTicker(time.Duration(interval * float64(time.Second)))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C():
+			s.logHealthSummary()
+		}
+	}
+}
+
+// logHealthSummary logs the current health status of all camera slots.
+// Counts cameras as online (fresh frame), stale (frame older than threshold),
+// or disconnected (not connected).
+func (s *Supervisor) logHealthSummary() {
+	s.stateLock.RLock()
+	initialized := s.initialized
+	s.stateLock.RUnlock()
+	if !initialized {
+		return
+	}
+
+	now := s.clock.Now()
+	online := 0
+	stale := 0
+	disconnected := 0
+	totalSlots := s.cfg.CameraSlotCount
+
+	limit := helpers.MinInt(totalSlots, s.slots)
+	for camIndex := 0; camIndex < limit; camIndex++ {
+		status, age := s.CameraHealth(camIndex, now)
+		switch status {
+		case HealthDisconnected:
+			disconnected++
+		case HealthStale:
+			stale++
+			if age < 0 {
+				log.Printf("[Health] WARNING: %s has never produced a frame", s.CameraLabel(camIndex))
+			} else {
+				log.Printf("[Health] WARNING: %s frame is stale (%.1fs old)", s.CameraLabel(camIndex), age.Seconds())
+			}
+		default:
+			online++
+		}
+	}
+
+	log.Printf("[Health] cameras online=%d stale=%d disconnected=%d total_slots=%d",
+		online, stale, disconnected, totalSlots)
+}
+
+// CameraHealth classifies a camera slot. A connected camera whose last frame
+// is older than StaleFrameTimeoutSec, or that never produced one, is stale.
+// age is the time since the last frame, or -1 if there was none. A slot out
+// of range is disconnected.
+func (s *Supervisor) CameraHealth(camIndex int, now time.Time) (status string, age time.Duration) {
+	if camIndex < 0 || camIndex >= s.slots {
+		return HealthDisconnected, -1
+	}
+	s.stateLock.RLock()
+	connected := s.cameraStatus[camIndex]
+	lastFrame := s.lastFrameTime[camIndex]
+	s.stateLock.RUnlock()
+
+	age = -1
+	if !lastFrame.IsZero() {
+		age = now.Sub(lastFrame)
+	}
+	switch {
+	case !connected:
+		return HealthDisconnected, age
+	case age < 0 || age.Seconds() > s.cfg.StaleFrameTimeoutSec: // H7: use config instead of hardcoded 10.0
+		return HealthStale, age
+	}
+	return HealthOnline, age
+}
+
+// =============================================================================
+// Stale Frame Detection + Bounded Auto-Restart
+// =============================================================================
+// Matches Python's _restart_capture_if_stale() policy:
+//   - STALE_FRAME_TIMEOUT_SEC: time before a frame is considered stale
+//   - RESTART_COOLDOWN_SEC: minimum time between restarts for one camera
+//   - MAX_RESTARTS_PER_WINDOW: max restarts allowed in RESTART_WINDOW_SEC
+//   - Extended cooldown (2x window) when limit is reached
+// =============================================================================
+
+// runStaleFrameDetection periodically checks for cameras that have stopped
+// producing frames and restarts their capture workers.
+func (s *Supervisor) runStaleFrameDetection() {
+	log.Println("[Stale] Starting stale frame detection...")
+
+	ticker := s.clock.NewTicker(staleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C():
+			s.trackFrames()
+			s.checkStaleFrames()
+		}
+	}
+}
+
+// trackFrames records when each camera last produced a frame, as reported
+// by the manager. It runs without a display, so staleness does not depend
+// on a UI reading the frames.
+func (s *Supervisor) trackFrames() {
+	s.stateLock.RLock()
+	limit := helpers.MinInt(s.slots, len(s.cameras))
+	s.stateLock.RUnlock()
+	for camIndex := 0; camIndex < limit; camIndex++ {
+		t := s.manager.LastFrameTime(camIndex)
+		s.stateLock.Lock()
+		if t.After(s.lastFrameTime[camIndex]) {
+			s.lastFrameTime[camIndex] = t
+		}
+		s.stateLock.Unlock()
+	}
+}
+
+// checkStaleFrames checks each connected camera for stale frames
+func (s *Supervisor) checkStaleFrames() {
+	s.stateLock.RLock()
+	camCount := len(s.cameras)
+	s.stateLock.RUnlock()
+
+	if camCount == 0 {
+		return
+	}
+
+	now := s.clock.Now()
+	staleTimeout := time.Duration(s.cfg.StaleFrameTimeoutSec * float64(time.Second))
+
+	limit := helpers.MinInt(s.slots, camCount)
+	for camIndex := 0; camIndex < limit; camIndex++ {
+		s.stateLock.RLock()
+		connected := s.cameraStatus[camIndex]
+		lastFrame := s.lastFrameTime[camIndex]
+		s.stateLock.RUnlock()
+
+		if !connected {
+			continue // Skip disconnected cameras
+		}
+
+		// Skip if we haven't received any frames yet (still initializing)
+		if lastFrame.IsZero() {
+			continue
+		}
+
+		// Check if frame is stale
+		staleDuration := now.Sub(lastFrame)
+		if staleDuration <= staleTimeout {
+			continue // Frame is fresh
+		}
+
+		log.Printf("[Stale] %s: stale frame detected (no frames for %.1fs)",
+			s.CameraLabel(camIndex), staleDuration.Seconds())
+
+		// Mark as disconnected
+		s.updateCameraStatus(camIndex, false)
+
+		// Attempt bounded auto-restart
+		s.restartCaptureIfStale(camIndex)
+	}
+}
+
+// restartCaptureIfStale implements the bounded restart policy matching Python's
+// _restart_capture_if_stale(). Enforces:
+//   - Cooldown between restarts (RESTART_COOLDOWN_SEC)
+//   - Sliding window restart limit (MAX_RESTARTS_PER_WINDOW in RESTART_WINDOW_SEC)
+//   - Extended cooldown (2x window) when limit is reached
+func (s *Supervisor) restartCaptureIfStale(camIndex int) {
+	if camIndex < 0 || camIndex >= s.slots {
+		return
+	}
+	now := s.clock.Now()
+	cooldown := time.Duration(s.cfg.RestartCooldownSec * float64(time.Second))
+	window := time.Duration(s.cfg.RestartWindowSec * float64(time.Second))
+	extendedCooldown := window * 2
+
+	// Check cooldown
+	if !s.lastRestartTime[camIndex].IsZero() && now.Sub(s.lastRestartTime[camIndex]) < cooldown {
+		return // Still in cooldown
+	}
+
+	// Count recent restarts in the sliding window
+	recentCount := 0
+	for _, t := range s.restartEvents[camIndex] {
+		if now.Sub(t) <= window {
+			recentCount++
+		}
+	}
+
+	if recentCount >= s.cfg.MaxRestartsPerWindow {
+		// Restart limit reached - check extended cooldown
+		if !s.lastRestartTime[camIndex].IsZero() && now.Sub(s.lastRestartTime[camIndex]) < extendedCooldown {
+			if !s.restartLimitHit[camIndex] {
+				log.Printf("[Stale] %s: restart limit reached (%d/%d in %.0fs), will retry in %.0fs",
+					s.CameraLabel(camIndex), recentCount, s.cfg.MaxRestartsPerWindow,
+					s.cfg.RestartWindowSec, extendedCooldown.Seconds())
+				s.restartLimitHit[camIndex] = true
+				s.events.Publish(events.Camera(events.CameraRestartLimit, camIndex, s.CameraLabel(camIndex),
+					fmt.Sprintf("%d restarts in %.0fs, retrying in %.0fs", recentCount, s.cfg.RestartWindowSec, extendedCooldown.Seconds())))
+			}
+			return
+		}
+
+		// Extended cooldown passed - clear events and allow restart
+		log.Printf("[Stale] %s: extended cooldown passed, attempting recovery", s.CameraLabel(camIndex))
+		s.restartMu.Lock()
+		s.restartEvents[camIndex] = nil
+		s.restartMu.Unlock()
+		s.restartLimitHit[camIndex] = false
+	}
+
+	// Record this restart event and clean up old events outside the window
+	s.lastRestartTime[camIndex] = now
+	var filtered []time.Time
+	for _, t := range append(s.restartEvents[camIndex], now) {
+		if now.Sub(t) <= window*2 { // Keep slightly more history
+			filtered = append(filtered, t)
+		}
+	}
+	s.restartMu.Lock()
+	s.restartEvents[camIndex] = filtered
+	s.restartTotal[camIndex]++
+	s.restartMu.Unlock()
+
+	log.Printf("[Stale] %s: restarting capture worker after stale frames", s.CameraLabel(camIndex))
+	s.events.Publish(events.Camera(events.CameraRestart, camIndex, s.CameraLabel(camIndex), "stale frames"))
+
+	s.actions.Add(1)
+	go func(idx int) {
+		defer s.actions.Done()
+
+		// Kill any processes holding this camera device before restart
+		if cam, ok := s.Camera(idx); ok && cam.DevicePath != "" {
+			s.devices.KillHolders(cam.DevicePath, s.cfg.KillDeviceHolders)
+		}
+
+		if err := s.manager.RestartCamera(idx); err != nil {
+			log.Printf("[Stale] %s: failed to restart: %v", s.CameraLabel(idx), err)
+			return
+		}
+
+		// Reset frame time so we don't immediately re-trigger
+		s.stateLock.Lock()
+		s.lastFrameTime[idx] = s.clock.Now()
+		s.stateLock.Unlock()
+
+		// Mark as connected again
+		s.updateCameraStatus(idx, true)
+		log.Printf("[Stale] %s: successfully restarted", s.CameraLabel(idx))
+	}(camIndex)
+}
+
+// RestartCounts returns, per slot, the capture restarts within the restart
+// window and since startup.
+func (s *Supervisor) RestartCounts() (recent []int, totals []uint64) {
+	now := s.clock.Now()
+	window := time.Duration(s.cfg.RestartWindowSec * float64(time.Second))
+	s.restartMu.Lock()
+	defer s.restartMu.Unlock()
+	recent = make([]int, s.slots)
+	totals = make([]uint64, s.slots)
+	for i := 0; i < s.slots; i++ {
+		for _, t := range s.restartEvents[i] {
+			if now.Sub(t) <= window {
+				recent[i]++
+			}
+		}
+		totals[i] = s.restartTotal[i]
+	}
+	return recent, totals
+}
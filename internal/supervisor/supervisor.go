@@ -0,0 +1,232 @@
+// Package supervisor keeps the cameras of a dashboard running: it detects
+// stale frames and restarts capture under a bounded restart policy, scans
+// for hotplugged and reconnected USB cameras (with reconnect debouncing),
+// and logs periodic health summaries.
+//
+// A Supervisor owns the per-slot camera state (camera list, connected
+// status, last frame time, restart history) and reports changes through
+// status callbacks and the event bus. The camera manager, the clock and the
+// device probes are interfaces, so the policies can be tested with fakes.
+package supervisor
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/events"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultReconnectDebounce = 3 * time.Second
+
+// staleCheckInterval is how often frames are checked for staleness.
+const staleCheckInterval = 500 * time.Millisecond
+
+// Manager is the camera manager a Supervisor watches. Slots are indexes into
+// the camera list last passed to SetCameras.
+type Manager interface {
+	// LastFrameTime returns when the camera in slot last produced a frame,
+	// or the zero time if it has not.
+	LastFrameTime(slot int) time.Time
+	// RestartCamera restarts the capture of one camera, leaving the others
+	// running.
+	RestartCamera(slot int) error
+	// Reinitialize rediscovers all cameras after a new device appeared and
+	// returns the new camera list.
+	Reinitialize() ([]camera.Camera, error)
+}
+
+// Supervisor runs the stale-frame, hotplug and health loops for one manager.
+type Supervisor struct {
+	cfg     *config.Config
+	slots   int
+	manager Manager
+	clock   Clock
+	devices Devices
+	events  *events.Bus
+
+	// Camera state per slot
+	stateLock     sync.RWMutex // Protects cameras, cameraStatus, lastFrameTime, initialized
+	cameras       []camera.Camera
+	cameraStatus  []bool      // true = connected, false = disconnected
+	lastFrameTime []time.Time // When each camera last produced a frame
+	initialized   bool        // SetCameras has been called
+
+	// Hot-plug detection
+	reinitInProgress   bool // Prevents concurrent reinitializations
+	reinitLock         sync.Mutex
+	lastDisconnectTime []time.Time // Per-camera debounce tracking
+	failedNewDevice    map[string]time.Time
+
+	// Stale frame detection + bounded auto-restart
+	restartEvents   [][]time.Time // Sliding window of restart timestamps
+	restartTotal    []uint64      // Restarts since startup (for /metrics)
+	restartMu       sync.Mutex    // Guards restartEvents writes and restartTotal for /metrics
+	lastRestartTime []time.Time   // Last restart timestamp per camera
+	restartLimitHit []bool        // Whether restart limit was reached
+
+	// Status subscribers, set before Start
+	onStatus  func(slot int, connected bool)
+	onCameras func(cams []camera.Camera)
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	loops    sync.WaitGroup // Supervision loops
+	actions  sync.WaitGroup // Restarts and reinitializations in flight
+}
+
+// New creates a supervisor for slots camera slots of manager. Nothing runs
+// until Start.
+func New(cfg *config.Config, slots int, manager Manager) *Supervisor {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	return &Supervisor{
+		cfg:                cfg,
+		slots:              slots,
+		manager:            manager,
+		clock:              realClock{},
+		devices:            sysfsDevices{},
+		cameraStatus:       make([]bool, slots),
+		lastFrameTime:      make([]time.Time, slots),
+		lastDisconnectTime: make([]time.Time, slots),
+		failedNewDevice:    make(map[string]time.Time),
+		restartEvents:      make([][]time.Time, slots),
+		restartTotal:       make([]uint64, slots),
+		lastRestartTime:    make([]time.Time, slots),
+		restartLimitHit:    make([]bool, slots),
+		stopCh:             make(chan struct{}),
+	}
+}
+
+// SetClock replaces the wall clock, e.g. with a fake in tests. Call before Start.
+func (s *Supervisor) SetClock(c Clock) {
+	s.clock = c
+}
+
+// SetDevices replaces the /dev and sysfs probes. Call before Start.
+func (s *Supervisor) SetDevices(d Devices) {
+	s.devices = d
+}
+
+// SetEventBus publishes camera state changes to bus. Call before Start.
+func (s *Supervisor) SetEventBus(bus *events.Bus) {
+	s.events = bus
+}
+
+// SetStatusHandler registers fn to be called with every camera status
+// update, including repeated ones. Call before Start.
+func (s *Supervisor) SetStatusHandler(fn func(slot int, connected bool)) {
+	s.onStatus = fn
+}
+
+// SetCamerasHandler registers fn to be called after the camera list
+// changes. Call before Start.
+func (s *Supervisor) SetCamerasHandler(fn func(cams []camera.Camera)) {
+	s.onCameras = fn
+}
+
+// Start runs the hotplug, stale-frame and health loops until Stop.
+func (s *Supervisor) Start() {
+	s.loops.Add(3)
+	go func() {
+		defer s.loops.Done()
+		s.runHotplugDetection()
+	}()
+	go func() {
+		defer s.loops.Done()
+		s.runStaleFrameDetection()
+	}()
+	go func() {
+		defer s.loops.Done()
+		s.runHealthLogging()
+	}()
+}
+
+// Stop ends the loops and waits for them. Restarts already in flight finish
+// on their own.
+func (s *Supervisor) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.loops.Wait()
+}
+
+// SetCameras replaces the camera list after the manager was (re)initialized
+// and sets each slot's status from Camera.Available. Statuses are set
+// directly, so cameras that stay connected across a reinitialization do not
+// flap (and publish disconnect events).
+func (s *Supervisor) SetCameras(cams []camera.Camera) {
+	s.stateLock.Lock()
+	s.cameras = append([]camera.Camera(nil), cams...)
+	s.initialized = true
+	s.stateLock.Unlock()
+	if s.onCameras != nil {
+		s.onCameras(append([]camera.Camera(nil), cams...))
+	}
+
+	for i := 0; i < s.slots; i++ {
+		s.updateCameraStatus(i, i < len(cams) && cams[i].Available)
+	}
+}
+
+// Cameras returns a copy of the camera list; index == slot.
+func (s *Supervisor) Cameras() []camera.Camera {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	return append([]camera.Camera(nil), s.cameras...)
+}
+
+// Camera returns the camera in slot.
+func (s *Supervisor) Camera(slot int) (camera.Camera, bool) {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	if slot < 0 || slot >= len(s.cameras) {
+		return camera.Camera{}, false
+	}
+	return s.cameras[slot], true
+}
+
+// Connected reports whether the camera in slot is connected.
+func (s *Supervisor) Connected(slot int) bool {
+	s.stateLock.RLock()
+	defer s.stateLock.RUnlock()
+	return slot >= 0 && slot < len(s.cameraStatus) && s.cameraStatus[slot]
+}
+
+// CameraLabel returns the configured role of a camera slot for logs,
+// or "Camera <index>" when no role is set.
+func (s *Supervisor) CameraLabel(slot int) string {
+	if cam, ok := s.Camera(slot); ok {
+		return cam.Label(slot)
+	}
+	return fmt.Sprintf("Camera %d", slot)
+}
+
+// updateCameraStatus updates the connected/disconnected status for a camera slot
+func (s *Supervisor) updateCameraStatus(camIndex int, connected bool) {
+	if camIndex < 0 || camIndex >= len(s.cameraStatus) {
+		return
+	}
+
+	s.stateLock.Lock()
+	previousStatus := s.cameraStatus[camIndex]
+	s.cameraStatus[camIndex] = connected
+	s.stateLock.Unlock()
+
+	if previousStatus != connected {
+		label := s.CameraLabel(camIndex)
+		log.Printf("[Supervisor] %s status changed: connected=%v", label, connected)
+		evType := events.CameraDisconnected
+		if connected {
+			evType = events.CameraConnected
+		}
+		s.events.Publish(events.Camera(evType, camIndex, label, ""))
+	}
+
+	if s.onStatus != nil {
+		s.onStatus(camIndex, connected)
+	}
+}
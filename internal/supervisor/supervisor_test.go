@@ -0,0 +1,334 @@
+package supervisor
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/events"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when advanced. Its tickers never fire; tests call
+// the loop bodies directly.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func (c *fakeClock) Sleep(time.Duration) {}
+
+func (c *fakeClock) NewTicker(time.Duration) Ticker { return fakeTicker{} }
+
+type fakeTicker struct{}
+
+func (fakeTicker) C() <-chan time.Time { return nil }
+func (fakeTicker) Stop()               {}
+
+type fakeManager struct {
+	mu        sync.Mutex
+	lastFrame map[int]time.Time
+	restarts  map[int]int
+	reinit    []camera.Camera
+}
+
+func (m *fakeManager) LastFrameTime(slot int) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastFrame[slot]
+}
+
+func (m *fakeManager) setFrame(slot int, t time.Time) {
+	m.mu.Lock()
+	m.lastFrame[slot] = t
+	m.mu.Unlock()
+}
+
+func (m *fakeManager) RestartCamera(slot int) error {
+	m.mu.Lock()
+	m.restarts[slot]++
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *fakeManager) restartCount(slot int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restarts[slot]
+}
+
+func (m *fakeManager) Reinitialize() ([]camera.Camera, error) {
+	return m.reinit, nil
+}
+
+// fakeDevices reports the paths in present as existing and the paths in
+// fresh as new cameras.
+type fakeDevices struct {
+	mu      sync.Mutex
+	present map[string]bool
+	fresh   map[string]bool
+}
+
+func (d *fakeDevices) Exists(devPath string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.present[devPath]
+}
+
+func (d *fakeDevices) IsNewCamera(devPath string, tracked map[string]bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.fresh[devPath] && !tracked[devPath]
+}
+
+func (d *fakeDevices) KillHolders(string, bool) {}
+
+func (d *fakeDevices) set(devPath string, present bool) {
+	d.mu.Lock()
+	d.present[devPath] = present
+	d.mu.Unlock()
+}
+
+func newTestSupervisor(cfg *config.Config) (*Supervisor, *fakeClock, *fakeManager, *fakeDevices) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	mgr := &fakeManager{lastFrame: map[int]time.Time{}, restarts: map[int]int{}}
+	devs := &fakeDevices{present: map[string]bool{}, fresh: map[string]bool{}}
+	s := New(cfg, 2, mgr)
+	s.SetClock(clock)
+	s.SetDevices(devs)
+	s.SetEventBus(events.NewBus())
+	return s, clock, mgr, devs
+}
+
+func testCameras(paths ...string) []camera.Camera {
+	cams := make([]camera.Camera, len(paths))
+	for i, p := range paths {
+		cams[i] = camera.Camera{DeviceID: p, DevicePath: p, Available: true}
+	}
+	return cams
+}
+
+// tick runs one pass of the stale-frame loop and waits for its restarts.
+func tick(s *Supervisor) {
+	s.trackFrames()
+	s.checkStaleFrames()
+	s.actions.Wait()
+}
+
+func TestSupervisor_RestartsStaleCamera(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.StaleFrameTimeoutSec = 5
+	s, clock, mgr, _ := newTestSupervisor(cfg)
+	sub := s.events.Subscribe(16)
+	defer sub.Close()
+
+	s.SetCameras(testCameras("/dev/video0", "/dev/video2"))
+	mgr.setFrame(0, clock.Now())
+	mgr.setFrame(1, clock.Now())
+	tick(s)
+
+	clock.Advance(6 * time.Second)
+	mgr.setFrame(0, clock.Now())
+	tick(s)
+
+	if got := mgr.restartCount(0); got != 0 {
+		t.Errorf("fresh camera restarted %d times", got)
+	}
+	if got := mgr.restartCount(1); got != 1 {
+		t.Fatalf("stale camera restarted %d times, want 1", got)
+	}
+	if !s.Connected(1) {
+		t.Error("camera 1 not connected after restart")
+	}
+	if status, _ := s.CameraHealth(1, clock.Now()); status != HealthOnline {
+		t.Errorf("camera 1 health = %s after restart, want %s", status, HealthOnline)
+	}
+	for _, slot := range []int{-1, 99} {
+		if status, age := s.CameraHealth(slot, clock.Now()); status != HealthDisconnected || age != -1 {
+			t.Errorf("CameraHealth(%d) = %s %v, want %s -1", slot, status, age, HealthDisconnected)
+		}
+	}
+	recent, totals := s.RestartCounts()
+	if recent[1] != 1 || totals[1] != 1 || totals[0] != 0 {
+		t.Errorf("restart counts = %v %v", recent, totals)
+	}
+
+	var types []events.Type
+	for len(sub.C) > 0 {
+		types = append(types, (<-sub.C).Type)
+	}
+	want := []events.Type{events.CameraConnected, events.CameraConnected,
+		events.CameraDisconnected, events.CameraRestart, events.CameraConnected}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("events = %v, want %v", types, want)
+		}
+	}
+}
+
+func TestSupervisor_RestartLimit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.StaleFrameTimeoutSec = 1
+	cfg.RestartCooldownSec = 2
+	cfg.MaxRestartsPerWindow = 2
+	cfg.RestartWindowSec = 30
+	s, clock, mgr, _ := newTestSupervisor(cfg)
+	sub := s.events.Subscribe(32)
+	defer sub.Close()
+	s.SetCameras(testCameras("/dev/video0", "/dev/video2"))
+
+	// Within the cooldown a second restart is refused
+	s.restartCaptureIfStale(0)
+	clock.Advance(time.Second)
+	s.restartCaptureIfStale(0)
+	s.actions.Wait()
+	if got := mgr.restartCount(0); got != 1 {
+		t.Fatalf("restarts within cooldown = %d, want 1", got)
+	}
+
+	clock.Advance(2 * time.Second)
+	s.restartCaptureIfStale(0)
+	clock.Advance(3 * time.Second)
+	s.restartCaptureIfStale(0)
+	s.actions.Wait()
+	if got := mgr.restartCount(0); got != 2 {
+		t.Fatalf("restarts after limit = %d, want 2", got)
+	}
+
+	limitEvents := 0
+	for len(sub.C) > 0 {
+		if (<-sub.C).Type == events.CameraRestartLimit {
+			limitEvents++
+		}
+	}
+	if limitEvents != 1 {
+		t.Errorf("restart limit events = %d, want 1", limitEvents)
+	}
+
+	// After the extended cooldown (2x window) recovery is attempted
+	clock.Advance(61 * time.Second)
+	s.restartCaptureIfStale(0)
+	s.actions.Wait()
+	if got := mgr.restartCount(0); got != 3 {
+		t.Errorf("restarts after extended cooldown = %d, want 3", got)
+	}
+	if _, totals := s.RestartCounts(); totals[0] != 3 {
+		t.Errorf("restart total = %d, want 3", totals[0])
+	}
+}
+
+func TestSupervisor_ReconnectDebounce(t *testing.T) {
+	s, clock, mgr, devs := newTestSupervisor(config.DefaultConfig())
+	devs.set("/dev/video0", true)
+	devs.set("/dev/video2", true)
+	s.SetCameras(testCameras("/dev/video0", "/dev/video2"))
+
+	devs.set("/dev/video2", false)
+	s.checkCameraChanges()
+	if s.Connected(1) {
+		t.Fatal("camera 1 still connected after its device disappeared")
+	}
+
+	// A device that comes back within the debounce is ignored
+	clock.Advance(time.Second)
+	devs.set("/dev/video2", true)
+	s.checkCameraChanges()
+	s.actions.Wait()
+	if mgr.restartCount(1) != 0 || s.Connected(1) {
+		t.Fatal("reconnect within debounce was not ignored")
+	}
+
+	clock.Advance(3 * time.Second)
+	s.checkCameraChanges()
+	s.actions.Wait()
+	if got := mgr.restartCount(1); got != 1 {
+		t.Fatalf("restarts after debounce = %d, want 1", got)
+	}
+	if !s.Connected(1) {
+		t.Error("camera 1 not connected after reconnect")
+	}
+}
+
+func TestSupervisor_NewCameraReinitializes(t *testing.T) {
+	s, _, mgr, devs := newTestSupervisor(config.DefaultConfig())
+	var mu sync.Mutex
+	var statuses []bool
+	s.SetStatusHandler(func(slot int, connected bool) {
+		if slot == 0 {
+			mu.Lock()
+			statuses = append(statuses, connected)
+			mu.Unlock()
+		}
+	})
+	var listed []camera.Camera
+	s.SetCamerasHandler(func(cams []camera.Camera) { listed = cams })
+	devs.set("/dev/video0", true)
+	s.SetCameras(testCameras("/dev/video0"))
+
+	sub := s.events.Subscribe(16)
+	defer sub.Close()
+	devs.set("/dev/video4", true)
+	devs.fresh["/dev/video4"] = true
+	mgr.reinit = testCameras("/dev/video0", "/dev/video4")
+	s.checkCameraChanges()
+	s.actions.Wait()
+
+	if len(listed) != 2 || !s.Connected(1) {
+		t.Fatalf("cameras after reinit = %v (slot 1 connected=%v)", listed, s.Connected(1))
+	}
+	mu.Lock()
+	for _, connected := range statuses {
+		if !connected {
+			t.Errorf("slot 0 reported disconnected during reinit: %v", statuses)
+			break
+		}
+	}
+	mu.Unlock()
+
+	added := false
+	for len(sub.C) > 0 {
+		ev := <-sub.C
+		switch ev.Type {
+		case events.CameraAdded:
+			added = *ev.Slot == 1 && ev.Message == "/dev/video4"
+		case events.CameraDisconnected:
+			t.Errorf("unexpected disconnect event for slot %d", *ev.Slot)
+		}
+	}
+	if !added {
+		t.Error("no CameraAdded event for slot 1")
+	}
+}
+
+func TestSupervisor_StartStop(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HealthLogIntervalSec = 1
+	s, _, _, _ := newTestSupervisor(cfg)
+	s.Start()
+	done := make(chan struct{})
+	go func() {
+		s.Stop()
+		s.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
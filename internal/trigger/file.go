@@ -0,0 +1,97 @@
+package trigger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+)
+
+// maxFileValue bounds how much of a value file is read.
+const maxFileValue = 64
+
+// openFile returns an input reading path. A regular file (or sysfs
+// attribute) is re-read on every poll, and a missing file counts as
+// inactive, so "echo 1 > /run/reverse" and "rm /run/reverse" both work. A
+// FIFO is read line by line and holds the last value written to it.
+func openFile(path string) (Input, error) {
+	fi, err := os.Stat(path)
+	if err == nil && fi.Mode()&fs.ModeNamedPipe != 0 {
+		return openFIFO(path)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return fileInput{path: path}, nil
+}
+
+// fileInput reads a file on every poll.
+type fileInput struct {
+	path string
+}
+
+func (f fileInput) Value() (bool, error) {
+	file, err := os.Open(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	buf := make([]byte, maxFileValue)
+	n, err := file.Read(buf)
+	if err != nil && n == 0 && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return parseValue(string(buf[:n]))
+}
+
+func (f fileInput) Close() error { return nil }
+
+// fifoInput keeps the last line written to a FIFO.
+type fifoInput struct {
+	file *os.File
+
+	mu    sync.Mutex
+	value bool
+}
+
+// openFIFO opens path read-write, so opening does not wait for a writer
+// and writers closing their end does not end the stream (Linux semantics).
+func openFIFO(path string) (Input, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open fifo: %w", err)
+	}
+	f := &fifoInput{file: file}
+	go f.read(path)
+	return f, nil
+}
+
+// read stores each line's value until the FIFO is closed.
+func (f *fifoInput) read(path string) {
+	scanner := bufio.NewScanner(f.file)
+	for scanner.Scan() {
+		v, err := parseValue(scanner.Text())
+		if err != nil {
+			log.Printf("[Trigger] %s: ignoring line: %v", path, err)
+			continue
+		}
+		f.mu.Lock()
+		f.value = v
+		f.mu.Unlock()
+	}
+}
+
+func (f *fifoInput) Value() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.value, nil
+}
+
+func (f *fifoInput) Close() error { return f.file.Close() }
@@ -0,0 +1,14 @@
+package trigger
+
+import "strings"
+
+// chipPath turns "gpiochip0" or "0" into "/dev/gpiochip0"; paths are kept.
+func chipPath(chip string) string {
+	if strings.Contains(chip, "/") {
+		return chip
+	}
+	if strings.Trim(chip, "0123456789") == "" {
+		return "/dev/gpiochip" + chip
+	}
+	return "/dev/" + chip
+}
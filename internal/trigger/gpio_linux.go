@@ -0,0 +1,131 @@
+package trigger
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// =============================================================================
+// GPIO Character Device (uAPI v2)
+// =============================================================================
+// A line is requested as an input from /dev/gpiochipN with
+// GPIO_V2_GET_LINE_IOCTL; the kernel returns a line fd that answers
+// GPIO_V2_LINE_GET_VALUES_IOCTL. The kernel applies active_low and bias, so
+// a value of 1 always means active. Works with the gpio-sim test module.
+// =============================================================================
+
+// Line flags (linux/gpio.h)
+const (
+	gpioV2LineFlagActiveLow    = 1 << 1
+	gpioV2LineFlagInput        = 1 << 2
+	gpioV2LineFlagBiasPullUp   = 1 << 8
+	gpioV2LineFlagBiasPullDown = 1 << 9
+	gpioV2LineFlagBiasDisabled = 1 << 10
+)
+
+type gpioV2LineAttribute struct {
+	ID      uint32
+	Padding uint32
+	Value   uint64 // flags, values or debounce_period_us
+}
+
+type gpioV2LineConfigAttribute struct {
+	Attr gpioV2LineAttribute
+	Mask uint64
+}
+
+type gpioV2LineConfig struct {
+	Flags    uint64
+	NumAttrs uint32
+	Padding  [5]uint32
+	Attrs    [10]gpioV2LineConfigAttribute
+}
+
+type gpioV2LineRequest struct {
+	Offsets         [64]uint32
+	Consumer        [32]byte
+	Config          gpioV2LineConfig
+	NumLines        uint32
+	EventBufferSize uint32
+	Padding         [5]uint32
+	Fd              int32
+}
+
+type gpioV2LineValues struct {
+	Bits uint64
+	Mask uint64
+}
+
+// gpioIOWR encodes a read-write ioctl request of the GPIO uAPI (type 0xB4).
+func gpioIOWR(nr, size uintptr) uintptr {
+	return 3<<30 | size<<16 | 0xB4<<8 | nr
+}
+
+var (
+	gpioV2GetLineIoctl       = gpioIOWR(0x07, unsafe.Sizeof(gpioV2LineRequest{}))
+	gpioV2LineGetValuesIoctl = gpioIOWR(0x0E, unsafe.Sizeof(gpioV2LineValues{}))
+)
+
+// gpioInput is a requested input line.
+type gpioInput struct {
+	fd int
+}
+
+// openGPIO requests line of chip as an input.
+func openGPIO(chip string, line int, activeLow bool, bias, consumer string) (Input, error) {
+	flags := uint64(gpioV2LineFlagInput)
+	if activeLow {
+		flags |= gpioV2LineFlagActiveLow
+	}
+	switch bias {
+	case "":
+	case "pull-up":
+		flags |= gpioV2LineFlagBiasPullUp
+	case "pull-down":
+		flags |= gpioV2LineFlagBiasPullDown
+	case "disable":
+		flags |= gpioV2LineFlagBiasDisabled
+	default:
+		return nil, fmt.Errorf("unknown bias %q (want pull-up, pull-down or disable)", bias)
+	}
+
+	chipFd, err := syscall.Open(chip, syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", chip, err)
+	}
+	defer syscall.Close(chipFd)
+
+	var req gpioV2LineRequest
+	req.Offsets[0] = uint32(line)
+	req.NumLines = 1
+	req.Config.Flags = flags
+	copy(req.Consumer[:len(req.Consumer)-1], consumer)
+	if err := ioctl(chipFd, gpioV2GetLineIoctl, unsafe.Pointer(&req)); err != nil {
+		return nil, fmt.Errorf("request %s line %d: %w", chip, line, err)
+	}
+	return &gpioInput{fd: int(req.Fd)}, nil
+}
+
+func (g *gpioInput) Value() (bool, error) {
+	values := gpioV2LineValues{Mask: 1}
+	if err := ioctl(g.fd, gpioV2LineGetValuesIoctl, unsafe.Pointer(&values)); err != nil {
+		return false, err
+	}
+	return values.Bits&1 != 0, nil
+}
+
+func (g *gpioInput) Close() error { return syscall.Close(g.fd) }
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	for {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+		if errno == syscall.EINTR {
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+package trigger
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// The ioctl numbers encode the struct sizes, which must match linux/gpio.h.
+func TestGPIOStructSizes(t *testing.T) {
+	if got := unsafe.Sizeof(gpioV2LineRequest{}); got != 592 {
+		t.Errorf("sizeof(gpio_v2_line_request) = %d, want 592", got)
+	}
+	if got := unsafe.Sizeof(gpioV2LineValues{}); got != 16 {
+		t.Errorf("sizeof(gpio_v2_line_values) = %d, want 16", got)
+	}
+	if gpioV2GetLineIoctl != 0xC250B407 || gpioV2LineGetValuesIoctl != 0xC010B40E {
+		t.Errorf("ioctls = %#x %#x", gpioV2GetLineIoctl, gpioV2LineGetValuesIoctl)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package trigger
+
+import "errors"
+
+// openGPIO is unavailable off Linux; use a file trigger instead.
+func openGPIO(chip string, line int, activeLow bool, bias, consumer string) (Input, error) {
+	return nil, errors.New("gpio triggers are only supported on Linux")
+}
@@ -0,0 +1,299 @@
+// Package trigger watches named inputs - a GPIO line, a file or FIFO, or
+// an API call - and reports when they become active or clear, e.g. to show
+// the rear camera fullscreen while reverse gear is engaged.
+//
+// Inputs are polled. A new value counts once it held for the trigger's
+// debounce time, and an active trigger clears only after its input stayed
+// inactive for the hold time, so a blinking turn signal keeps its camera up.
+package trigger
+
+import (
+	"camera-dashboard-go/internal/config"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sources
+const (
+	SourceGPIO = "gpio"
+	SourceFile = "file"
+	SourceAPI  = "api"
+)
+
+// Actions
+const (
+	ActionFullscreen = "fullscreen" // Show the trigger's camera fullscreen while active
+	ActionClip       = "clip"       // Save an event clip of the camera when activated
+)
+
+// PollInterval is how often Run reads the inputs.
+const PollInterval = 20 * time.Millisecond
+
+// Input is the value source of a trigger.
+type Input interface {
+	Value() (bool, error)
+	Close() error
+}
+
+// State describes one trigger (GET /api/state).
+type State struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Action string `json:"action"`
+	Camera string `json:"camera,omitempty"`
+	Active bool   `json:"active"`
+}
+
+// trigger is one opened [trigger.<name>] section.
+type trigger struct {
+	cfg   config.TriggerConfig
+	input Input
+	api   *apiInput // Set for API triggers
+
+	raw      bool      // Last value read
+	rawSince time.Time // When raw last changed
+	active   bool
+	lastErr  string // Last read error, logged once
+}
+
+// Set watches a group of triggers.
+type Set struct {
+	mu       sync.Mutex
+	triggers []*trigger
+	closed   bool
+	onChange func(cfg config.TriggerConfig, active bool)
+}
+
+// Open opens the inputs of cfgs. Triggers that fail to open are logged and
+// left out, so one unplugged input does not disable the others.
+func Open(cfgs []config.TriggerConfig) *Set {
+	s := &Set{}
+	for _, cfg := range cfgs {
+		t, err := openTrigger(cfg)
+		if err != nil {
+			log.Printf("[Trigger] %s: disabled: %v", cfg.Name, err)
+			continue
+		}
+		log.Printf("[Trigger] %s: watching %s (%s %s)", cfg.Name, describe(cfg), cfg.Action, cfg.Camera)
+		s.triggers = append(s.triggers, t)
+	}
+	return s
+}
+
+// openTrigger checks cfg and opens its input.
+func openTrigger(cfg config.TriggerConfig) (*trigger, error) {
+	switch cfg.Action {
+	case ActionFullscreen, ActionClip:
+	default:
+		return nil, fmt.Errorf("unknown action %q (want %s or %s)", cfg.Action, ActionFullscreen, ActionClip)
+	}
+	if cfg.Action == ActionFullscreen && cfg.Camera == "" {
+		return nil, fmt.Errorf("action %s needs a camera", cfg.Action)
+	}
+
+	t := &trigger{cfg: cfg}
+	switch cfg.Source {
+	case SourceGPIO:
+		if cfg.Chip == "" || cfg.Line < 0 {
+			return nil, fmt.Errorf("gpio source needs chip and line")
+		}
+		in, err := openGPIO(chipPath(cfg.Chip), cfg.Line, cfg.ActiveLow, cfg.Bias, "camera-dashboard:"+cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		t.input = in
+	case SourceFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file source needs a path")
+		}
+		in, err := openFile(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		t.input = in
+	case SourceAPI:
+		t.api = &apiInput{}
+		t.input = t.api
+	default:
+		return nil, fmt.Errorf("unknown source %q (want %s, %s or %s)", cfg.Source, SourceGPIO, SourceFile, SourceAPI)
+	}
+	return t, nil
+}
+
+// describe names a trigger's input for log lines.
+func describe(cfg config.TriggerConfig) string {
+	switch cfg.Source {
+	case SourceGPIO:
+		return fmt.Sprintf("%s line %d", chipPath(cfg.Chip), cfg.Line)
+	case SourceFile:
+		return cfg.Path
+	default:
+		return cfg.Source
+	}
+}
+
+// SetHandler registers fn to be called when a trigger becomes active or
+// clears. It runs on the polling goroutine. Call before Run.
+func (s *Set) SetHandler(fn func(cfg config.TriggerConfig, active bool)) {
+	s.onChange = fn
+}
+
+// Len returns the number of opened triggers.
+func (s *Set) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.triggers)
+}
+
+// Run polls the inputs every PollInterval until stop is closed.
+func (s *Set) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			s.Poll(now)
+		}
+	}
+}
+
+// Poll reads every input once and reports the triggers that changed.
+func (s *Set) Poll(now time.Time) {
+	type change struct {
+		cfg    config.TriggerConfig
+		active bool
+	}
+	var changes []change
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	for _, t := range s.triggers {
+		v, err := t.input.Value()
+		if err != nil {
+			// Keep the last value; a flapping read error must not toggle the display
+			if msg := err.Error(); msg != t.lastErr {
+				log.Printf("[Trigger] %s: read failed: %v", t.cfg.Name, err)
+				t.lastErr = msg
+			}
+			continue
+		}
+		t.lastErr = ""
+		if v != t.raw || t.rawSince.IsZero() {
+			t.raw = v
+			t.rawSince = now
+		}
+
+		held := now.Sub(t.rawSince)
+		debounce := time.Duration(t.cfg.DebounceMS) * time.Millisecond
+		hold := time.Duration(t.cfg.HoldMS) * time.Millisecond
+		if hold < debounce {
+			hold = debounce
+		}
+		switch {
+		case t.raw && !t.active && held >= debounce:
+			t.active = true
+		case !t.raw && t.active && held >= hold:
+			t.active = false
+		default:
+			continue
+		}
+		changes = append(changes, change{t.cfg, t.active})
+	}
+	s.mu.Unlock()
+
+	for _, c := range changes {
+		if s.onChange != nil {
+			s.onChange(c.cfg, c.active)
+		}
+	}
+}
+
+// Set sets the value of an API trigger; the next Poll picks it up.
+func (s *Set) Set(name string, active bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.triggers {
+		if t.cfg.Name != name {
+			continue
+		}
+		if t.api == nil {
+			return fmt.Errorf("trigger %q has source %s, not %s", name, t.cfg.Source, SourceAPI)
+		}
+		t.api.set(active)
+		return nil
+	}
+	return fmt.Errorf("unknown trigger %q", name)
+}
+
+// States returns the opened triggers in config order.
+func (s *Set) States() []State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]State, len(s.triggers))
+	for i, t := range s.triggers {
+		states[i] = State{
+			Name:   t.cfg.Name,
+			Source: t.cfg.Source,
+			Action: t.cfg.Action,
+			Camera: t.cfg.Camera,
+			Active: t.active,
+		}
+	}
+	return states
+}
+
+// Close releases the inputs. Poll does nothing afterwards.
+func (s *Set) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for _, t := range s.triggers {
+		if err := t.input.Close(); err != nil {
+			log.Printf("[Trigger] %s: close failed: %v", t.cfg.Name, err)
+		}
+	}
+}
+
+// apiInput is set through Set.Set.
+type apiInput struct {
+	mu    sync.Mutex
+	value bool
+}
+
+func (a *apiInput) set(v bool) {
+	a.mu.Lock()
+	a.value = v
+	a.mu.Unlock()
+}
+
+func (a *apiInput) Value() (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.value, nil
+}
+
+func (a *apiInput) Close() error { return nil }
+
+// parseValue reads an input value: 1/on/true/high/yes/active are active,
+// 0/off/false/low/no/inactive and empty are not.
+func parseValue(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "on", "true", "high", "yes", "active":
+		return true, nil
+	case "", "0", "off", "false", "low", "no", "inactive":
+		return false, nil
+	default:
+		return false, fmt.Errorf("unrecognized value %q", strings.TrimSpace(s))
+	}
+}
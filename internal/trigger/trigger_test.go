@@ -0,0 +1,166 @@
+package trigger
+
+import (
+	"camera-dashboard-go/internal/config"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type change struct {
+	name   string
+	active bool
+}
+
+func openRecorded(cfgs ...config.TriggerConfig) (*Set, *[]change) {
+	s := Open(cfgs)
+	var changes []change
+	s.SetHandler(func(cfg config.TriggerConfig, active bool) {
+		changes = append(changes, change{cfg.Name, active})
+	})
+	return s, &changes
+}
+
+func apiTrigger(name string, debounceMS, holdMS int) config.TriggerConfig {
+	return config.TriggerConfig{Name: name, Source: SourceAPI, Line: -1, Action: ActionFullscreen,
+		Camera: "Rear", DebounceMS: debounceMS, HoldMS: holdMS}
+}
+
+func TestSet_DebounceAndHold(t *testing.T) {
+	s, changes := openRecorded(apiTrigger("left", 50, 1000))
+	defer s.Close()
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+
+	s.Poll(at(0))
+	if err := s.Set("left", true); err != nil {
+		t.Fatal(err)
+	}
+	// A 30ms glitch does not count
+	s.Poll(at(10))
+	s.Set("left", false)
+	s.Poll(at(40))
+	if len(*changes) != 0 {
+		t.Fatalf("glitch shorter than debounce reported: %v", *changes)
+	}
+
+	s.Set("left", true)
+	s.Poll(at(100))
+	s.Poll(at(160))
+	if len(*changes) != 1 || !(*changes)[0].active || !s.States()[0].Active {
+		t.Fatalf("changes after debounce = %v, want active", *changes)
+	}
+
+	// Blinking: off for less than the hold time keeps the trigger active
+	for ms := 500; ms < 3000; ms += 500 {
+		s.Set("left", ms%1000 != 0)
+		s.Poll(at(ms))
+	}
+	if len(*changes) != 1 {
+		t.Fatalf("blinking input changed the trigger: %v", *changes)
+	}
+
+	s.Set("left", false)
+	s.Poll(at(3000))
+	s.Poll(at(3999))
+	if len(*changes) != 1 {
+		t.Fatalf("cleared before the hold time: %v", *changes)
+	}
+	s.Poll(at(4000))
+	if len(*changes) != 2 || (*changes)[1].active {
+		t.Fatalf("changes = %v, want cleared after the hold time", *changes)
+	}
+}
+
+func TestSet_FileInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reverse")
+	s, changes := openRecorded(config.TriggerConfig{Name: "reverse", Source: SourceFile, Path: path,
+		Line: -1, Action: ActionFullscreen, Camera: "Rear"})
+	defer s.Close()
+	if s.Len() != 1 {
+		t.Fatal("file trigger on a missing file not opened")
+	}
+	now := time.Now()
+
+	// Missing file: inactive
+	s.Poll(now)
+	os.WriteFile(path, []byte("1\n"), 0o644)
+	s.Poll(now.Add(time.Millisecond))
+	if len(*changes) != 1 || !(*changes)[0].active {
+		t.Fatalf("changes = %v, want active after writing 1", *changes)
+	}
+
+	// Unreadable values keep the last state
+	os.WriteFile(path, []byte("maybe"), 0o644)
+	s.Poll(now.Add(time.Second))
+	os.WriteFile(path, []byte("off"), 0o644)
+	s.Poll(now.Add(2 * time.Second))
+	if len(*changes) != 2 || (*changes)[1].active {
+		t.Fatalf("changes = %v, want cleared after writing off", *changes)
+	}
+}
+
+func TestSet_FIFOInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gear")
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	s := Open([]config.TriggerConfig{{Name: "gear", Source: SourceFile, Path: path,
+		Line: -1, Action: ActionClip}})
+	defer s.Close()
+
+	w, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("on\n")
+	w.Close() // The trigger keeps its read end open
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !s.States()[0].Active {
+		if time.Now().After(deadline) {
+			t.Fatal("fifo value never became active")
+		}
+		s.Poll(time.Now())
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	s := Open([]config.TriggerConfig{
+		{Name: "nosource", Action: ActionFullscreen, Camera: "Rear"},
+		{Name: "nocamera", Source: SourceAPI, Action: ActionFullscreen},
+		{Name: "badaction", Source: SourceAPI, Action: "explode", Camera: "Rear"},
+		{Name: "noline", Source: SourceGPIO, Chip: "gpiochip0", Line: -1, Action: ActionClip},
+		{Name: "nochip", Source: SourceGPIO, Chip: filepath.Join(t.TempDir(), "gpiochip9"), Line: 3, Action: ActionClip},
+		{Name: "nopath", Source: SourceFile, Action: ActionClip},
+		apiTrigger("ok", 0, 0),
+	})
+	defer s.Close()
+	if got := s.States(); len(got) != 1 || got[0].Name != "ok" {
+		t.Fatalf("opened triggers = %+v, want only ok", got)
+	}
+	if err := s.Set("missing", true); err == nil {
+		t.Error("Set on an unknown trigger succeeded")
+	}
+
+	f := Open([]config.TriggerConfig{{Name: "file", Source: SourceFile, Path: "/nonexistent/x", Action: ActionClip}})
+	defer f.Close()
+	if err := f.Set("file", true); err == nil {
+		t.Error("Set on a file trigger succeeded")
+	}
+}
+
+func TestChipPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"gpiochip0":      "/dev/gpiochip0",
+		"2":              "/dev/gpiochip2",
+		"/dev/gpiochip1": "/dev/gpiochip1",
+	} {
+		if got := chipPath(in); got != want {
+			t.Errorf("chipPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -3,40 +3,50 @@ package ui
 import (
 	"camera-dashboard-go/internal/camera"
 	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/core"
+	"camera-dashboard-go/internal/dewarp"
+	"camera-dashboard-go/internal/filter"
+	"camera-dashboard-go/internal/guides"
 	"camera-dashboard-go/internal/helpers"
-	"camera-dashboard-go/internal/perf"
+	"camera-dashboard-go/internal/motion"
+	"camera-dashboard-go/internal/recording"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 	"image"
 	"image/color"
 	"log"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 const holdThreshold = 400 * time.Millisecond
-const defaultBrightnessPercent = 100
-const defaultReconnectDebounce = 3 * time.Second
+const defaultBrightnessPercent = filter.DefaultBrightness
 
-// App represents the main camera dashboard application
+// App represents the main camera dashboard application. Cameras and their
+// supervision run in a core.Service; the App renders them and subscribes to
+// the service's status updates.
 type App struct {
 	fyneApp     fyne.App
 	window      fyne.Window
-	manager     *camera.Manager
-	cameras     []camera.Camera
+	core        *core.Service
 	cfg         *config.Config
 	cameraSlots int
 
+	// uiMu serializes everything that changes the grid or the fullscreen
+	// view - touch input, the REST API and triggers - and guards the state
+	// they share: gridSlots, gridWidgets, grid.Objects, the swap and
+	// fullscreen fields and triggerCam. Methods that say "Callers hold uiMu"
+	// expect it held; the core setters that call back into the App (e.g.
+	// SetMotionZones) must be called without it.
+	uiMu sync.Mutex
+
 	// Grid positions: index 0 is settings, index 1..N are camera slots.
 	// Each entry value is: -1 = settings, >=0 = camera index.
 	gridSlots []int
@@ -45,52 +55,53 @@ type App struct {
 	cameraImages  []*canvas.Image
 	cameraFrames  []image.Image
 	cameraWidgets []*TappableImage // References to camera TappableImage widgets
-	cameraStatus  []bool           // true = connected, false = disconnected
 	lastFrameRead []uint64         // Last frame timestamp read from each buffer
-	frameLock     sync.RWMutex     // Protects cameras, cameraFrames, cameraStatus, lastFrameTime
+	frameLock     sync.RWMutex     // Protects cameraFrames
 
 	// All grid widgets (for highlighting during swap). Index 0 is settings.
-	gridWidgets []Highlightable
+	gridWidgets    []Highlightable
+	settingsWidget *TappableSettings
 
 	// UI state
 	swapMode          bool
 	swapSourceSlot    int // Grid position (0..len(gridWidgets)-1)
 	isFullscreen      atomic.Bool
 	fullscreenSlot    int
+	fullscreenCam     int // Camera index shown fullscreen
 	fullscreenImg     *canvas.Image
 	fullscreenWidget  *TappableImage
 	fullscreenContent *fyne.Container
+	zoneEditor        *zoneEditor // Drag editor over the fullscreen image
+	zoneEditBtn       *widget.Button
+	zoneClearBtn      *widget.Button
+	zoneEditing       atomic.Bool
+	zoomPad           *zoomPad // Zoom/pan input over the fullscreen image
+	zoomBtn           *widget.Button
+	zoomResetBtn      *widget.Button
+	cropKeepBtn       *widget.Button
+	cropClearBtn      *widget.Button
+	triggerCam        int           // Camera a trigger showed fullscreen, -1 = none
 	fullscreenStopCh  chan struct{} // Stops the fullscreen update goroutine
 	fullscreenMu      sync.Mutex    // Protects fullscreen state transitions
 	gridContent       *fyne.Container
 	grid              *fyne.Container
-
-	// Hot-plug detection
-	hotplugStopCh      chan struct{}
-	reinitInProgress   bool // Prevents concurrent reinitializations
-	reinitLock         sync.Mutex
-	lastDisconnectTime []time.Time // Per-camera debounce tracking
-	failedNewDevice    map[string]time.Time
-	cleanupOnce        sync.Once // Prevents double close of hotplugStopCh
-
-	// Stale frame detection + bounded auto-restart
-	lastFrameTime   []time.Time   // When each camera last produced a frame
-	restartEvents   [][]time.Time // Sliding window of restart timestamps
-	lastRestartTime []time.Time   // Last restart timestamp per camera
-	restartLimitHit []bool        // Whether restart limit was reached
+	cleanupOnce       sync.Once
 
 	// Night mode
 	nightModeEnabled atomic.Bool
-	nightModeBufs    []*image.RGBA // Reusable buffers for night mode (one per camera slot)
-	nightModeFSBuf   *image.RGBA   // Reusable buffer for fullscreen night mode
 
 	// Brightness (Python parity: 15/60/80/100/150% presets from settings tile)
 	brightnessPercent atomic.Int32
-	brightnessBufs    []*image.RGBA // Reusable buffers for brightness filter (per camera slot)
-	brightnessFSBuf   *image.RGBA   // Reusable buffer for fullscreen brightness filter
 
-	// Performance management
-	perfController *perf.AdaptiveController
+	// Fisheye correction (lens of the camera in each slot, see core.Dewarp)
+	dewarpers []*dewarp.Remapper // One per camera slot; tables are shared with fullscreen
+
+	// Display filters: one chain per camera slot and one for fullscreen,
+	// each owning its buffers; nil chains are built on the next frame
+	filterMu    sync.Mutex
+	slotFilters []*filter.Chain
+	fsFilters   *filter.Chain
+	fsFilterCam int // Slot fsFilters was built for
 }
 
 // Highlightable interface for widgets that can be highlighted during swap
@@ -103,13 +114,8 @@ func NewApp(cfg *config.Config) *App {
 	if cfg == nil {
 		cfg = config.DefaultConfig()
 	}
-	slots := cfg.CameraSlotCount
-	if slots < 1 {
-		slots = 1
-	}
-	if slots > 8 {
-		slots = 8
-	}
+	svc := core.New(cfg)
+	slots := svc.Slots()
 
 	fyneApp := app.New()
 	window := fyneApp.NewWindow("Camera Dashboard - Go")
@@ -118,13 +124,13 @@ func NewApp(cfg *config.Config) *App {
 	window.SetFullScreen(true)
 
 	a := &App{
-		fyneApp:         fyneApp,
-		window:          window,
-		cfg:             cfg,
-		cameraSlots:     slots,
-		swapSourceSlot:  -1,
-		hotplugStopCh:   make(chan struct{}),
-		failedNewDevice: make(map[string]time.Time),
+		fyneApp:        fyneApp,
+		window:         window,
+		core:           svc,
+		cfg:            cfg,
+		cameraSlots:    slots,
+		swapSourceSlot: -1,
+		triggerCam:     -1,
 	}
 	a.brightnessPercent.Store(defaultBrightnessPercent)
 
@@ -134,15 +140,12 @@ func NewApp(cfg *config.Config) *App {
 	a.cameraImages = make([]*canvas.Image, slots)
 	a.cameraFrames = make([]image.Image, slots)
 	a.cameraWidgets = make([]*TappableImage, slots)
-	a.cameraStatus = make([]bool, slots)
 	a.lastFrameRead = make([]uint64, slots)
-	a.lastDisconnectTime = make([]time.Time, slots)
-	a.lastFrameTime = make([]time.Time, slots)
-	a.restartEvents = make([][]time.Time, slots)
-	a.lastRestartTime = make([]time.Time, slots)
-	a.restartLimitHit = make([]bool, slots)
-	a.nightModeBufs = make([]*image.RGBA, slots)
-	a.brightnessBufs = make([]*image.RGBA, slots)
+	a.dewarpers = make([]*dewarp.Remapper, slots)
+	a.slotFilters = make([]*filter.Chain, slots)
+	for i := range a.dewarpers {
+		a.dewarpers[i] = &dewarp.Remapper{}
+	}
 
 	a.gridSlots[0] = -1 // Settings
 	for i := 0; i < slots; i++ {
@@ -158,6 +161,13 @@ func NewApp(cfg *config.Config) *App {
 		a.cameraImages[i].FillMode = canvas.ImageFillStretch // Fill entire cell, no black bars
 	}
 
+	svc.SetStatusHandler(a.showCameraStatus)
+	svc.SetCamerasHandler(a.refreshCameraLabels)
+	svc.SetMotionHandler(a.showMotion)
+	svc.SetZonesHandler(a.showZones)
+	svc.SetTriggerHandler(a.triggerFullscreen)
+	svc.SetGuidesHandler(a.refreshGuides)
+	svc.SetController(apiController{core.NewController(svc), a})
 	return a
 }
 
@@ -183,22 +193,209 @@ func createColoredImage(width, height int, c color.Color) image.Image {
 	return img
 }
 
-func minInt(a, b int) int {
-	if a < b {
-		return a
+// refreshCameraLabels redraws the label on every camera tile after the
+// camera list changes.
+func (a *App) refreshCameraLabels(cameras []camera.Camera) {
+	for i, w := range a.cameraWidgets {
+		if w == nil {
+			continue
+		}
+		if i < len(cameras) {
+			w.SetLabel(core.DisplayName(cameras[i], i))
+		} else {
+			w.SetLabel("")
+		}
+	}
+	a.refreshFilters()
+	a.refreshGuides()
+}
+
+// refreshFilters gives each slot's remapper the lens of the camera now in
+// it and drops the filter chains, so they are rebuilt for the new cameras.
+// A new lens can hide or bring back the zone outlines and controls (see
+// alignedWithCamera), so those are redrawn too.
+func (a *App) refreshFilters() {
+	for i, r := range a.dewarpers {
+		r.SetParams(a.core.Dewarp(i))
+	}
+	a.filterMu.Lock()
+	for i := range a.slotFilters {
+		a.slotFilters[i] = nil
+	}
+	a.fsFilters = nil
+	a.filterMu.Unlock()
+
+	for i := range a.slotFilters {
+		a.showZones(i, a.core.MotionZones(i))
+	}
+	a.withUI(func() {
+		if a.isFullscreen.Load() {
+			a.refreshZoomControls()
+		}
+	})
+}
+
+// alignedWithCamera reports whether the tile (or fullscreen view) of slot
+// shows the camera frame without pixels moved by its filters. Crops and
+// motion zones are measured on the camera frame, so zone outlines, the zone
+// editor and "Keep as Crop" are only offered on such a picture.
+func (a *App) alignedWithCamera(slot int, fullscreen bool) bool {
+	if slot < 0 || slot >= len(a.slotFilters) {
+		return true
+	}
+	return !a.filterChain(slot, fullscreen).MovesPixels()
+}
+
+// filterChain returns the filter chain of a slot's tile, or of the
+// fullscreen view of it, building it on first use.
+func (a *App) filterChain(slot int, fullscreen bool) *filter.Chain {
+	a.filterMu.Lock()
+	defer a.filterMu.Unlock()
+	if fullscreen {
+		if a.fsFilters == nil || a.fsFilterCam != slot {
+			a.fsFilters, a.fsFilterCam = a.buildFilters(slot, true), slot
+		}
+		return a.fsFilters
+	}
+	if a.slotFilters[slot] == nil {
+		a.slotFilters[slot] = a.buildFilters(slot, false)
+	}
+	return a.slotFilters[slot]
+}
+
+// buildFilters parses the filter spec of the camera in slot, falling back
+// to the default chain when it is invalid.
+func (a *App) buildFilters(slot int, fullscreen bool) *filter.Chain {
+	in := filter.Inputs{
+		NightMode:  a.nightModeEnabled.Load,
+		Brightness: a.getBrightnessPercent,
+		Lens:       a.dewarpers[slot],
+		Crop:       func() camera.Crop { return a.core.Crop(slot) },
+	}
+	if fullscreen {
+		in.View = a.zoomPad.View
+	}
+	chain, err := filter.Parse(a.core.Filters(slot), in)
+	if err != nil {
+		label := fmt.Sprintf("camera %d", slot)
+		if cam, ok := a.core.Camera(slot); ok {
+			label = cam.Label(slot)
+		}
+		log.Printf("[UI] %s: %v; using filters %q", label, err, filter.DefaultSpec)
+		chain, _ = filter.Parse(filter.DefaultSpec, in)
+	}
+	return chain
+}
+
+// refreshGuides draws the parking guides on the tile of the [guides] camera,
+// and in fullscreen while it shows that camera, and clears them elsewhere.
+func (a *App) refreshGuides() {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	a.drawGuides()
+}
+
+// drawGuides is refreshGuides. Callers hold uiMu.
+func (a *App) drawGuides() {
+	if !a.cfg.GuidesEnabled {
+		return
+	}
+	slot, lines := a.core.GuideLines()
+	for i, w := range a.cameraWidgets {
+		if w == nil {
+			continue
+		}
+		if i == slot {
+			w.SetGuides(lines)
+		} else {
+			w.SetGuides(nil)
+		}
+	}
+	if a.fullscreenWidget != nil {
+		if a.isFullscreen.Load() && a.fullscreenCam == slot {
+			a.fullscreenWidget.SetGuides(lines)
+		} else {
+			a.fullscreenWidget.SetGuides(nil)
+		}
 	}
-	return b
 }
 
-func maxInt(a, b int) int {
-	if a > b {
-		return a
+// showCameraStatus shows or hides the "Disconnected" overlay of a camera
+// tile when the service reports its status.
+func (a *App) showCameraStatus(camIndex int, connected bool) {
+	if camIndex >= 0 && camIndex < len(a.cameraWidgets) && a.cameraWidgets[camIndex] != nil {
+		a.cameraWidgets[camIndex].SetDisconnected(!connected)
 	}
-	return b
 }
 
-func (a *App) effectiveSlots() int {
-	return a.cameraSlots
+// showMotion draws or clears the motion border of a camera tile, and of
+// the fullscreen view when it shows that camera.
+func (a *App) showMotion(camIndex int, active bool) {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	if camIndex >= 0 && camIndex < len(a.cameraWidgets) && a.cameraWidgets[camIndex] != nil {
+		a.cameraWidgets[camIndex].SetMotion(active)
+	}
+	if a.isFullscreen.Load() && a.fullscreenCam == camIndex && a.fullscreenWidget != nil {
+		a.fullscreenWidget.SetMotion(active)
+	}
+}
+
+// showZones redraws the zone outlines of a camera tile, and of the
+// fullscreen view when it shows that camera, if [motion] show_zones is set.
+// Pictures that do not line up with the camera frame get none.
+func (a *App) showZones(camIndex int, zones []motion.Polygon) {
+	if !a.cfg.MotionShowZones {
+		return
+	}
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	if camIndex >= 0 && camIndex < len(a.cameraWidgets) && a.cameraWidgets[camIndex] != nil {
+		if a.alignedWithCamera(camIndex, false) {
+			a.cameraWidgets[camIndex].SetZones(zones)
+		} else {
+			a.cameraWidgets[camIndex].SetZones(nil)
+		}
+	}
+	if a.isFullscreen.Load() && a.fullscreenCam == camIndex && a.fullscreenWidget != nil {
+		a.refreshZoomControls()
+	}
+}
+
+// triggerFullscreen shows a camera fullscreen for a trigger (e.g. reverse
+// gear), or with camIndex -1 returns to the grid - but only when the
+// fullscreen view still shows the camera a trigger put there, so a camera
+// the driver opened by hand stays up. It runs on the trigger goroutine and
+// takes uiMu, so a trigger and a tap on the screen are handled one after
+// the other.
+func (a *App) triggerFullscreen(camIndex int) {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	if camIndex < 0 {
+		if a.triggerCam >= 0 && a.isFullscreen.Load() && a.fullscreenCam == a.triggerCam {
+			a.hideFullscreen()
+		}
+		a.triggerCam = -1
+		return
+	}
+
+	gridPos := -1
+	for pos, content := range a.gridSlots {
+		if content == camIndex {
+			gridPos = pos
+		}
+	}
+	if gridPos < 0 {
+		return
+	}
+	if a.isFullscreen.Load() && a.fullscreenCam == camIndex {
+		return // Already up (opened by hand or by a trigger)
+	}
+	a.hideFullscreen()
+	a.showFullscreen(gridPos)
+	if a.isFullscreen.Load() {
+		a.triggerCam = camIndex
+	}
 }
 
 func (a *App) currentUIFPS() int {
@@ -207,11 +404,12 @@ func (a *App) currentUIFPS() int {
 		base = 20
 	}
 
-	if a.perfController == nil || !a.cfg.DynamicFPSEnabled {
+	pc := a.core.PerfController()
+	if pc == nil || !a.cfg.DynamicFPSEnabled {
 		return base
 	}
 
-	curCapture := a.perfController.GetCurrentFPS()
+	curCapture := pc.GetCurrentFPS()
 	baseCapture := a.cfg.CaptureFPS
 	if baseCapture <= 0 {
 		baseCapture = 1
@@ -230,14 +428,21 @@ func (a *App) currentUIFPS() int {
 func (a *App) Start() {
 	a.setupUI()
 	a.window.Show()
-	go a.initializeCamerasAsync()
+	a.core.Start()
 	a.startCameraRefresh()
-	go a.startHotplugDetection()
-	go a.startStaleFrameDetection()
-	go a.startHealthLogging()
 	a.fyneApp.Run()
 }
 
+// snapshot is the button handler for Snapshot; it runs off the UI goroutine.
+func (a *App) snapshot(camIndex int) {
+	paths, err := a.core.Snapshot(camIndex)
+	if err != nil {
+		log.Printf("[UI] Snapshot failed: %v", err)
+		return
+	}
+	log.Printf("[UI] Snapshot saved %d image(s) to %s", len(paths), a.cfg.SnapshotDir)
+}
+
 // TappableImage is an image that can be tapped and long-pressed
 type TappableImage struct {
 	widget.BaseWidget
@@ -245,6 +450,10 @@ type TappableImage struct {
 	bg              *canvas.Rectangle
 	border          *canvas.Rectangle
 	disconnectLabel *canvas.Text
+	roleLabel       *canvas.Text
+	roleBg          *canvas.Rectangle
+	zones           *zoneOverlay
+	guides          *guideOverlay
 	onTap           func()
 	onLongTap       func()
 	pressStart      time.Time
@@ -253,6 +462,7 @@ type TappableImage struct {
 	tapHandled      bool // Prevents double-firing from MouseUp + Tapped
 	highlighted     bool
 	disconnected    bool
+	motion          bool
 	mu              sync.Mutex
 }
 
@@ -261,6 +471,8 @@ func NewTappableImage(img *canvas.Image, bgColor color.Color, onTap, onLongTap f
 		image:     img,
 		bg:        canvas.NewRectangle(bgColor),
 		border:    canvas.NewRectangle(color.Transparent),
+		zones:     newZoneOverlay(),
+		guides:    newGuideOverlay(),
 		onTap:     onTap,
 		onLongTap: onLongTap,
 	}
@@ -273,31 +485,91 @@ func NewTappableImage(img *canvas.Image, bgColor color.Color, onTap, onLongTap f
 	t.disconnectLabel.Alignment = fyne.TextAlignCenter
 	t.disconnectLabel.Hidden = true
 
+	// Create role label in the top-left corner (hidden until set)
+	t.roleLabel = canvas.NewText("", color.RGBA{255, 255, 255, 255})
+	t.roleLabel.TextSize = 16
+	t.roleLabel.TextStyle = fyne.TextStyle{Bold: true}
+	t.roleBg = canvas.NewRectangle(color.RGBA{0, 0, 0, 140})
+	t.roleLabel.Hidden = true
+	t.roleBg.Hidden = true
+
 	t.ExtendBaseWidget(t)
 	return t
 }
 
 func (t *TappableImage) CreateRenderer() fyne.WidgetRenderer {
-	// Stack: bg, image, disconnected label centered, border on top
+	// Stack: bg, image, zone outlines, parking guides, disconnected label centered, role label top-left, border on top
 	labelContainer := container.NewCenter(t.disconnectLabel)
-	c := container.NewStack(t.bg, t.image, labelContainer, t.border)
+	roleContainer := container.NewVBox(container.NewHBox(
+		container.NewStack(t.roleBg, container.NewPadded(t.roleLabel))))
+	c := container.NewStack(t.bg, t.image, t.zones, t.guides, labelContainer, roleContainer, t.border)
 	return widget.NewSimpleRenderer(c)
 }
 
+// SetLabel sets the role label drawn over the image; "" hides it
+func (t *TappableImage) SetLabel(text string) {
+	t.roleLabel.Text = text
+	t.roleLabel.Hidden = text == ""
+	t.roleBg.Hidden = text == ""
+	t.roleLabel.Refresh()
+	t.roleBg.Refresh()
+}
+
 // SetHighlight sets the border highlight for swap mode
 func (t *TappableImage) SetHighlight(on bool) {
 	t.mu.Lock()
 	t.highlighted = on
 	t.mu.Unlock()
+	t.refreshBorder()
+}
 
-	if on {
+// SetMotion shows or hides the red motion border
+func (t *TappableImage) SetMotion(on bool) {
+	t.mu.Lock()
+	t.motion = on
+	t.mu.Unlock()
+	t.refreshBorder()
+}
+
+// SetZones outlines motion detection zones over the image; nil clears them
+func (t *TappableImage) SetZones(zones []motion.Polygon) {
+	t.zones.SetZones(zones)
+}
+
+// SetGuides draws parking guide lines over the image; nil clears them
+func (t *TappableImage) SetGuides(lines []guides.Segment) {
+	t.guides.SetLines(lines)
+}
+
+// refreshBorder draws the swap highlight, else the motion alert
+func (t *TappableImage) refreshBorder() {
+	t.mu.Lock()
+	highlighted, motion := t.highlighted, t.motion
+	t.mu.Unlock()
+
+	switch {
+	case highlighted:
 		t.border.StrokeColor = color.RGBA{255, 200, 0, 255} // Yellow border
-	} else {
+	case motion:
+		t.border.StrokeColor = color.RGBA{230, 30, 30, 255} // Red border
+	default:
 		t.border.StrokeColor = color.Transparent
 	}
 	t.border.Refresh()
 }
 
+// CancelPress drops the pending tap and long press, e.g. when the press
+// turned into a drag on an overlay
+func (t *TappableImage) CancelPress() {
+	t.mu.Lock()
+	if t.longPressTimer != nil {
+		t.longPressTimer.Stop()
+		t.longPressTimer = nil
+	}
+	t.tapHandled = true
+	t.mu.Unlock()
+}
+
 // SetDisconnected shows or hides the "Disconnected" label
 func (t *TappableImage) SetDisconnected(disconnected bool) {
 	t.mu.Lock()
@@ -422,6 +694,7 @@ type TappableSettings struct {
 func NewTappableSettings(
 	onRestart, onExit, onNightModeToggle func(),
 	onBrightnessChange func(int),
+	onSnapshot, onSaveClip func(),
 	onTap, onLongTap func(),
 ) *TappableSettings {
 	t := &TappableSettings{
@@ -470,11 +743,23 @@ func NewTappableSettings(
 	}
 	t.SetBrightnessSelection(defaultBrightnessPercent)
 
+	snapshotBtn := widget.NewButton("Snapshot", func() {
+		if onSnapshot != nil {
+			onSnapshot()
+		}
+	})
+	// Save Clip shares the row and only appears when event clips are enabled
+	actionRow := container.NewGridWithColumns(1, snapshotBtn)
+	if onSaveClip != nil {
+		actionRow = container.NewGridWithColumns(2, snapshotBtn, widget.NewButton("Save Clip", onSaveClip))
+	}
+
 	t.content = container.NewCenter(container.NewVBox(
 		restartBtn,
 		t.nightModeBtn,
 		brightnessLabel,
 		brightnessRow,
+		actionRow,
 		exitBtn,
 	))
 	t.ExtendBaseWidget(t)
@@ -597,7 +882,15 @@ func (a *App) setupUI() {
 	// Dark background
 	background := canvas.NewRectangle(color.RGBA{20, 20, 20, 255})
 
-	// Settings widget with Restart/Night Mode/Brightness/Exit controls and swap support
+	var saveClip func()
+	if a.cfg.ClipsEnabled {
+		saveClip = func() {
+			log.Println("[UI] Save Clip clicked")
+			a.core.SaveClip("", recording.TriggerButton, "settings tile")
+		}
+	}
+
+	// Settings widget with Restart/Night Mode/Brightness/Snapshot/Clip/Exit controls and swap support
 	var settingsWidget *TappableSettings
 	settingsWidget = NewTappableSettings(
 		func() {
@@ -616,17 +909,23 @@ func (a *App) setupUI() {
 			a.setBrightness(percent)
 			settingsWidget.SetBrightnessSelection(percent)
 		},
+		func() {
+			log.Println("[UI] Snapshot clicked")
+			go a.snapshot(-1)
+		},
+		saveClip,
 		func() { a.onWidgetTap(settingsWidget) },
 		func() { a.onWidgetLongPress(settingsWidget) },
 	)
 	settingsWidget.SetBrightnessSelection(a.getBrightnessPercent())
 	a.gridWidgets[0] = settingsWidget
+	a.settingsWidget = settingsWidget
 
 	// Camera widgets with tap handlers
-	gridObjects := make([]fyne.CanvasObject, 0, a.effectiveSlots()+1)
+	gridObjects := make([]fyne.CanvasObject, 0, a.cameraSlots+1)
 	gridObjects = append(gridObjects, settingsWidget)
 
-	for i := 0; i < a.effectiveSlots(); i++ {
+	for i := 0; i < a.cameraSlots; i++ {
 		index := i
 		var camWidget *TappableImage
 		camWidget = NewTappableImage(
@@ -653,13 +952,73 @@ func (a *App) setupUI() {
 	a.fullscreenWidget = NewTappableImage(
 		a.fullscreenImg,
 		color.RGBA{0, 0, 0, 255},
-		func() { a.hideFullscreen() },
+		func() { a.withUI(a.hideFullscreen) },
 		nil,
 	)
 
-	// Fullscreen content (black bg + image)
+	// Snapshot button in the bottom-right corner; taps elsewhere still exit fullscreen
+	fsSnapshotBtn := widget.NewButton("Snapshot", func() {
+		log.Println("[UI] Fullscreen snapshot clicked")
+		go a.snapshot(a.fullscreenCamera())
+	})
+
+	// Zone editor: drag on the image to draw or reshape detection zones
+	a.zoneEditor = newZoneEditor(func(zones []motion.Polygon) {
+		if err := a.core.SetMotionZones(a.fullscreenCamera(), zones); err != nil {
+			log.Printf("[UI] Failed to set zones: %v", err)
+		}
+	})
+	a.zoneEditor.Hide()
+	a.zoneEditBtn = widget.NewButton("Edit Zones", func() {
+		a.withUI(func() {
+			if a.zoneEditing.Load() {
+				a.stopZoneEditing()
+			} else {
+				a.startZoneEditing()
+			}
+		})
+	})
+	a.zoneClearBtn = widget.NewButton("Clear Zones", func() {
+		a.zoneEditor.SetZones(nil)
+		if err := a.core.SetMotionZones(a.fullscreenCamera(), nil); err != nil {
+			log.Printf("[UI] Failed to clear zones: %v", err)
+		}
+	})
+	a.zoneClearBtn.Hide()
+	if !a.cfg.MotionEnabled {
+		a.zoneEditBtn.Hide()
+	}
+
+	// Digital zoom: scroll or the Zoom button zooms, dragging pans. A drag
+	// must not count as the tap that exits fullscreen.
+	a.zoomPad = newZoomPad(func() { a.withUI(a.refreshZoomControls) }, a.fullscreenWidget.CancelPress)
+	a.zoomBtn = widget.NewButton("Zoom", func() { a.zoomPad.Step() })
+	a.zoomResetBtn = widget.NewButton("Reset Zoom", func() {
+		a.withUI(func() {
+			a.zoomPad.Reset()
+			a.refreshZoomControls()
+		})
+	})
+	a.cropKeepBtn = widget.NewButton("Keep as Crop", func() { a.withUI(a.keepZoomAsCrop) })
+	a.cropClearBtn = widget.NewButton("Clear Crop", func() {
+		a.withUI(func() {
+			if err := a.core.SetCrop(a.fullscreenCam, camera.Crop{}, true); err != nil {
+				log.Printf("[UI] Failed to clear crop: %v", err)
+			}
+			a.refreshZoomControls()
+		})
+	})
+	a.zoomResetBtn.Hide()
+	a.cropKeepBtn.Hide()
+	a.cropClearBtn.Hide()
+
+	fsButtons := container.NewHBox(layout.NewSpacer(), a.cropClearBtn, a.cropKeepBtn, a.zoomResetBtn, a.zoomBtn,
+		a.zoneClearBtn, a.zoneEditBtn, fsSnapshotBtn)
+	fsControls := container.NewBorder(nil, fsButtons, nil, nil)
+
+	// Fullscreen content (black bg + image + zoom pad + zone editor + controls)
 	fsBg := canvas.NewRectangle(color.RGBA{0, 0, 0, 255})
-	a.fullscreenContent = container.NewStack(fsBg, a.fullscreenWidget)
+	a.fullscreenContent = container.NewStack(fsBg, a.fullscreenWidget, a.zoomPad, a.zoneEditor, fsControls)
 	a.fullscreenContent.Hide()
 
 	// Grid content
@@ -699,7 +1058,22 @@ func (g *fillGridLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
 	}
 }
 
-// onGridTap handles tap on any grid position (0-3)
+// withUI runs f holding uiMu, so it cannot interleave with touch input,
+// the REST API or a trigger.
+func (a *App) withUI(f func()) {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	f()
+}
+
+// fullscreenCamera returns the camera index shown fullscreen (or last shown).
+func (a *App) fullscreenCamera() int {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	return a.fullscreenCam
+}
+
+// onGridTap handles tap on any grid position (0-3). Callers hold uiMu.
 func (a *App) onGridTap(gridPos int) {
 	if gridPos < 0 || gridPos >= len(a.gridSlots) {
 		return
@@ -713,7 +1087,8 @@ func (a *App) onGridTap(gridPos int) {
 	}
 }
 
-// onGridLongPress handles long-press on any grid position (0-3)
+// onGridLongPress handles long-press on any grid position (0-3). Callers
+// hold uiMu.
 func (a *App) onGridLongPress(gridPos int) {
 	if gridPos < 0 || gridPos >= len(a.gridSlots) {
 		return
@@ -730,7 +1105,8 @@ func (a *App) onGridLongPress(gridPos int) {
 	log.Printf("[UI] Swap mode - selected position %d, tap another to swap", gridPos)
 }
 
-// findWidgetPosition finds the current grid position of a widget
+// findWidgetPosition finds the current grid position of a widget. Callers
+// hold uiMu.
 func (a *App) findWidgetPosition(widget Highlightable) int {
 	for i := 0; i < len(a.gridWidgets); i++ {
 		if a.gridWidgets[i] == widget {
@@ -742,6 +1118,8 @@ func (a *App) findWidgetPosition(widget Highlightable) int {
 
 // onWidgetTap handles tap on a widget, finding its current position dynamically
 func (a *App) onWidgetTap(widget Highlightable) {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
 	gridPos := a.findWidgetPosition(widget)
 	if gridPos < 0 {
 		log.Println("[UI] Widget tap: widget not found in grid")
@@ -752,6 +1130,8 @@ func (a *App) onWidgetTap(widget Highlightable) {
 
 // onWidgetLongPress handles long-press on a widget, finding its current position dynamically
 func (a *App) onWidgetLongPress(widget Highlightable) {
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
 	gridPos := a.findWidgetPosition(widget)
 	if gridPos < 0 {
 		log.Println("[UI] Widget long-press: widget not found in grid")
@@ -760,6 +1140,8 @@ func (a *App) onWidgetLongPress(widget Highlightable) {
 	a.onGridLongPress(gridPos)
 }
 
+// handleSwapTap selects, deselects or swaps with gridPos in swap mode.
+// Callers hold uiMu.
 func (a *App) handleSwapTap(gridPos int) {
 	if a.swapSourceSlot < 0 {
 		a.swapSourceSlot = gridPos
@@ -792,7 +1174,8 @@ func (a *App) handleSwapTap(gridPos int) {
 	}
 }
 
-// swapGridPositions swaps the content assignments of two grid positions
+// swapGridPositions swaps the content assignments of two grid positions.
+// Callers hold uiMu.
 func (a *App) swapGridPositions(pos1, pos2 int) {
 	if pos1 < 0 || pos2 < 0 || pos1 >= len(a.gridSlots) || pos2 >= len(a.gridSlots) {
 		return
@@ -816,6 +1199,8 @@ func (a *App) swapGridPositions(pos1, pos2 int) {
 	a.grid.Refresh()
 }
 
+// showFullscreen shows the camera at gridPos fullscreen, unless a camera
+// already is. Callers hold uiMu.
 func (a *App) showFullscreen(gridPos int) {
 	if a.isFullscreen.Load() {
 		return
@@ -835,17 +1220,21 @@ func (a *App) showFullscreen(gridPos int) {
 
 	// Camera index
 	camIndex := contentType
-	a.frameLock.RLock()
-	camCount := len(a.cameras)
-	a.frameLock.RUnlock()
-	if camIndex >= camCount {
+	cam, ok := a.core.Camera(camIndex)
+	if !ok {
 		log.Printf("[UI] No camera at grid position %d (camera index %d)", gridPos, camIndex)
 		return
 	}
 
 	a.isFullscreen.Store(true)
 	a.fullscreenSlot = gridPos
-	log.Printf("[UI] Fullscreen: camera %d from grid position %d", camIndex, gridPos)
+	a.fullscreenCam = camIndex
+	log.Printf("[UI] Fullscreen: %s from grid position %d", cam.Label(camIndex), gridPos)
+	a.fullscreenWidget.SetLabel(core.DisplayName(cam, camIndex))
+	a.fullscreenWidget.SetMotion(a.core.Motion(camIndex))
+	a.zoomPad.Reset()
+	a.refreshZoomControls()
+	a.drawGuides()
 
 	// Get current frame and set it
 	a.frameLock.RLock()
@@ -853,7 +1242,7 @@ func (a *App) showFullscreen(gridPos int) {
 	a.frameLock.RUnlock()
 
 	if currentFrame != nil {
-		displayFrame := a.applyFullscreenFilters(currentFrame)
+		displayFrame := a.applyFullscreenFilters(camIndex, currentFrame)
 		a.fullscreenImg.Image = displayFrame
 		a.fullscreenImg.Refresh()
 	}
@@ -875,12 +1264,15 @@ func (a *App) showFullscreen(gridPos int) {
 	go a.updateFullscreenLoop(camIndex, stopCh)
 }
 
+// hideFullscreen returns to the grid. Callers hold uiMu.
 func (a *App) hideFullscreen() {
 	if !a.isFullscreen.Load() {
 		return
 	}
 	log.Println("[UI] Exiting fullscreen")
+	a.stopZoneEditing()
 	a.isFullscreen.Store(false)
+	a.zoomPad.Reset()
 
 	// Stop fullscreen update goroutine (mutex prevents double-close)
 	a.fullscreenMu.Lock()
@@ -895,6 +1287,98 @@ func (a *App) hideFullscreen() {
 	a.gridContent.Show()
 }
 
+// startZoneEditing shows the zone editor over the fullscreen camera. Taps
+// on the image no longer exit fullscreen until editing stops. Callers hold
+// uiMu.
+func (a *App) startZoneEditing() {
+	if !a.isFullscreen.Load() || !a.alignedWithCamera(a.fullscreenCam, true) || a.zoneEditing.Swap(true) {
+		return
+	}
+	log.Printf("[UI] Editing zones of camera %d", a.fullscreenCam)
+	// Zones are edited on the whole picture
+	a.zoomPad.Reset()
+	a.zoomPad.Hide()
+	a.refreshZoomControls()
+	a.zoneEditor.SetZones(a.core.MotionZones(a.fullscreenCam))
+	a.zoneEditor.Show()
+	a.zoneClearBtn.Show()
+	a.zoneEditBtn.SetText("Done")
+}
+
+// stopZoneEditing hides the zone editor and logs the zones as a config line,
+// since edits only last until another camera takes the slot. Callers hold
+// uiMu.
+func (a *App) stopZoneEditing() {
+	if !a.zoneEditing.Swap(false) {
+		return
+	}
+	a.zoneEditor.Hide()
+	a.zoneClearBtn.Hide()
+	a.zoneEditBtn.SetText("Edit Zones")
+	a.zoomPad.Show()
+	a.refreshZoomControls()
+
+	label := fmt.Sprintf("camera %d", a.fullscreenCam)
+	if cam, ok := a.core.Camera(a.fullscreenCam); ok {
+		label = cam.Label(a.fullscreenCam)
+	}
+	log.Printf("[UI] Zones of %s edited; to keep them, add to its [camera.<id>] section: zones = %s",
+		label, motion.FormatZones(a.core.MotionZones(a.fullscreenCam)))
+}
+
+// refreshZoomControls shows the zoom, crop and zone buttons that apply to
+// the fullscreen view - Reset Zoom and Keep as Crop while zoomed, Clear
+// Crop when the camera has a crop - and hides the zone outlines while
+// zoomed, since they are drawn for the whole picture. Keep as Crop and the
+// zones also need a picture that lines up with the camera frame (see
+// alignedWithCamera). Callers hold uiMu.
+func (a *App) refreshZoomControls() {
+	editing := a.zoneEditing.Load()
+	zoomed := a.zoomPad.Zoomed()
+	aligned := a.alignedWithCamera(a.fullscreenCam, true)
+	showIf(a.zoomBtn, !editing)
+	showIf(a.zoomResetBtn, !editing && zoomed)
+	showIf(a.cropKeepBtn, !editing && zoomed && aligned)
+	showIf(a.cropClearBtn, !editing && !zoomed && !a.core.Crop(a.fullscreenCam).IsFull())
+	showIf(a.zoneEditBtn, a.cfg.MotionEnabled && (editing || aligned))
+
+	if !a.cfg.MotionShowZones {
+		return
+	}
+	if zoomed || !aligned {
+		a.fullscreenWidget.SetZones(nil)
+	} else {
+		a.fullscreenWidget.SetZones(a.core.MotionZones(a.fullscreenCam))
+	}
+}
+
+// keepZoomAsCrop makes the zoomed view the camera's crop and saves it to
+// the config file. The picture stays the same, now unzoomed. Callers hold
+// uiMu.
+func (a *App) keepZoomAsCrop() {
+	camIndex := a.fullscreenCam
+	if !a.alignedWithCamera(camIndex, true) {
+		log.Printf("[UI] Not keeping the zoom as crop: the filters move pixels, so the view is not a part of the camera frame")
+		return
+	}
+	crop := a.core.Crop(camIndex).Within(a.zoomPad.View())
+	if err := a.core.SetCrop(camIndex, crop, true); err != nil {
+		log.Printf("[UI] Failed to keep zoom as crop: %v", err)
+	}
+	if a.core.Crop(camIndex) == crop {
+		a.zoomPad.Reset()
+	}
+	a.refreshZoomControls()
+}
+
+func showIf(obj fyne.CanvasObject, visible bool) {
+	if visible {
+		obj.Show()
+	} else {
+		obj.Hide()
+	}
+}
+
 func (a *App) updateFullscreenLoop(camIndex int, stopCh chan struct{}) {
 	for {
 		if !a.isFullscreen.Load() {
@@ -909,7 +1393,7 @@ func (a *App) updateFullscreenLoop(camIndex int, stopCh chan struct{}) {
 		a.frameLock.RUnlock()
 
 		if frame != nil && a.fullscreenImg != nil {
-			displayFrame := a.applyFullscreenFilters(frame)
+			displayFrame := a.applyFullscreenFilters(camIndex, frame)
 			a.fullscreenImg.Image = displayFrame
 			a.fullscreenImg.Refresh()
 		}
@@ -927,102 +1411,38 @@ func (a *App) updateFullscreenLoop(camIndex int, stopCh chan struct{}) {
 	}
 }
 
-func (a *App) initializeCamerasAsync() {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("[UI] PANIC in camera init: %v", r)
-		}
-	}()
-
-	log.Println("[UI] Starting camera initialization...")
-
-	// Kill any processes holding camera devices (e.g., stale FFmpeg from previous run)
-	if a.cfg.KillDeviceHolders {
-		maxScan := maxInt(10, a.effectiveSlots()*4+4)
-		for devNum := 0; devNum <= maxScan; devNum += 2 {
-			devPath := fmt.Sprintf("/dev/video%d", devNum)
-			if _, err := os.Stat(devPath); err == nil {
-				helpers.KillDeviceHolders(devPath, true)
-			}
-		}
-	}
-
-	// Use buffer mode for decoupled capture/render with config-driven settings
-	a.manager = camera.NewManagerWithSettings(camera.Settings{
-		Width:      a.cfg.CaptureWidth,
-		Height:     a.cfg.CaptureHeight,
-		FPS:        a.cfg.CaptureFPS,
-		Format:     a.cfg.CaptureFormat,
-		MaxCameras: a.effectiveSlots(),
-	}, true)
-
-	if err := a.manager.Initialize(); err != nil {
-		log.Printf("[UI] Camera init error: %v", err)
-		return
-	}
-	log.Println("[UI] Manager initialized (buffer mode, config-driven settings)")
-
-	if err := a.manager.Start(); err != nil {
-		log.Printf("[UI] Camera start error: %v", err)
-		return
-	}
-
-	cams := a.manager.GetCameras()
-	a.frameLock.Lock()
-	a.cameras = cams
-	a.frameLock.Unlock()
-	for i := 0; i < a.effectiveSlots(); i++ {
-		a.updateCameraStatus(i, false)
-	}
-	log.Printf("[UI] Discovered %d cameras", len(cams))
-	for i, cam := range cams {
-		log.Printf("[UI]   - %s: %s", cam.DeviceID, cam.DevicePath)
-		// Mark camera as connected and update UI
-		if i < a.effectiveSlots() {
-			a.updateCameraStatus(i, true)
-		}
-	}
-
-	a.perfController = perf.NewAdaptiveController(a.manager, a.cfg)
-	a.perfController.Start()
-}
-
 func (a *App) startCameraRefresh() {
 	go func() {
 		frameCounters := make(map[string]uint64)
 
 		for {
 			select {
-			case <-a.hotplugStopCh:
+			case <-a.core.Done():
 				return
 			default:
 			}
 
-			if a.manager == nil {
+			manager := a.core.Manager()
+			if manager == nil {
 				uiFPS := a.currentUIFPS()
 				if uiFPS < 1 {
 					uiFPS = 1
 				}
 				select {
-				case <-a.hotplugStopCh:
+				case <-a.core.Done():
 					return
 				case <-time.After(time.Second / time.Duration(uiFPS)):
 				}
 				continue
 			}
 
-			a.frameLock.RLock()
-			camCount := len(a.cameras)
-			cameras := make([]camera.Camera, camCount)
-			copy(cameras, a.cameras)
-			a.frameLock.RUnlock()
-
-			slotLimit := minInt(a.effectiveSlots(), camCount)
+			cameras := a.core.Cameras()
+			slotLimit := helpers.MinInt(a.cameraSlots, len(cameras))
 			for camIndex := 0; camIndex < slotLimit; camIndex++ {
 				cameraID := cameras[camIndex].DeviceID
 
 				// Try buffer mode first (preferred)
-				buffer := a.manager.GetFrameBuffer(cameraID)
+				buffer := manager.GetFrameBuffer(cameraID)
 				if buffer == nil {
 					continue
 				}
@@ -1035,10 +1455,8 @@ func (a *App) startCameraRefresh() {
 
 				a.lastFrameRead[camIndex] = frameNum
 
-				// Track frame arrival time for stale detection
 				a.frameLock.Lock()
 				a.cameraFrames[camIndex] = frame
-				a.lastFrameTime[camIndex] = time.Now()
 				a.frameLock.Unlock()
 
 				displayFrame := a.applySlotFilters(camIndex, frame)
@@ -1052,8 +1470,8 @@ func (a *App) startCameraRefresh() {
 				if frameCounters[cameraID]%90 == 1 { // Log every 90 frames (~3 sec at 30fps)
 					fps, totalFrames, _ := buffer.GetCaptureStats()
 					droppedCount := buffer.GetDroppedCount()
-					log.Printf("[UI] Camera %s: frame #%d, buffer stats: %d captured, %d dropped, %.1f fps",
-						cameraID, frameCounters[cameraID], totalFrames, droppedCount, fps)
+					log.Printf("[UI] %s: frame #%d, buffer stats: %d captured, %d dropped, %.1f fps",
+						cameras[camIndex].Label(camIndex), frameCounters[cameraID], totalFrames, droppedCount, fps)
 				}
 			}
 
@@ -1062,7 +1480,7 @@ func (a *App) startCameraRefresh() {
 				uiFPS = 1
 			}
 			select {
-			case <-a.hotplugStopCh:
+			case <-a.core.Done():
 				return
 			case <-time.After(time.Second / time.Duration(uiFPS)):
 			}
@@ -1070,27 +1488,6 @@ func (a *App) startCameraRefresh() {
 	}()
 }
 
-// updateCameraStatus updates the connected/disconnected status for a camera slot
-func (a *App) updateCameraStatus(camIndex int, connected bool) {
-	if camIndex < 0 || camIndex >= len(a.cameraStatus) {
-		return
-	}
-
-	a.frameLock.Lock()
-	previousStatus := a.cameraStatus[camIndex]
-	a.cameraStatus[camIndex] = connected
-	a.frameLock.Unlock()
-
-	if previousStatus != connected {
-		log.Printf("[UI] Camera %d status changed: connected=%v", camIndex, connected)
-	}
-
-	// Update the widget UI
-	if a.cameraWidgets[camIndex] != nil {
-		a.cameraWidgets[camIndex].SetDisconnected(!connected)
-	}
-}
-
 // =============================================================================
 // Night Mode
 // =============================================================================
@@ -1119,40 +1516,17 @@ func (a *App) setBrightness(percent int) {
 }
 
 func (a *App) applySlotFilters(camIndex int, frame image.Image) image.Image {
-	if camIndex < 0 || camIndex >= len(a.nightModeBufs) || camIndex >= len(a.brightnessBufs) {
+	if camIndex < 0 || camIndex >= len(a.slotFilters) {
 		return frame
 	}
-	displayFrame := frame
-
-	if a.nightModeEnabled.Load() {
-		a.nightModeBufs[camIndex] = applyNightModeReuse(displayFrame, a.nightModeBufs[camIndex])
-		displayFrame = a.nightModeBufs[camIndex]
-	}
-
-	brightness := a.getBrightnessPercent()
-	if brightness != defaultBrightnessPercent {
-		a.brightnessBufs[camIndex] = applyBrightnessPercentReuse(displayFrame, brightness, a.brightnessBufs[camIndex])
-		displayFrame = a.brightnessBufs[camIndex]
-	}
-
-	return displayFrame
+	return a.filterChain(camIndex, false).Apply(frame)
 }
 
-func (a *App) applyFullscreenFilters(frame image.Image) image.Image {
-	displayFrame := frame
-
-	if a.nightModeEnabled.Load() {
-		a.nightModeFSBuf = applyNightModeReuse(displayFrame, a.nightModeFSBuf)
-		displayFrame = a.nightModeFSBuf
-	}
-
-	brightness := a.getBrightnessPercent()
-	if brightness != defaultBrightnessPercent {
-		a.brightnessFSBuf = applyBrightnessPercentReuse(displayFrame, brightness, a.brightnessFSBuf)
-		displayFrame = a.brightnessFSBuf
+func (a *App) applyFullscreenFilters(camIndex int, frame image.Image) image.Image {
+	if camIndex < 0 || camIndex >= len(a.slotFilters) {
+		return a.zoomPad.View().Apply(frame)
 	}
-
-	return displayFrame
+	return a.filterChain(camIndex, true).Apply(frame)
 }
 
 // toggleNightMode toggles the night mode state and logs the change.
@@ -1166,612 +1540,11 @@ func (a *App) toggleNightMode() {
 	}
 }
 
-// =============================================================================
-// Health Logging
-// =============================================================================
-// Periodic summary of camera health: online, stale, and disconnected counts.
-// Matches Python's log_health_summary() from utils/helpers.py.
-// =============================================================================
-
-// startHealthLogging periodically logs camera health status.
-// Disabled when HealthLogIntervalSec <= 0.
-func (a *App) startHealthLogging() {
-	interval := a.cfg.HealthLogIntervalSec
-	if interval <= 0 {
-		log.Println("[Health] Health logging disabled (interval <= 0)")
-		return
-	}
-
-	log.Printf("[Health] Starting health logging (every %.0fs)...", interval)
-
-	ticker := time.NewTicker(time.Duration(interval * float64(time.Second)))
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-a.hotplugStopCh:
-			return
-		case <-ticker.C:
-			a.logHealthSummary()
-		}
-	}
-}
-
-// logHealthSummary logs the current health status of all camera slots.
-// Counts cameras as online (fresh frame), stale (frame older than threshold),
-// or disconnected (not connected).
-func (a *App) logHealthSummary() {
-	if a.manager == nil {
-		return
-	}
-
-	now := time.Now()
-	staleThreshold := a.cfg.StaleFrameTimeoutSec // H7: use config instead of hardcoded 10.0
-	online := 0
-	stale := 0
-	disconnected := 0
-	totalSlots := a.cfg.CameraSlotCount
-
-	limit := minInt(totalSlots, len(a.cameraStatus))
-	for camIndex := 0; camIndex < limit; camIndex++ {
-		a.frameLock.RLock()
-		connected := a.cameraStatus[camIndex]
-		lastFrame := a.lastFrameTime[camIndex]
-		a.frameLock.RUnlock()
-
-		if !connected {
-			disconnected++
-			continue
-		}
-
-		if lastFrame.IsZero() {
-			// Never received a frame — treat as stale
-			stale++
-			log.Printf("[Health] WARNING: camera %d has never produced a frame", camIndex)
-			continue
-		}
-
-		age := now.Sub(lastFrame).Seconds()
-		if age > staleThreshold {
-			stale++
-			log.Printf("[Health] WARNING: camera %d frame is stale (%.1fs old)", camIndex, age)
-		} else {
-			online++
-		}
-	}
-
-	log.Printf("[Health] cameras online=%d stale=%d disconnected=%d total_slots=%d",
-		online, stale, disconnected, totalSlots)
-}
-
-// =============================================================================
-// Stale Frame Detection + Bounded Auto-Restart
-// =============================================================================
-// Matches Python's _restart_capture_if_stale() policy:
-//   - STALE_FRAME_TIMEOUT_SEC: time before a frame is considered stale
-//   - RESTART_COOLDOWN_SEC: minimum time between restarts for one camera
-//   - MAX_RESTARTS_PER_WINDOW: max restarts allowed in RESTART_WINDOW_SEC
-//   - Extended cooldown (2x window) when limit is reached
-// =============================================================================
-
-// startStaleFrameDetection periodically checks for cameras that have stopped
-// producing frames and restarts their capture workers.
-func (a *App) startStaleFrameDetection() {
-	log.Println("[Stale] Starting stale frame detection...")
-
-	// Check every 500ms for responsiveness
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-a.hotplugStopCh:
-			return
-		case <-ticker.C:
-			a.checkStaleFrames()
-		}
-	}
-}
-
-// checkStaleFrames checks each connected camera for stale frames
-func (a *App) checkStaleFrames() {
-	if a.manager == nil {
-		return
-	}
-
-	a.frameLock.RLock()
-	camCount := len(a.cameras)
-	a.frameLock.RUnlock()
-
-	if camCount == 0 {
-		return
-	}
-
-	now := time.Now()
-	staleTimeout := time.Duration(a.cfg.StaleFrameTimeoutSec * float64(time.Second))
-
-	limit := minInt(a.effectiveSlots(), camCount)
-	for camIndex := 0; camIndex < limit; camIndex++ {
-		a.frameLock.RLock()
-		connected := a.cameraStatus[camIndex]
-		lastFrame := a.lastFrameTime[camIndex]
-		a.frameLock.RUnlock()
-
-		if !connected {
-			continue // Skip disconnected cameras
-		}
-
-		// Skip if we haven't received any frames yet (still initializing)
-		if lastFrame.IsZero() {
-			continue
-		}
-
-		// Check if frame is stale
-		staleDuration := now.Sub(lastFrame)
-		if staleDuration <= staleTimeout {
-			continue // Frame is fresh
-		}
-
-		log.Printf("[Stale] Camera %d: stale frame detected (no frames for %.1fs)",
-			camIndex, staleDuration.Seconds())
-
-		// Mark as disconnected in UI
-		a.updateCameraStatus(camIndex, false)
-
-		// Attempt bounded auto-restart
-		a.restartCaptureIfStale(camIndex)
-	}
-}
-
-// restartCaptureIfStale implements the bounded restart policy matching Python's
-// _restart_capture_if_stale(). Enforces:
-//   - Cooldown between restarts (RESTART_COOLDOWN_SEC)
-//   - Sliding window restart limit (MAX_RESTARTS_PER_WINDOW in RESTART_WINDOW_SEC)
-//   - Extended cooldown (2x window) when limit is reached
-func (a *App) restartCaptureIfStale(camIndex int) {
-	if camIndex < 0 || camIndex >= len(a.lastRestartTime) || camIndex >= len(a.restartEvents) || camIndex >= len(a.restartLimitHit) {
-		return
-	}
-	now := time.Now()
-	cooldown := time.Duration(a.cfg.RestartCooldownSec * float64(time.Second))
-	window := time.Duration(a.cfg.RestartWindowSec * float64(time.Second))
-	extendedCooldown := window * 2
-
-	// Check cooldown
-	if !a.lastRestartTime[camIndex].IsZero() && now.Sub(a.lastRestartTime[camIndex]) < cooldown {
-		return // Still in cooldown
-	}
-
-	// Count recent restarts in the sliding window
-	recentCount := 0
-	for _, t := range a.restartEvents[camIndex] {
-		if now.Sub(t) <= window {
-			recentCount++
-		}
-	}
-
-	if recentCount >= a.cfg.MaxRestartsPerWindow {
-		// Restart limit reached - check extended cooldown
-		if !a.lastRestartTime[camIndex].IsZero() && now.Sub(a.lastRestartTime[camIndex]) < extendedCooldown {
-			if !a.restartLimitHit[camIndex] {
-				log.Printf("[Stale] Camera %d: restart limit reached (%d/%d in %.0fs), will retry in %.0fs",
-					camIndex, recentCount, a.cfg.MaxRestartsPerWindow,
-					a.cfg.RestartWindowSec, extendedCooldown.Seconds())
-				a.restartLimitHit[camIndex] = true
-			}
-			return
-		}
-
-		// Extended cooldown passed - clear events and allow restart
-		log.Printf("[Stale] Camera %d: extended cooldown passed, attempting recovery", camIndex)
-		a.restartEvents[camIndex] = nil
-		a.restartLimitHit[camIndex] = false
-	}
-
-	// Record this restart event
-	a.restartEvents[camIndex] = append(a.restartEvents[camIndex], now)
-	a.lastRestartTime[camIndex] = now
-
-	// Clean up old events outside the window
-	var filtered []time.Time
-	for _, t := range a.restartEvents[camIndex] {
-		if now.Sub(t) <= window*2 { // Keep slightly more history
-			filtered = append(filtered, t)
-		}
-	}
-	a.restartEvents[camIndex] = filtered
-
-	log.Printf("[Stale] Camera %d: restarting capture worker after stale frames", camIndex)
-
-	go func(idx int) {
-		if a.manager == nil {
-			return
-		}
-
-		// Kill any processes holding this camera device before restart
-		a.frameLock.RLock()
-		var devPath string
-		if idx < len(a.cameras) {
-			devPath = a.cameras[idx].DevicePath
-		}
-		a.frameLock.RUnlock()
-		if devPath != "" {
-			helpers.KillDeviceHolders(devPath, a.cfg.KillDeviceHolders)
-		}
-
-		if err := a.manager.RestartCameraByIndex(idx); err != nil {
-			log.Printf("[Stale] Camera %d: failed to restart: %v", idx, err)
-			return
-		}
-
-		// Reset frame time so we don't immediately re-trigger
-		a.frameLock.Lock()
-		a.lastFrameTime[idx] = time.Now()
-		a.frameLock.Unlock()
-
-		// Mark as connected again
-		a.updateCameraStatus(idx, true)
-		log.Printf("[Stale] Camera %d: successfully restarted", idx)
-	}(camIndex)
-}
-
-// startHotplugDetection starts a goroutine that polls for camera connect/disconnect
-func (a *App) startHotplugDetection() {
-	log.Println("[Hotplug] Starting camera hot-plug detection...")
-
-	interval := time.Duration(a.cfg.RescanIntervalMS) * time.Millisecond
-	if interval < 500*time.Millisecond {
-		interval = 500 * time.Millisecond
-	}
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-a.hotplugStopCh:
-			log.Println("[Hotplug] Stopping hot-plug detection")
-			return
-		case <-ticker.C:
-			a.checkCameraChanges()
-		}
-	}
-}
-
-// checkCameraChanges polls for camera connect/disconnect events
-func (a *App) checkCameraChanges() {
-	// Simple check: just verify device files exist (don't use v4l2-ctl to avoid conflicts with FFmpeg)
-	// Check for disconnections in our existing cameras
-	a.frameLock.RLock()
-	cameras := make([]camera.Camera, len(a.cameras))
-	copy(cameras, a.cameras)
-	statusSnapshot := make([]bool, len(a.cameraStatus))
-	copy(statusSnapshot, a.cameraStatus)
-	a.frameLock.RUnlock()
-
-	limit := minInt(len(cameras), len(statusSnapshot))
-	for i := 0; i < limit; i++ {
-		cam := cameras[i]
-
-		// Check if device file still exists
-		_, err := os.Stat(cam.DevicePath)
-		deviceExists := err == nil
-
-		wasConnected := statusSnapshot[i]
-
-		if wasConnected && !deviceExists {
-			// Camera disconnected - record time for debouncing
-			a.reinitLock.Lock()
-			a.lastDisconnectTime[i] = time.Now()
-			a.reinitLock.Unlock()
-			log.Printf("[Hotplug] Camera %d (%s) disconnected", i, cam.DevicePath)
-			a.updateCameraStatus(i, false)
-		} else if !wasConnected && deviceExists {
-			// Camera reconnected
-			log.Printf("[Hotplug] Camera %d (%s) reconnected", i, cam.DevicePath)
-			a.handleCameraReconnect(i)
-		}
-	}
-
-	// Check for new cameras at common device paths
-	a.checkForNewCameras()
-}
-
-// checkForNewCameras looks for new cameras dynamically
-func (a *App) checkForNewCameras() {
-	// Skip if reinit is already in progress
-	a.reinitLock.Lock()
-	if a.reinitInProgress {
-		a.reinitLock.Unlock()
-		return
-	}
-	a.reinitLock.Unlock()
-
-	// Only check if we have empty slots.
-	connectedCount := 0
-	a.frameLock.RLock()
-	camCount := len(a.cameras)
-	for i := 0; i < a.effectiveSlots(); i++ {
-		if i < camCount && a.cameraStatus[i] {
-			connectedCount++
-		}
-	}
-	a.frameLock.RUnlock()
-	if connectedCount >= a.effectiveSlots() {
-		return // All slots full
-	}
-
-	// Build set of existing device paths we're already tracking
-	existingPaths := make(map[string]bool)
-	a.frameLock.RLock()
-	for _, cam := range a.cameras {
-		existingPaths[cam.DevicePath] = true
-	}
-	a.frameLock.RUnlock()
-
-	cooldown := time.Duration(a.cfg.FailedCameraCooldownS * float64(time.Second))
-	if cooldown < time.Second {
-		cooldown = time.Second
-	}
-	now := time.Now()
-	maxScan := maxInt(10, a.effectiveSlots()*4+4)
-
-	// Scan /dev/video* for potential new USB cameras.
-	for i := 0; i <= maxScan; i += 2 {
-		devPath := fmt.Sprintf("/dev/video%d", i)
-
-		if existingPaths[devPath] {
-			continue // Already tracking this device
-		}
-		if last, ok := a.failedNewDevice[devPath]; ok && now.Sub(last) < cooldown {
-			continue
-		}
-
-		// Check if device exists
-		if _, err := os.Stat(devPath); err == nil {
-			// Verify it's a USB camera by checking if it's a capture device
-			if a.isUSBCaptureDevice(devPath, existingPaths) {
-				log.Printf("[Hotplug] New USB camera detected at %s", devPath)
-				a.failedNewDevice[devPath] = now
-				a.handleNewCameraDevice(devPath)
-				return // Only handle one at a time
-			}
-		}
-	}
-}
-
-// getUSBParent returns the sysfs USB parent path for a /dev/videoX device.
-// Two video nodes from the same physical USB camera share the same parent.
-// Returns "" if the parent cannot be determined.
-func getUSBParent(devPath string) string {
-	var videoNum int
-	_, err := fmt.Sscanf(devPath, "/dev/video%d", &videoNum)
-	if err != nil {
-		return ""
-	}
-	// Resolve the physical device symlink and go up one level to the USB device
-	symlinkPath := fmt.Sprintf("/sys/class/video4linux/video%d/device", videoNum)
-	resolved, err := filepath.EvalSymlinks(symlinkPath)
-	if err != nil {
-		return ""
-	}
-	return filepath.Dir(resolved)
-}
-
-// isUSBCaptureDevice checks if a device path is a USB video capture device
-// that is NOT a secondary node of an already-tracked camera.
-// Uses sysfs instead of v4l2-ctl to avoid conflicts with active FFmpeg capture.
-func (a *App) isUSBCaptureDevice(devPath string, existingPaths map[string]bool) bool {
-	// Extract video number from path (e.g., /dev/video0 -> 0)
-	var videoNum int
-	_, err := fmt.Sscanf(devPath, "/dev/video%d", &videoNum)
-	if err != nil {
-		return false
-	}
-
-	// Check sysfs for device type - USB capture devices have specific characteristics
-	// USB cameras typically create even-numbered video devices (video0, video2, video4)
-	// Odd numbers are usually metadata devices
-	if videoNum%2 != 0 {
-		return false // Skip odd-numbered devices (metadata)
-	}
-
-	// Check if it's a capture device by looking at sysfs
-	sysfsPath := fmt.Sprintf("/sys/class/video4linux/video%d/device/modalias", videoNum)
-	data, err := os.ReadFile(sysfsPath)
-	if err != nil {
-		return false
-	}
-
-	// USB devices have modalias starting with "usb:"
-	if !strings.HasPrefix(string(data), "usb:") {
-		return false
-	}
-
-	// Reject secondary nodes that share a USB parent with an already-tracked camera.
-	// Multi-function USB cameras (e.g. UVC webcams) register multiple /dev/videoX nodes
-	// under the same physical USB device. Only the primary capture node (typically the
-	// lowest-numbered) should be treated as a camera.
-	candidateParent := getUSBParent(devPath)
-	if candidateParent == "" {
-		return false
-	}
-	for existingPath := range existingPaths {
-		if getUSBParent(existingPath) == candidateParent {
-			return false // Same physical device as an already-tracked camera
-		}
-	}
-
-	return true
-}
-
-// handleNewCameraDevice handles a newly detected camera device
-func (a *App) handleNewCameraDevice(devPath string) {
-	a.reinitLock.Lock()
-	if a.reinitInProgress {
-		a.reinitLock.Unlock()
-		log.Printf("[Hotplug] Reinit already in progress, skipping new camera %s", devPath)
-		return
-	}
-	a.reinitInProgress = true
-	a.reinitLock.Unlock()
-
-	// Find an empty/disconnected slot
-	emptySlot := -1
-	a.frameLock.RLock()
-	for i := 0; i < a.effectiveSlots(); i++ {
-		if i >= len(a.cameras) || !a.cameraStatus[i] {
-			emptySlot = i
-			break
-		}
-	}
-	a.frameLock.RUnlock()
-
-	if emptySlot < 0 {
-		log.Printf("[Hotplug] New camera detected (%s) but no empty slots available", devPath)
-		a.reinitLock.Lock()
-		a.reinitInProgress = false
-		a.reinitLock.Unlock()
-		return
-	}
-
-	log.Printf("[Hotplug] Assigning new camera (%s) to slot %d", devPath, emptySlot)
-
-	go func() {
-		defer func() {
-			a.reinitLock.Lock()
-			a.reinitInProgress = false
-			a.reinitLock.Unlock()
-		}()
-
-		// Let device settle
-		time.Sleep(1500 * time.Millisecond)
-
-		// Stop existing manager and wait for cleanup
-		if a.manager != nil {
-			a.manager.Stop()
-			time.Sleep(500 * time.Millisecond)
-		}
-
-		// Use buffer mode for decoupled capture/render with config-driven settings
-		a.manager = camera.NewManagerWithSettings(camera.Settings{
-			Width:      a.cfg.CaptureWidth,
-			Height:     a.cfg.CaptureHeight,
-			FPS:        a.cfg.CaptureFPS,
-			Format:     a.cfg.CaptureFormat,
-			MaxCameras: a.effectiveSlots(),
-		}, true)
-		if err := a.manager.Initialize(); err != nil {
-			log.Printf("[Hotplug] Failed to reinitialize manager: %v", err)
-			return
-		}
-		if err := a.manager.Start(); err != nil {
-			log.Printf("[Hotplug] Failed to start manager: %v", err)
-			return
-		}
-
-		cams := a.manager.GetCameras()
-		a.frameLock.Lock()
-		a.cameras = cams
-		a.frameLock.Unlock()
-		for i := 0; i < a.effectiveSlots(); i++ {
-			a.updateCameraStatus(i, false)
-		}
-		log.Printf("[Hotplug] Reinitialized with %d cameras", len(cams))
-
-		for i := range cams {
-			if i < a.effectiveSlots() {
-				a.updateCameraStatus(i, true)
-			}
-		}
-	}()
-}
-
-// handleCameraReconnect handles a camera that was disconnected and is now reconnected
-// Uses per-camera restart to avoid disrupting other cameras
-func (a *App) handleCameraReconnect(camIndex int) {
-	// Debounce reconnect checks to avoid flapping on unstable USB links.
-	debounce := defaultReconnectDebounce
-	if cfgDelay := time.Duration(a.cfg.FailedCameraCooldownS * float64(time.Second)); cfgDelay > 0 && cfgDelay < debounce {
-		debounce = cfgDelay
-	}
-
-	a.reinitLock.Lock()
-	if camIndex < 0 || camIndex >= len(a.lastDisconnectTime) {
-		a.reinitLock.Unlock()
-		return
-	}
-	timeSinceDisconnect := time.Since(a.lastDisconnectTime[camIndex])
-	if timeSinceDisconnect < debounce {
-		a.reinitLock.Unlock()
-		log.Printf("[Hotplug] Camera %d: Ignoring reconnect (%.1fs since disconnect, need %.1fs debounce)",
-			camIndex, timeSinceDisconnect.Seconds(), debounce.Seconds())
-		return
-	}
-
-	if a.reinitInProgress {
-		a.reinitLock.Unlock()
-		log.Printf("[Hotplug] Reinit already in progress, skipping reconnect for camera %d", camIndex)
-		return
-	}
-	a.reinitInProgress = true
-	a.reinitLock.Unlock()
-
-	log.Printf("[Hotplug] Camera %d: Attempting per-camera restart (other cameras unaffected)...", camIndex)
-
-	go func() {
-		defer func() {
-			a.reinitLock.Lock()
-			a.reinitInProgress = false
-			a.reinitLock.Unlock()
-		}()
-
-		// Let the device settle after reconnection
-		time.Sleep(1500 * time.Millisecond)
-
-		// Kill any stale processes holding the device before restart
-		a.frameLock.RLock()
-		var devPath string
-		if camIndex < len(a.cameras) {
-			devPath = a.cameras[camIndex].DevicePath
-		}
-		a.frameLock.RUnlock()
-		if devPath != "" {
-			helpers.KillDeviceHolders(devPath, a.cfg.KillDeviceHolders)
-		}
-
-		// Restart only this camera's worker
-		if a.manager != nil {
-			if err := a.manager.RestartCameraByIndex(camIndex); err != nil {
-				log.Printf("[Hotplug] Camera %d: Failed to restart: %v", camIndex, err)
-				return
-			}
-		}
-
-		// Mark camera as connected
-		a.updateCameraStatus(camIndex, true)
-		log.Printf("[Hotplug] Camera %d: Successfully restarted", camIndex)
-	}()
-}
-
 // cleanup stops all processes and exits cleanly
 func (a *App) cleanup() {
 	a.cleanupOnce.Do(func() {
 		log.Println("[UI] Cleanup: stopping all processes...")
-
-		// Stop hot-plug detection
-		close(a.hotplugStopCh)
-
-		// Stop performance controller
-		if a.perfController != nil {
-			a.perfController.Stop()
-		}
-
-		// Stop camera manager (kills FFmpeg processes)
-		if a.manager != nil {
-			a.manager.Stop()
-			log.Println("[UI] Cleanup: stopped camera manager")
-		}
-
+		a.core.Stop()
 		log.Println("[UI] Cleanup: complete, exiting...")
 		a.fyneApp.Quit()
 	})
@@ -1780,43 +1553,8 @@ func (a *App) cleanup() {
 // restart stops all processes and restarts the application
 func (a *App) restart() {
 	log.Println("[UI] Restart: stopping all processes...")
-
-	// Stop performance controller
-	if a.perfController != nil {
-		a.perfController.Stop()
-	}
-
-	// Stop camera manager
-	if a.manager != nil {
-		a.manager.Stop()
-	}
-
-	// Stop all background goroutines (hotplug, stale detection, health, refresh)
-	a.cleanupOnce.Do(func() {
-		close(a.hotplugStopCh)
-	})
-
-	log.Println("[UI] Restart: relaunching application...")
-
-	// Get the path to the current executable
-	executable, err := os.Executable()
-	if err != nil {
-		log.Printf("[UI] Restart: failed to get executable path: %v", err)
-		return
-	}
-
-	// Launch new instance
-	cmd := exec.Command(executable)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = os.Environ()
-
-	if err := cmd.Start(); err != nil {
-		log.Printf("[UI] Restart: failed to start new instance: %v", err)
-		return
-	}
-
-	log.Println("[UI] Restart: new instance started, exiting current...")
+	a.core.Restart()
+	log.Println("[UI] Restart: exiting current instance...")
 	a.fyneApp.Quit()
 }
 
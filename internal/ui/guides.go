@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"camera-dashboard-go/internal/guides"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+	"image/color"
+	"sync"
+)
+
+// guideBandColors are the distance band colors, from the vehicle outwards.
+var guideBandColors = [...]color.Color{
+	guides.BandNear: color.RGBA{230, 30, 30, 255}, // Red
+	guides.BandMid:  color.RGBA{255, 200, 0, 255}, // Yellow
+	guides.BandFar:  color.RGBA{40, 200, 60, 255}, // Green
+}
+
+// guideOverlay draws parking guide lines over a camera image as canvas
+// lines, so the guides cost nothing per frame. Like zoneOverlay it takes
+// no input and scales normalized coordinates to its size.
+type guideOverlay struct {
+	widget.BaseWidget
+	mu    sync.Mutex
+	lines []guides.Segment
+}
+
+func newGuideOverlay() *guideOverlay {
+	o := &guideOverlay{}
+	o.ExtendBaseWidget(o)
+	return o
+}
+
+// SetLines replaces the drawn guide lines; nil draws nothing
+func (o *guideOverlay) SetLines(lines []guides.Segment) {
+	o.mu.Lock()
+	o.lines = append([]guides.Segment(nil), lines...)
+	o.mu.Unlock()
+	o.Refresh()
+}
+
+func (o *guideOverlay) CreateRenderer() fyne.WidgetRenderer {
+	return &guideRenderer{overlay: o}
+}
+
+// guideRenderer reuses its canvas lines; a steering change only moves them.
+type guideRenderer struct {
+	overlay *guideOverlay
+	size    fyne.Size
+	lines   []*canvas.Line
+	objects []fyne.CanvasObject
+}
+
+func (r *guideRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.update()
+}
+
+func (r *guideRenderer) MinSize() fyne.Size { return fyne.NewSize(0, 0) }
+
+func (r *guideRenderer) Refresh() {
+	r.update()
+	canvas.Refresh(r.overlay)
+}
+
+func (r *guideRenderer) Objects() []fyne.CanvasObject { return r.objects }
+
+func (r *guideRenderer) Destroy() {}
+
+func (r *guideRenderer) update() {
+	r.overlay.mu.Lock()
+	segs := r.overlay.lines
+	r.overlay.mu.Unlock()
+
+	for len(r.lines) < len(segs) {
+		line := canvas.NewLine(color.Transparent)
+		line.StrokeWidth = 3
+		r.lines = append(r.lines, line)
+	}
+	r.lines = r.lines[:len(segs)]
+	r.objects = r.objects[:0]
+	for i, seg := range segs {
+		band := seg.Band
+		if band >= len(guideBandColors) {
+			band = len(guideBandColors) - 1
+		}
+		line := r.lines[i]
+		line.StrokeColor = guideBandColors[band]
+		line.Position1 = fyne.NewPos(float32(seg.X1)*r.size.Width, float32(seg.Y1)*r.size.Height)
+		line.Position2 = fyne.NewPos(float32(seg.X2)*r.size.Width, float32(seg.Y2)*r.size.Height)
+		r.objects = append(r.objects, line)
+	}
+}
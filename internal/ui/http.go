@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"camera-dashboard-go/internal/core"
+	"camera-dashboard-go/internal/server"
+	"fmt"
+)
+
+// apiController adds the settings-tile display actions to the service's
+// REST API, using the same App methods as the touch screen. Its methods run
+// on HTTP handler goroutines, so the grid and fullscreen actions take uiMu
+// like touch input does.
+type apiController struct {
+	core.Controller
+	a *App
+}
+
+func (c apiController) State() server.State {
+	a := c.a
+	st := c.Controller.State()
+	st.NightMode = a.nightModeEnabled.Load()
+	st.Brightness = a.getBrightnessPercent()
+
+	a.uiMu.Lock()
+	defer a.uiMu.Unlock()
+	st.Grid = append([]int(nil), a.gridSlots...)
+	if a.isFullscreen.Load() {
+		st.Fullscreen = a.fullscreenSlot
+	}
+	return st
+}
+
+func (c apiController) Restart() { c.a.restart() }
+
+func (c apiController) Exit() { c.a.cleanup() }
+
+func (c apiController) SetNightMode(enabled bool) {
+	if c.a.nightModeEnabled.Load() != enabled {
+		c.a.toggleNightMode()
+	}
+	if c.a.settingsWidget != nil {
+		c.a.settingsWidget.SetNightModeLabel(enabled)
+	}
+}
+
+func (c apiController) SetBrightness(percent int) error {
+	c.a.setBrightness(percent)
+	if c.a.getBrightnessPercent() != percent {
+		return fmt.Errorf("unsupported brightness %d%% (presets: 15, 60, 80, 100, 150)", percent)
+	}
+	if c.a.settingsWidget != nil {
+		c.a.settingsWidget.SetBrightnessSelection(percent)
+	}
+	return nil
+}
+
+func (c apiController) Swap(pos1, pos2 int) error {
+	c.a.uiMu.Lock()
+	defer c.a.uiMu.Unlock()
+	n := len(c.a.gridSlots)
+	if pos1 < 0 || pos2 < 0 || pos1 >= n || pos2 >= n || pos1 == pos2 {
+		return fmt.Errorf("invalid grid positions %d and %d (grid has %d)", pos1, pos2, n)
+	}
+	c.a.swapGridPositions(pos1, pos2)
+	return nil
+}
+
+func (c apiController) ShowFullscreen(gridPos int) error {
+	c.a.uiMu.Lock()
+	defer c.a.uiMu.Unlock()
+	if gridPos < 0 || gridPos >= len(c.a.gridSlots) {
+		return fmt.Errorf("grid position %d out of range", gridPos)
+	}
+	if c.a.gridSlots[gridPos] == -1 {
+		return fmt.Errorf("grid position %d is the settings tile", gridPos)
+	}
+	// Switch cameras when another one is already fullscreen
+	c.a.hideFullscreen()
+	c.a.showFullscreen(gridPos)
+	if !c.a.isFullscreen.Load() {
+		return fmt.Errorf("no camera at grid position %d", gridPos)
+	}
+	return nil
+}
+
+func (c apiController) HideFullscreen() { c.a.withUI(c.a.hideFullscreen) }
@@ -0,0 +1,257 @@
+package ui
+
+import (
+	"camera-dashboard-go/internal/motion"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+	"image/color"
+	"math"
+	"sync"
+)
+
+// =============================================================================
+// Motion Detection Zones
+// =============================================================================
+// zoneOverlay draws the outlines of a camera's detection zones over its
+// image. Zones are in normalized coordinates and camera images are
+// stretched to fill their widget, so scaling by the widget size lines the
+// outlines up with the picture. zoneEditor adds dragging on top for the
+// fullscreen view: drag a corner to move it, or drag across empty space to
+// add a rectangular zone.
+// =============================================================================
+
+const (
+	zoneHandleRadius = 10 // Drawn vertex handle radius (editor)
+	zoneGrabDistance = 32 // Max distance from a vertex to start moving it
+	zoneMinDragSize  = 16 // Smaller new rectangles are discarded
+)
+
+var (
+	zoneLineColor   = color.RGBA{0, 200, 255, 255}
+	zoneHandleColor = color.RGBA{255, 255, 255, 220}
+)
+
+// zoneOverlay draws zone outlines; it takes no input, so taps reach the
+// widget below.
+type zoneOverlay struct {
+	widget.BaseWidget
+	mu      sync.Mutex
+	zones   []motion.Polygon
+	handles bool // Draw vertex handles
+}
+
+func newZoneOverlay() *zoneOverlay {
+	o := &zoneOverlay{}
+	o.ExtendBaseWidget(o)
+	return o
+}
+
+// SetZones replaces the drawn zones; nil draws nothing
+func (o *zoneOverlay) SetZones(zones []motion.Polygon) {
+	o.mu.Lock()
+	o.zones = copyZones(zones)
+	o.mu.Unlock()
+	o.Refresh()
+}
+
+// Zones returns a copy of the drawn zones
+func (o *zoneOverlay) Zones() []motion.Polygon {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return copyZones(o.zones)
+}
+
+func (o *zoneOverlay) CreateRenderer() fyne.WidgetRenderer {
+	return &zoneRenderer{overlay: o}
+}
+
+// zoneRenderer rebuilds its lines on every layout or refresh; zones change
+// rarely and have a handful of points.
+type zoneRenderer struct {
+	overlay *zoneOverlay
+	size    fyne.Size
+	objects []fyne.CanvasObject
+}
+
+func (r *zoneRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.build()
+}
+
+func (r *zoneRenderer) MinSize() fyne.Size { return fyne.NewSize(0, 0) }
+
+func (r *zoneRenderer) Refresh() {
+	r.build()
+	canvas.Refresh(r.overlay)
+}
+
+func (r *zoneRenderer) Objects() []fyne.CanvasObject { return r.objects }
+
+func (r *zoneRenderer) Destroy() {}
+
+func (r *zoneRenderer) build() {
+	o := r.overlay
+	o.mu.Lock()
+	zones, handles := o.zones, o.handles
+	o.mu.Unlock()
+
+	objects := make([]fyne.CanvasObject, 0, len(zones)*8)
+	for _, poly := range zones {
+		for i, pt := range poly {
+			next := poly[(i+1)%len(poly)]
+			line := canvas.NewLine(zoneLineColor)
+			line.StrokeWidth = 2
+			line.Position1 = toScreen(pt, r.size)
+			line.Position2 = toScreen(next, r.size)
+			objects = append(objects, line)
+		}
+	}
+	if handles {
+		for _, poly := range zones {
+			for _, pt := range poly {
+				dot := canvas.NewCircle(zoneHandleColor)
+				dot.StrokeColor = zoneLineColor
+				dot.StrokeWidth = 2
+				dot.Resize(fyne.NewSize(2*zoneHandleRadius, 2*zoneHandleRadius))
+				dot.Move(toScreen(pt, r.size).SubtractXY(zoneHandleRadius, zoneHandleRadius))
+				objects = append(objects, dot)
+			}
+		}
+	}
+	r.objects = objects
+}
+
+// zoneEditor edits zones by dragging over a zoneOverlay with handles. It
+// swallows taps so touching the image while editing does not leave
+// fullscreen.
+type zoneEditor struct {
+	widget.BaseWidget
+	overlay  *zoneOverlay
+	onChange func(zones []motion.Polygon) // Called after each drag
+
+	// Drag state, only touched from input events
+	dragging bool
+	zones    []motion.Polygon
+	zone     int // Zone being edited
+	vertex   int // Vertex being moved; -1 while drawing a new rectangle
+	start    motion.Point
+}
+
+func newZoneEditor(onChange func(zones []motion.Polygon)) *zoneEditor {
+	e := &zoneEditor{overlay: newZoneOverlay(), onChange: onChange}
+	e.overlay.handles = true
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *zoneEditor) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(e.overlay)
+}
+
+// SetZones replaces the edited zones
+func (e *zoneEditor) SetZones(zones []motion.Polygon) { e.overlay.SetZones(zones) }
+
+// Zones returns a copy of the edited zones
+func (e *zoneEditor) Zones() []motion.Polygon { return e.overlay.Zones() }
+
+// Tapped ignores taps
+func (e *zoneEditor) Tapped(_ *fyne.PointEvent) {}
+
+// Dragged moves the grabbed vertex, or stretches the new rectangle
+func (e *zoneEditor) Dragged(ev *fyne.DragEvent) {
+	size := e.Size()
+	if size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+	cur := toNormalized(ev.Position, size)
+
+	if !e.dragging {
+		e.dragging = true
+		e.zones = e.overlay.Zones()
+		startPos := ev.Position.Subtract(ev.Dragged)
+		e.zone, e.vertex = nearestVertex(e.zones, startPos, size)
+		if e.vertex < 0 {
+			e.start = toNormalized(startPos, size)
+			e.zones = append(e.zones, nil)
+			e.zone = len(e.zones) - 1
+		}
+	}
+	if e.vertex >= 0 {
+		e.zones[e.zone][e.vertex] = cur
+	} else {
+		s := e.start
+		e.zones[e.zone] = motion.Polygon{{X: s.X, Y: s.Y}, {X: cur.X, Y: s.Y}, {X: cur.X, Y: cur.Y}, {X: s.X, Y: cur.Y}}
+	}
+	e.overlay.SetZones(e.zones)
+}
+
+// DragEnd finishes the drag and reports the new zones
+func (e *zoneEditor) DragEnd() {
+	if !e.dragging {
+		return
+	}
+	e.dragging = false
+	if e.vertex < 0 {
+		// Discard rectangles too small to have been meant
+		size := e.Size()
+		r := e.zones[e.zone]
+		w := math.Abs(r[2].X-r[0].X) * float64(size.Width)
+		h := math.Abs(r[2].Y-r[0].Y) * float64(size.Height)
+		if w < zoneMinDragSize || h < zoneMinDragSize {
+			e.zones = e.zones[:e.zone]
+		}
+	}
+	e.overlay.SetZones(e.zones)
+	if e.onChange != nil {
+		e.onChange(e.overlay.Zones())
+	}
+}
+
+// nearestVertex returns the zone and vertex closest to pos within
+// zoneGrabDistance, or -1, -1.
+func nearestVertex(zones []motion.Polygon, pos fyne.Position, size fyne.Size) (int, int) {
+	bestZone, bestVertex := -1, -1
+	best := float32(zoneGrabDistance * zoneGrabDistance)
+	for zi, poly := range zones {
+		for vi, pt := range poly {
+			p := toScreen(pt, size)
+			dx, dy := p.X-pos.X, p.Y-pos.Y
+			if d := dx*dx + dy*dy; d <= best {
+				best, bestZone, bestVertex = d, zi, vi
+			}
+		}
+	}
+	return bestZone, bestVertex
+}
+
+func toScreen(pt motion.Point, size fyne.Size) fyne.Position {
+	return fyne.NewPos(float32(pt.X)*size.Width, float32(pt.Y)*size.Height)
+}
+
+// toNormalized converts a widget position to zone coordinates, clamped to
+// the frame.
+func toNormalized(pos fyne.Position, size fyne.Size) motion.Point {
+	return motion.Point{
+		X: clamp01(float64(pos.X / size.Width)),
+		Y: clamp01(float64(pos.Y / size.Height)),
+	}
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func copyZones(zones []motion.Polygon) []motion.Polygon {
+	var cp []motion.Polygon
+	for _, poly := range zones {
+		cp = append(cp, append(motion.Polygon(nil), poly...))
+	}
+	return cp
+}
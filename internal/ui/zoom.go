@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"camera-dashboard-go/internal/camera"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+	"image/color"
+	"sync"
+)
+
+// =============================================================================
+// Fullscreen Digital Zoom
+// =============================================================================
+// zoomPad lies over the fullscreen image: the scroll wheel zooms around the
+// pointer, dragging pans, and the Zoom button steps through zoomLevels. The
+// view is a camera.Crop of the displayed frame, cut with SubImage before the
+// fullscreen filters, so zooming costs no copy. The fullscreen image is
+// stretched to the widget, so widget positions map linearly onto the view.
+// =============================================================================
+
+const (
+	maxZoom        = 8.0
+	zoomScrollStep = 1.25 // Zoom factor per scroll notch
+)
+
+// zoomLevels are the steps of the Zoom button; after the last it resets.
+var zoomLevels = []float64{1.5, 2, 3, 4}
+
+// zoomPad tracks the zoomed view. It only takes scroll and drag input, so
+// taps reach the fullscreen image below.
+type zoomPad struct {
+	widget.BaseWidget
+	bg       *canvas.Rectangle
+	onChange func() // Called after the zoom changed (not on pans)
+	onDrag   func() // Called when a drag starts, e.g. to cancel a pending tap
+	dragging bool   // Only touched from input events
+
+	mu   sync.Mutex // Protects zoom, x, y; the view is read by the frame loop
+	zoom float64    // 1 = whole frame
+	x, y float64    // Top-left corner of the view (normalized)
+}
+
+func newZoomPad(onChange, onDrag func()) *zoomPad {
+	p := &zoomPad{onChange: onChange, onDrag: onDrag, zoom: 1, bg: canvas.NewRectangle(color.Transparent)}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+func (p *zoomPad) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(p.bg)
+}
+
+// View returns the shown part of the frame; the zero value is all of it.
+func (p *zoomPad) View() camera.Crop {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.zoom <= 1 {
+		return camera.Crop{}
+	}
+	return camera.Crop{X: p.x, Y: p.y, W: 1 / p.zoom, H: 1 / p.zoom}
+}
+
+// Zoomed reports whether the view is smaller than the frame.
+func (p *zoomPad) Zoomed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.zoom > 1
+}
+
+// Reset shows the whole frame again. Unlike zooming by input it does not
+// call onChange: the App resets while it holds the lock onChange takes, and
+// refreshes the controls itself.
+func (p *zoomPad) Reset() {
+	p.mu.Lock()
+	p.zoom, p.x, p.y = 1, 0, 0
+	p.mu.Unlock()
+}
+
+// Step zooms to the next of zoomLevels around the view centre, or resets
+// after the last.
+func (p *zoomPad) Step() {
+	p.mu.Lock()
+	next := 1.0
+	for _, level := range zoomLevels {
+		if level > p.zoom+1e-9 {
+			next = level
+			break
+		}
+	}
+	p.mu.Unlock()
+	if next == 1 {
+		p.Reset()
+		if p.onChange != nil {
+			p.onChange()
+		}
+		return
+	}
+	p.zoomTo(next, 0.5, 0.5)
+}
+
+// zoomTo sets the zoom, keeping the frame point at widget fraction (fx, fy)
+// in place.
+func (p *zoomPad) zoomTo(zoom, fx, fy float64) {
+	if zoom < 1 {
+		zoom = 1
+	}
+	if zoom > maxZoom {
+		zoom = maxZoom
+	}
+	p.mu.Lock()
+	w := 1 / p.zoom
+	px, py := p.x+fx*w, p.y+fy*w // Frame point under (fx, fy)
+	p.zoom = zoom
+	w = 1 / zoom
+	p.x, p.y = clampView(px-fx*w, w), clampView(py-fy*w, w)
+	p.mu.Unlock()
+	if p.onChange != nil {
+		p.onChange()
+	}
+}
+
+// Scrolled zooms in (scroll up) or out around the pointer.
+func (p *zoomPad) Scrolled(ev *fyne.ScrollEvent) {
+	size := p.Size()
+	if size.Width <= 0 || size.Height <= 0 || ev.Scrolled.DY == 0 {
+		return
+	}
+	p.mu.Lock()
+	zoom := p.zoom
+	p.mu.Unlock()
+	if ev.Scrolled.DY > 0 {
+		zoom *= zoomScrollStep
+	} else {
+		zoom /= zoomScrollStep
+	}
+	p.zoomTo(zoom, float64(ev.Position.X/size.Width), float64(ev.Position.Y/size.Height))
+}
+
+// Dragged pans the zoomed view with the pointer.
+func (p *zoomPad) Dragged(ev *fyne.DragEvent) {
+	if !p.dragging {
+		p.dragging = true
+		if p.onDrag != nil {
+			p.onDrag()
+		}
+	}
+	size := p.Size()
+	if size.Width <= 0 || size.Height <= 0 {
+		return
+	}
+	p.mu.Lock()
+	if p.zoom <= 1 {
+		p.mu.Unlock()
+		return
+	}
+	w := 1 / p.zoom
+	p.x = clampView(p.x-float64(ev.Dragged.DX/size.Width)*w, w)
+	p.y = clampView(p.y-float64(ev.Dragged.DY/size.Height)*w, w)
+	p.mu.Unlock()
+}
+
+// DragEnd ends the pan
+func (p *zoomPad) DragEnd() {
+	p.dragging = false
+}
+
+// clampView keeps a view edge of size w inside the frame.
+func clampView(pos, w float64) float64 {
+	if pos < 0 {
+		return 0
+	}
+	if pos > 1-w {
+		return 1 - w
+	}
+	return pos
+}
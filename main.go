@@ -2,6 +2,7 @@ package main
 
 import (
 	"camera-dashboard-go/internal/config"
+	"camera-dashboard-go/internal/core"
 	"camera-dashboard-go/internal/ui"
 	"flag"
 	"fmt"
@@ -24,6 +25,7 @@ func main() {
 	showVersion := flag.Bool("version", false, "Show version information")
 	flag.BoolVar(showVersion, "v", false, "Show version information (shorthand)")
 	configPath := flag.String("config", "", "Path to config.ini (default: ./config.ini or $CAMERA_DASHBOARD_CONFIG)")
+	headless := flag.Bool("headless", false, "Run without a display: capture, recording and HTTP server only")
 	flag.Parse()
 
 	if *showVersion {
@@ -64,12 +66,17 @@ func main() {
 		log.Printf("[Main] WARNING: %s", w)
 	}
 
-	app := ui.NewApp(cfg)
-
 	// Setup signal handling for clean shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	if *headless {
+		runHeadless(cfg, sigCh)
+		return
+	}
+
+	app := ui.NewApp(cfg)
+
 	go func() {
 		sig := <-sigCh
 		log.Printf("[Main] Received signal %v, cleaning up...", sig)
@@ -82,3 +89,22 @@ func main() {
 	// Cleanup on normal exit
 	app.Cleanup()
 }
+
+// runHeadless runs the camera services without a window until a signal
+// arrives or the REST API exits or restarts the dashboard.
+func runHeadless(cfg *config.Config, sigCh <-chan os.Signal) {
+	if !cfg.HTTPEnabled && !cfg.RecordingEnabled && !cfg.ClipsEnabled {
+		log.Printf("[Main] WARNING: Headless mode with [http], [recording] and [clips] all disabled; cameras are only supervised")
+	}
+	log.Println("[Main] Running headless (no display)")
+
+	svc := core.New(cfg)
+	svc.Start()
+
+	select {
+	case sig := <-sigCh:
+		log.Printf("[Main] Received signal %v, cleaning up...", sig)
+	case <-svc.Done():
+	}
+	svc.Stop()
+}